@@ -0,0 +1,363 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/internal/supervisor"
+	"github.com/ink-code/gint/middlewares/unavailable"
+)
+
+var (
+	// ErrTaskQueueFull 任务队列已满
+	ErrTaskQueueFull = errors.New("任务队列已满")
+
+	// ErrTaskPoolClosed 任务池已关闭
+	ErrTaskPoolClosed = errors.New("任务池已关闭")
+
+	// ErrTaskNotFound 任务不存在
+	ErrTaskNotFound = errors.New("任务不存在")
+)
+
+// TaskStatus 异步任务状态
+type TaskStatus string
+
+const (
+	// TaskPending 排队中
+	TaskPending TaskStatus = "pending"
+	// TaskRunning 执行中
+	TaskRunning TaskStatus = "running"
+	// TaskSucceeded 执行成功
+	TaskSucceeded TaskStatus = "succeeded"
+	// TaskFailed 执行失败
+	TaskFailed TaskStatus = "failed"
+)
+
+// Task 异步任务
+type Task struct {
+	ID        string     `json:"id"`               // 任务 ID
+	Status    TaskStatus `json:"status"`           // 任务状态
+	Result    *Result    `json:"result,omitempty"` // 执行成功时的结果
+	Error     string     `json:"error,omitempty"`  // 执行失败时的错误信息
+	CreatedAt time.Time  `json:"created_at"`       // 创建时间
+	UpdatedAt time.Time  `json:"updated_at"`       // 最后更新时间
+}
+
+// TaskFunc 异步任务的执行函数
+type TaskFunc func(ctx context.Context) (Result, error)
+
+// TaskStore 任务状态存储接口
+// 多实例部署时应使用 Redis 等共享存储，使任意实例都能查询任务状态
+type TaskStore interface {
+	// Save 保存任务状态，任务状态变化时调用
+	Save(ctx context.Context, task *Task) error
+	// Get 获取任务状态，不存在时返回 ErrTaskNotFound
+	Get(ctx context.Context, id string) (*Task, error)
+}
+
+// taskJob 等待执行的任务
+type taskJob struct {
+	task *Task
+	fn   TaskFunc
+}
+
+// TaskPool 异步任务池
+// 固定数量的工作协程从有界队列中取出任务执行，并将状态写入 TaskStore
+type TaskPool struct {
+	store   TaskStore
+	queue   chan taskJob
+	timeout time.Duration // 单个任务的超时时间，0 表示不限制
+
+	mu     sync.RWMutex // 保护 closed 与 queue 的关闭
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewTaskPool 创建异步任务池
+// workers: 工作协程数
+// queueSize: 等待队列长度，队列满时提交任务返回 ErrTaskQueueFull
+func NewTaskPool(store TaskStore, workers, queueSize int) *TaskPool {
+	p := &TaskPool{
+		store: store,
+		queue: make(chan taskJob, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go p.worker()
+	}
+	return p
+}
+
+// WithTimeout 设置单个任务的超时时间
+func (p *TaskPool) WithTimeout(timeout time.Duration) *TaskPool {
+	p.timeout = timeout
+	return p
+}
+
+// Submit 提交任务，返回排队中的任务
+func (p *TaskPool) Submit(ctx context.Context, fn TaskFunc) (*Task, error) {
+	now := time.Now()
+	task := &Task{
+		ID:        uuid.NewString(),
+		Status:    TaskPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := p.store.Save(ctx, task); err != nil {
+		return nil, fmt.Errorf("保存任务状态失败: %w", err)
+	}
+
+	err := p.enqueue(taskJob{task: task, fn: fn})
+	if err != nil {
+		// 未能入队的任务记为失败，避免一直停留在排队状态
+		failed := *task
+		p.update(&failed, TaskFailed, nil, err)
+		return nil, err
+	}
+	return task, nil
+}
+
+// enqueue 将任务放入等待队列
+func (p *TaskPool) enqueue(job taskJob) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrTaskPoolClosed
+	}
+	select {
+	case p.queue <- job:
+		return nil
+	default:
+		return ErrTaskQueueFull
+	}
+}
+
+// Get 查询任务状态
+func (p *TaskPool) Get(ctx context.Context, id string) (*Task, error) {
+	return p.store.Get(ctx, id)
+}
+
+// QueueLen 等待队列中的任务数
+func (p *TaskPool) QueueLen() int {
+	return len(p.queue)
+}
+
+// Close 停止接收新任务，等待队列中的任务执行完成
+func (p *TaskPool) Close() error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+	return nil
+}
+
+// worker 工作协程
+func (p *TaskPool) worker() {
+	defer p.wg.Done()
+	for job := range p.queue {
+		p.run(job)
+	}
+}
+
+// run 执行单个任务并更新状态，任务 panic 时记为失败
+func (p *TaskPool) run(job taskJob) {
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	task := *job.task
+	p.update(&task, TaskRunning, nil, nil)
+
+	var res Result
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("异步任务 panic",
+					slog.String("task_id", task.ID),
+					slog.Any("panic", r),
+					slog.String("stack", string(debug.Stack())))
+				err = fmt.Errorf("任务执行异常: %v", r)
+			}
+		}()
+		res, err = job.fn(ctx)
+	}()
+
+	if err != nil {
+		slog.Error("异步任务执行失败", slog.String("task_id", task.ID), slog.Any("err", err))
+		p.update(&task, TaskFailed, nil, err)
+		return
+	}
+	p.update(&task, TaskSucceeded, &res, nil)
+}
+
+// update 更新任务状态并保存
+func (p *TaskPool) update(task *Task, status TaskStatus, res *Result, err error) {
+	task.Status = status
+	task.Result = res
+	task.UpdatedAt = time.Now()
+	if err != nil {
+		task.Error = err.Error()
+	}
+	if saveErr := p.store.Save(context.Background(), task); saveErr != nil {
+		slog.Error("保存任务状态失败",
+			slog.String("task_id", task.ID),
+			slog.String("status", string(status)),
+			slog.Any("err", saveErr))
+	}
+}
+
+// Async 异步任务包装器
+// 绑定参数后将业务逻辑提交到任务池执行，立即以 202 返回任务信息（Result.Data 为 Task），
+// 客户端可以通过 TaskHandler 注册的接口轮询任务状态
+// 注意：业务逻辑在请求结束后执行，只能使用传入的 ctx，不能再访问 gin.Context
+//
+// 示例:
+//
+//	pool := gint.NewTaskPool(gint.NewMemoryTaskStore(time.Hour), 4, 100)
+//	router.POST("/reports", gint.Async(pool, func(ctx context.Context, req ExportReq) (gint.Result, error) {
+//	   url, err := exportReport(ctx, req)
+//	   return gint.Success("", url), err
+//	}))
+//	router.GET("/tasks/:id", gint.TaskHandler(pool))
+func Async[Req any](pool *TaskPool, fn func(ctx context.Context, req Req) (Result, error), opts ...Option) gin.HandlerFunc {
+	checkRules[Req]()
+	o := newOptions(fn, opts)
+	if o.successStatus == 0 {
+		o.successStatus = http.StatusAccepted
+	}
+	return func(c *gin.Context) {
+		// 绑定参数并提交任务
+		handle(c, o, func() (Result, error) {
+			req, ok := bind[Req](c, o)
+			if !ok {
+				return Result{}, errBindFailed
+			}
+
+			task, err := pool.Submit(c.Request.Context(), func(ctx context.Context) (Result, error) {
+				return fn(ctx, req)
+			})
+			if errors.Is(err, ErrTaskQueueFull) || errors.Is(err, ErrTaskPoolClosed) {
+				unavailable.ServiceUnavailable(c, unavailable.ReasonOverloaded, 0)
+				return Result{}, fmt.Errorf("提交异步任务失败: %w", ErrNoResponse)
+			}
+			if err != nil {
+				return Result{Code: CodeError}, err
+			}
+			return Success("任务已受理", task), nil
+		})
+	}
+}
+
+// TaskHandler 查询异步任务状态的处理函数，任务 ID 取自路径参数 id
+//
+// 示例:
+//
+//	router.GET("/tasks/:id", gint.TaskHandler(pool))
+func TaskHandler(pool *TaskPool) gin.HandlerFunc {
+	return W(func(ctx *gctx.Context) (Result, error) {
+		task, err := pool.Get(ctx.Request.Context(), ctx.Param("id").StringOr(""))
+		if errors.Is(err, ErrTaskNotFound) {
+			return ErrorWithCode(http.StatusNotFound, err.Error()), nil
+		}
+		if err != nil {
+			return Result{Code: CodeError}, err
+		}
+		return Success("", task), nil
+	}, WithName("gint.TaskHandler"))
+}
+
+// MemoryTaskStore 基于内存的任务状态存储
+// 已结束的任务保留 ttl 后自动清理；多实例部署时请使用共享存储
+type MemoryTaskStore struct {
+	mu      sync.RWMutex
+	tasks   map[string]Task
+	ttl     time.Duration
+	cleaner *supervisor.Supervisor // 清理协程
+}
+
+// NewMemoryTaskStore 创建内存任务状态存储
+func NewMemoryTaskStore(ttl time.Duration) *MemoryTaskStore {
+	s := &MemoryTaskStore{
+		tasks: make(map[string]Task),
+		ttl:   ttl,
+	}
+	s.cleaner = supervisor.Go("memory-task-store-cleaner", s.cleanupLoop)
+	return s
+}
+
+// Save 保存任务状态
+func (s *MemoryTaskStore) Save(_ context.Context, task *Task) error {
+	s.mu.Lock()
+	s.tasks[task.ID] = *task
+	s.mu.Unlock()
+	return nil
+}
+
+// Get 获取任务状态
+func (s *MemoryTaskStore) Get(_ context.Context, id string) (*Task, error) {
+	s.mu.RLock()
+	task, ok := s.tasks[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+// Close 停止清理协程
+func (s *MemoryTaskStore) Close() error {
+	return s.cleaner.Close()
+}
+
+// cleanupLoop 清理已结束且超过保留时间的任务
+func (s *MemoryTaskStore) cleanupLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deadline := time.Now().Add(-s.ttl)
+			s.mu.Lock()
+			for id, task := range s.tasks {
+				finished := task.Status == TaskSucceeded || task.Status == TaskFailed
+				if finished && task.UpdatedAt.Before(deadline) {
+					delete(s.tasks, id)
+				}
+			}
+			s.mu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// defaultMultipartMemory 解析 multipart 表单时使用的默认内存上限
+const defaultMultipartMemory = 32 << 20
+
+// errBindFailed 绑定参数失败，400 响应已经输出
+var errBindFailed = fmt.Errorf("绑定参数失败: %w", ErrNoResponse)
+
+// BindSource 请求参数的绑定来源
+type BindSource int
+
+const (
+	// SourceJSON 从 JSON 请求体绑定（json 标签）
+	SourceJSON BindSource = iota + 1
+	// SourceQuery 从查询参数绑定（form 标签）
+	SourceQuery
+	// SourceForm 从表单请求体绑定（form 标签，支持 urlencoded 和 multipart）
+	SourceForm
+	// SourceURI 从路径参数绑定（uri 标签）
+	SourceURI
+)
+
+// 常用的绑定来源选项，可以组合使用
+// 多个来源按传入顺序依次绑定到同一个结构体，后绑定的来源会覆盖先绑定的同名字段，全部绑定完成后统一执行 binding 标签校验
+//
+// 示例:
+//
+//	router.PUT("/users/:id", gint.B(updateUser, gint.BindURI, gint.BindQuery, gint.BindJSON))
+var (
+	// BindJSON 从 JSON 请求体绑定
+	BindJSON = BindFrom(SourceJSON)
+	// BindQuery 从查询参数绑定
+	BindQuery = BindFrom(SourceQuery)
+	// BindForm 从表单请求体绑定
+	BindForm = BindFrom(SourceForm)
+	// BindURI 从路径参数绑定
+	BindURI = BindFrom(SourceURI)
+)
+
+// BindFrom 显式指定绑定来源
+// 未指定任何来源时，包装器自动绑定路径参数（uri 标签），其余参数使用 gin 的 ShouldBind 根据 Content-Type 推断
+func BindFrom(sources ...BindSource) Option {
+	return func(o *options) {
+		o.bindSources = append(o.bindSources, sources...)
+	}
+}
+
+// bind 绑定请求参数，并在请求参数实现了 Validatable 或 BuilderValidatable 时自动校验
+// 绑定或校验失败时直接返回 400 响应，并返回 ok=false
+func bind[Req any](c *gin.Context, o *options, attrs ...any) (req Req, ok bool) {
+	// 带 maxsize 标签的文件在解析请求体时检查，超限后立即中止解析
+	stop := guardFileSizes(c.Request, fileLimitsOf(reflect.TypeFor[Req]()))
+	var err error
+	if len(o.bindSources) > 0 {
+		err = bindSources(c, &req, o.bindSources)
+	} else {
+		err = bindDefault(c, &req)
+	}
+	if sizeErr := stop(); sizeErr != nil {
+		err = sizeErr
+	}
+	if err != nil {
+		slog.Debug("绑定参数失败", withTrace(c, append([]any{
+			slog.String("path", c.Request.URL.Path),
+			slog.Any("err", err)}, attrs...))...)
+		c.Set(ctxValidationFailuresKey, bindFailures(&req, err))
+		recordError(c, err, http.StatusBadRequest, http.StatusBadRequest, true)
+		be := translateBindError(o, &req, err)
+		res := Result{Code: 400, Msg: "参数错误: " + be.Msg, TraceID: traceID(c)}
+		if len(be.Fields) > 0 {
+			res.Data = be.Fields
+		}
+		writeError(c, http.StatusBadRequest, res)
+		return req, false
+	}
+
+	// 校验上传文件大小、已注册的枚举字段和请求参数自身的校验逻辑
+	errs := validateFiles(&req)
+	errs = append(errs, validateEnums(&req)...)
+	selfErrs, fieldErrs := validate(&req)
+	errs = append(errs, selfErrs...)
+	if len(errs) > 0 {
+		c.Set(ctxValidationFailuresKey, fieldErrs.failures())
+		recordError(c, errors.New(strings.Join(errs, "；")), http.StatusBadRequest, http.StatusBadRequest, true)
+		slog.Debug("参数校验失败", withTrace(c, append([]any{
+			slog.String("path", c.Request.URL.Path),
+			slog.Any("errors", errs)}, attrs...))...)
+		writeError(c, http.StatusBadRequest, Result{
+			Code:    400,
+			Msg:     "参数错误: " + strings.Join(errs, "；"),
+			Data:    errs,
+			TraceID: traceID(c),
+			Errors:  fieldErrs,
+		})
+		return req, false
+	}
+
+	return req, true
+}
+
+// bindDefault 默认绑定方式
+// 先将路径参数映射到 uri 标签字段，再由 ShouldBind 根据 Content-Type 绑定其余参数并统一校验
+// 路径参数不单独校验，避免请求体尚未绑定时 required 等规则误报
+func bindDefault(c *gin.Context, obj any) error {
+	if len(c.Params) > 0 {
+		if err := binding.MapFormWithTag(obj, uriParams(c), "uri"); err != nil {
+			return err
+		}
+	}
+	return c.ShouldBind(obj)
+}
+
+// bindSources 按顺序从多个来源绑定参数，最后统一校验
+func bindSources(c *gin.Context, obj any, sources []BindSource) error {
+	for _, source := range sources {
+		var err error
+		switch source {
+		case SourceJSON:
+			err = decodeJSON(c.Request, obj)
+		case SourceQuery:
+			err = binding.MapFormWithTag(obj, c.Request.URL.Query(), "form")
+		case SourceForm:
+			err = mapPostForm(c.Request, obj)
+		case SourceURI:
+			err = binding.MapFormWithTag(obj, uriParams(c), "uri")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// decodeJSON 解码 JSON 请求体，空请求体视为未传参数
+// 解码后恢复请求体，以便后续中间件或处理函数再次读取；可以定位的请求体（如 spool 转存的）直接从中解码，不读入内存
+func decodeJSON(req *http.Request, obj any) error {
+	if req.Body == nil {
+		return nil
+	}
+	if rs, ok := req.Body.(io.ReadSeeker); ok {
+		return rewind(rs, func(r io.Reader) error {
+			if err := newJSONDecoder(r).Decode(obj); !errors.Is(err, io.EOF) {
+				return err
+			}
+			return nil
+		})
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	return newJSONDecoder(bytes.NewReader(body)).Decode(obj)
+}
+
+// newJSONDecoder 按 gin 的全局设置创建 JSON 解码器
+func newJSONDecoder(r io.Reader) *json.Decoder {
+	decoder := json.NewDecoder(r)
+	if binding.EnableDecoderUseNumber {
+		decoder.UseNumber()
+	}
+	if binding.EnableDecoderDisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder
+}
+
+// rewind 从可以定位的请求体的当前位置读取，结束后定位回原来的位置，后续仍可以完整读取请求体
+func rewind(rs io.ReadSeeker, fn func(r io.Reader) error) error {
+	pos, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	err = fn(rs)
+	if _, seekErr := rs.Seek(pos, io.SeekStart); err == nil {
+		err = seekErr
+	}
+	return err
+}
+
+// mapPostForm 绑定表单请求体（不包含查询参数）
+// multipart 请求中的文件绑定到 *multipart.FileHeader 或 []*multipart.FileHeader 字段
+func mapPostForm(req *http.Request, obj any) error {
+	if err := req.ParseMultipartForm(defaultMultipartMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	if err := binding.MapFormWithTag(obj, req.PostForm, "form"); err != nil {
+		return err
+	}
+	return mapFiles(req, obj)
+}
+
+// uriParams 将路径参数转换为绑定所需的格式
+func uriParams(c *gin.Context) map[string][]string {
+	params := make(map[string][]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Key] = []string{p.Value}
+	}
+	return params
+}
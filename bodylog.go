@@ -0,0 +1,178 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxErrorBodyLength 错误请求体日志的最大记录长度
+const maxErrorBodyLength = 4096
+
+// maxLoggedBody 读取用于记录的请求体的最大长度，超过时不记录（截断后无法解析和脱敏）
+const maxLoggedBody = 64 << 10
+
+// redactedValue 脱敏后的值
+const redactedValue = "***"
+
+// defaultRedactFields 默认脱敏的字段名（不区分大小写）
+var defaultRedactFields = []string{
+	"password", "passwd", "pwd", "secret", "token",
+	"access_token", "refresh_token", "authorization", "api_key",
+}
+
+// errorBodyConfig 错误请求体日志配置
+type errorBodyConfig struct {
+	redact map[string]struct{} // 需要脱敏的字段名（小写）
+}
+
+// WithErrorBodyLog 处理函数返回错误或参数绑定失败时，以 Warn 级别记录脱敏后的请求体
+// 无需在 accesslog 中开启全量请求体日志即可排查线上失败的请求；fields 为默认字段（password、token 等）之外需要脱敏的字段名
+// JSON 和表单请求体按字段名脱敏（包括嵌套字段），multipart 请求体不记录，超过 4KB 的部分截断
+//
+// 示例:
+//
+//	router.POST("/orders", gint.BS(createOrder, gint.WithErrorBodyLog("id_card", "bank_card")))
+func WithErrorBodyLog(fields ...string) Option {
+	return func(o *options) {
+		cfg := &errorBodyConfig{redact: make(map[string]struct{})}
+		for _, f := range append(defaultRedactFields, fields...) {
+			cfg.redact[strings.ToLower(f)] = struct{}{}
+		}
+		o.errorBody = cfg
+	}
+}
+
+// logBodyOnError 在业务逻辑返回错误时记录请求体
+// JSON 和表单请求体在执行业务逻辑前读取前 64KB 并恢复，不影响后续的参数绑定；其他类型的请求体（如文件上传）不读取
+func logBodyOnError(c *gin.Context, cfg *errorBodyConfig, call func() (Result, error), attrs []any) func() (Result, error) {
+	var body []byte
+	if rs, ok := c.Request.Body.(io.ReadSeeker); ok && loggableBody(c.ContentType()) {
+		// 可以定位的请求体（如 spool 转存的）只读取记录所需的部分，读取后定位回原来的位置
+		rewind(rs, func(r io.Reader) (err error) {
+			body, err = io.ReadAll(io.LimitReader(r, maxLoggedBody+1))
+			return err
+		})
+	} else if c.Request.Body != nil && c.Request.Body != http.NoBody && loggableBody(c.ContentType()) {
+		// 同样只读取记录所需的部分，与未读取的部分拼接后恢复请求体
+		body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBody+1))
+		c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+	}
+
+	return func() (Result, error) {
+		res, err := call()
+		if err == nil || (errors.Is(err, ErrNoResponse) && !errors.Is(err, errBindFailed)) {
+			return res, err
+		}
+
+		logAttrs := append([]any{
+			slog.String("path", c.Request.URL.Path),
+			slog.String("method", c.Request.Method),
+			slog.String("query", cfg.redactQuery(c.Request.URL.RawQuery)),
+			slog.String("body", cfg.redactBody(c.ContentType(), body)),
+			slog.Any("err", err)}, attrs...)
+		slog.Warn("请求处理失败", logAttrs...)
+		return res, err
+	}
+}
+
+// loggableBody 判断请求体是否可以记录（JSON 或 URL 编码的表单）
+func loggableBody(contentType string) bool {
+	return contentType == gin.MIMEPOSTForm || strings.HasSuffix(contentType, "json")
+}
+
+// redactBody 按 Content-Type 脱敏请求体
+func (cfg *errorBodyConfig) redactBody(contentType string, body []byte) string {
+	if !loggableBody(contentType) {
+		if contentType == "" {
+			return ""
+		}
+		return "[" + contentType + " omitted]"
+	}
+	if len(body) == 0 {
+		return ""
+	}
+	if len(body) > maxLoggedBody {
+		// 截断后的 JSON 无法解析和脱敏，不记录原文
+		return "[body larger than " + strconv.Itoa(maxLoggedBody) + " bytes omitted]"
+	}
+
+	var out string
+	if contentType == gin.MIMEPOSTForm {
+		out = cfg.redactQuery(string(body))
+	} else {
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			// 无法解析时不记录原文，避免泄露未能识别的敏感字段
+			return "[invalid json]"
+		}
+		b, _ := json.Marshal(cfg.redactJSON(v))
+		out = string(b)
+	}
+
+	if len(out) > maxErrorBodyLength {
+		out = strings.ToValidUTF8(out[:maxErrorBodyLength], "") + "...(truncated)"
+	}
+	return out
+}
+
+// redactJSON 递归脱敏 JSON 中的字段
+func (cfg *errorBodyConfig) redactJSON(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if _, ok := cfg.redact[strings.ToLower(k)]; ok {
+				val[k] = redactedValue
+			} else {
+				val[k] = cfg.redactJSON(item)
+			}
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = cfg.redactJSON(item)
+		}
+	}
+	return v
+}
+
+// redactQuery 脱敏 URL 编码的参数
+func (cfg *errorBodyConfig) redactQuery(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return "[invalid form]"
+	}
+	for k := range values {
+		if _, ok := cfg.redact[strings.ToLower(k)]; ok {
+			values[k] = []string{redactedValue}
+		}
+	}
+	return values.Encode()
+}
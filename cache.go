@@ -0,0 +1,225 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/internal/supervisor"
+	"github.com/ink-code/gint/session"
+)
+
+// CacheKeyFunc 响应缓存键生成函数
+type CacheKeyFunc func(ctx *gctx.Context) string
+
+// ResultCache 包装器响应缓存存储
+type ResultCache interface {
+	// Get 获取缓存的结果，不存在或已过期时返回 nil
+	Get(ctx context.Context, key string) (*Result, error)
+	// Set 缓存结果
+	Set(ctx context.Context, key string, res Result, ttl time.Duration) error
+	// Delete 删除缓存
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// cacheConfig 响应缓存配置
+type cacheConfig struct {
+	ttl     time.Duration
+	keyFunc CacheKeyFunc
+}
+
+var (
+	// resultCache 全局响应缓存存储，未设置时首次使用创建内存存储
+	resultCache     atomic.Pointer[ResultCache]
+	resultCacheOnce sync.Once
+)
+
+// SetResultCache 设置 WithCache 使用的缓存存储，如多实例部署时替换为 Redis 实现
+func SetResultCache(cache ResultCache) {
+	resultCache.Store(&cache)
+}
+
+// getResultCache 获取缓存存储
+func getResultCache() ResultCache {
+	resultCacheOnce.Do(func() {
+		if resultCache.Load() == nil {
+			var cache ResultCache = NewMemoryResultCache()
+			resultCache.CompareAndSwap(nil, &cache)
+		}
+	})
+	return *resultCache.Load()
+}
+
+// WithCache 缓存 GET 请求的处理结果，命中时直接返回缓存的 Result，不再执行业务逻辑
+// keyFunc 为 nil 时使用请求 URI（路径 + 查询参数）和当前用户（session.UserId）作为键，如 /profile#u123；只缓存未返回 error 的结果
+// 自定义 keyFunc 时，接口返回与用户相关的数据则键中必须包含用户标识
+//
+// 示例:
+//
+//	router.GET("/products/:id", gint.B(getProduct, gint.WithCache(time.Minute, func(ctx *gctx.Context) string {
+//	   return "product:" + ctx.Param("id").StringOr("")
+//	})))
+//
+//	// 修改商品后失效缓存
+//	gint.InvalidateCache(ctx, "product:"+id)
+func WithCache(ttl time.Duration, keyFunc CacheKeyFunc) Option {
+	return func(o *options) {
+		o.cache = &cacheConfig{ttl: ttl, keyFunc: keyFunc}
+	}
+}
+
+// InvalidateCache 删除 WithCache 缓存的结果
+func InvalidateCache(ctx context.Context, keys ...string) error {
+	return getResultCache().Delete(ctx, keys...)
+}
+
+// handleCached 带响应缓存的执行流程
+// replayed 在命中缓存时调用，用于上报缓存结果的业务码
+func handleCached(c *gin.Context, o *options, call func() (Result, error), replayed func(Result), attrs ...any) {
+	cache := getResultCache()
+	ctx := c.Request.Context()
+
+	var key string
+	if o.cache.keyFunc != nil {
+		key = o.cache.keyFunc(&gctx.Context{Context: c})
+	} else {
+		key = defaultCacheKey(c)
+	}
+
+	cached, err := cache.Get(ctx, key)
+	if err != nil {
+		slog.Warn("读取响应缓存失败", append([]any{
+			slog.String("key", key),
+			slog.Any("err", err)}, attrs...)...)
+	}
+	if cached != nil {
+		c.Header("X-Cache", "HIT")
+		replayed(*cached)
+		render(c, o, *cached, nil, attrs...)
+		return
+	}
+
+	c.Header("X-Cache", "MISS")
+	res, err := call()
+	if err == nil && replayable(res) {
+		if setErr := cache.Set(ctx, key, res, o.cache.ttl); setErr != nil {
+			slog.Warn("写入响应缓存失败", append([]any{
+				slog.String("key", key),
+				slog.Any("err", setErr)}, attrs...)...)
+		}
+	}
+	render(c, o, res, err, attrs...)
+}
+
+// defaultCacheKey 默认的缓存键：请求 URI，已登录时追加 #用户 ID
+func defaultCacheKey(c *gin.Context) string {
+	key := c.Request.URL.RequestURI()
+	if userId := session.UserId(&gctx.Context{Context: c}); userId != "" {
+		key += "#" + userId
+	}
+	return key
+}
+
+// cacheable 判断请求是否使用响应缓存
+func cacheable(c *gin.Context, o *options) bool {
+	return o.cache != nil && c.Request.Method == http.MethodGet
+}
+
+// MemoryResultCache 内存响应缓存
+// 注意：缓存的 Result.Data 会被多个请求共享，处理函数不应在返回后修改它
+type MemoryResultCache struct {
+	mu      sync.RWMutex
+	entries map[string]memoryResultEntry
+	cleaner *supervisor.Supervisor
+}
+
+type memoryResultEntry struct {
+	result   Result
+	expireAt time.Time
+}
+
+// NewMemoryResultCache 创建内存响应缓存
+func NewMemoryResultCache() *MemoryResultCache {
+	s := &MemoryResultCache{
+		entries: make(map[string]memoryResultEntry),
+	}
+	s.cleaner = supervisor.Go("memory-result-cache-cleaner", s.cleanupLoop)
+	return s
+}
+
+// Get 获取缓存的结果
+func (s *MemoryResultCache) Get(ctx context.Context, key string) (*Result, error) {
+	s.mu.RLock()
+	entry, ok := s.entries[key]
+	s.mu.RUnlock()
+	if !ok || time.Now().After(entry.expireAt) {
+		return nil, nil
+	}
+	return &entry.result, nil
+}
+
+// Set 缓存结果
+func (s *MemoryResultCache) Set(ctx context.Context, key string, res Result, ttl time.Duration) error {
+	s.mu.Lock()
+	s.entries[key] = memoryResultEntry{result: res, expireAt: time.Now().Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete 删除缓存
+func (s *MemoryResultCache) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Close 停止后台清理协程
+func (s *MemoryResultCache) Close() error {
+	return s.cleaner.Close()
+}
+
+// cleanupLoop 定期清理过期缓存
+func (s *MemoryResultCache) cleanupLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		now := time.Now()
+		s.mu.Lock()
+		for key, entry := range s.entries {
+			if now.After(entry.expireAt) {
+				delete(s.entries, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
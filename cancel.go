@@ -0,0 +1,110 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+)
+
+// statusClientClosed 客户端在响应前断开时记录的状态码（与 nginx 的 499 一致），不会实际送达客户端
+const statusClientClosed = 499
+
+// ctxCanceledKey 在 Context 中记录客户端断开后仍在处理的时间的 key
+const ctxCanceledKey = "gint:canceled"
+
+// errClientGone 开启 WithAbortOnCancel 时，客户端在执行业务逻辑前已经断开
+var errClientGone = fmt.Errorf("客户端已断开，跳过业务逻辑: %w", context.Canceled)
+
+// WithAbortOnCancel 客户端断开后尽早停止处理
+// 执行业务逻辑前客户端已经断开时不再执行；传给处理函数的 ctx 的 Done、Err 跟随请求的 Context（见 gctx.FollowRequest），
+// 以 ctx 执行的数据库查询等操作会在客户端断开时被取消。开启了请求范围的事务时，被取消的请求会回滚
+//
+// 示例:
+//
+//	router.GET("/reports", gint.B(searchReports, gint.WithAbortOnCancel()))
+func WithAbortOnCancel() Option {
+	return func(o *options) {
+		o.abortOnCancel = true
+	}
+}
+
+// newContext 创建传给处理函数的 Context
+func newContext(c *gin.Context, o *options) *gctx.Context {
+	if o.abortOnCancel {
+		return gctx.FollowRequest(c)
+	}
+	return &gctx.Context{Context: c}
+}
+
+// watchCancel 记录客户端在处理完成前断开的情况：输出日志，并将断开后仍在处理的时间记录到指标中
+func watchCancel(c *gin.Context, o *options, call func() (Result, error), attrs []any) func() (Result, error) {
+	return func() (Result, error) {
+		reqCtx := c.Request.Context()
+		if o.abortOnCancel && clientGone(c) {
+			c.Set(ctxCanceledKey, time.Duration(0))
+			return Result{}, errClientGone
+		}
+
+		var canceledAt atomic.Int64
+		stop := context.AfterFunc(reqCtx, func() {
+			canceledAt.Store(time.Now().UnixNano())
+		})
+		start := time.Now()
+		res, err := call()
+		stop()
+		if !clientGone(c) {
+			return res, err
+		}
+
+		// AfterFunc 的协程可能还没有执行，此时按刚刚断开处理
+		end := time.Now()
+		wasted := time.Duration(0)
+		if at := canceledAt.Load(); at > 0 {
+			wasted = end.Sub(time.Unix(0, at))
+		}
+		c.Set(ctxCanceledKey, wasted)
+		slog.Info("客户端在处理完成前断开", append([]any{
+			slog.String("path", c.Request.URL.Path),
+			slog.String("handler", o.name),
+			slog.Duration("duration", end.Sub(start)),
+			slog.Duration("wasted", wasted)}, attrs...)...)
+		return res, err
+	}
+}
+
+// clientGone 判断客户端是否已经断开
+// 只认 context.Canceled：deadline 中间件设置的超时到期时 Err 为 context.DeadlineExceeded，按超时处理，不算客户端断开
+func clientGone(c *gin.Context) bool {
+	return errors.Is(c.Request.Context().Err(), context.Canceled)
+}
+
+// cancelInfo 返回断开后仍在处理的时间，以及客户端是否在处理完成前断开
+func cancelInfo(c *gin.Context) (time.Duration, bool) {
+	v, ok := c.Get(ctxCanceledKey)
+	if !ok {
+		return 0, false
+	}
+	wasted, _ := v.(time.Duration)
+	return wasted, true
+}
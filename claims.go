@@ -0,0 +1,232 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/session"
+)
+
+// DecodeClaims 将 Claims.Data 解码为自定义结构体
+// 字段通过 claims 标签指定键名（未指定时使用字段名），支持字符串、整数、浮点数、布尔值、
+// time.Duration 和逗号分隔的 []string；Data 中不存在的键保持零值
+//
+// 示例:
+//
+//	type UserClaims struct {
+//	   Role     string   `claims:"role"`
+//	   TenantId int64    `claims:"tenant_id"`
+//	   Scopes   []string `claims:"scopes"` // "read,write"
+//	}
+//
+//	uc, err := gint.DecodeClaims[UserClaims](sess.Claims())
+func DecodeClaims[T any](claims *session.Claims) (T, error) {
+	var out T
+	v := reflect.ValueOf(&out).Elem()
+	if v.Kind() != reflect.Struct {
+		return out, fmt.Errorf("claims 类型 %T 必须是结构体", out)
+	}
+	if claims == nil {
+		return out, nil
+	}
+	if err := decodeClaimsStruct(v, claims.Data); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// decodeClaimsStruct 按 claims 标签设置结构体字段，嵌入结构体的字段展开到同一层
+func decodeClaimsStruct(v reflect.Value, data map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := field.Tag.Get("claims")
+		if key == "-" {
+			continue
+		}
+		if field.Anonymous && key == "" && field.Type.Kind() == reflect.Struct {
+			if err := decodeClaimsStruct(v.Field(i), data); err != nil {
+				return err
+			}
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+
+		raw, ok := data[key]
+		if !ok {
+			continue
+		}
+		if err := setClaimValue(v.Field(i), raw); err != nil {
+			return fmt.Errorf("解析 claims 字段 %s 失败: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// setClaimValue 将字符串转换为字段类型并赋值
+func setClaimValue(fv reflect.Value, raw string) error {
+	if fv.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("不支持的类型 %s", fv.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		fv.Set(reflect.ValueOf(items).Convert(fv.Type()))
+	default:
+		return fmt.Errorf("不支持的类型 %s", fv.Type())
+	}
+	return nil
+}
+
+// ST (Session + Typed claims) 带类型化 Claims 的 Session 包装器
+// 与 S 相同，额外将 Claims.Data 解码为 T（见 DecodeClaims），解码失败时响应 401
+//
+// 示例:
+//
+//	router.GET("/tenant", gint.ST(func(ctx *gctx.Context, sess session.Session, uc UserClaims) (gint.Result, error) {
+//	   return gint.Success("", getTenant(uc.TenantId)), nil
+//	}))
+func ST[T any](fn func(ctx *gctx.Context, sess session.Session, claims T) (Result, error), opts ...Option) gin.HandlerFunc {
+	mustClaimsStruct[T]()
+	o := newOptions(fn, opts)
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := newContext(c, o)
+
+		// 获取 Session 并解码 Claims
+		sess, claims, ok := typedSession[T](ctx, o, start)
+		if !ok {
+			return
+		}
+
+		// 执行业务逻辑并响应
+		handle(c, o, func() (Result, error) {
+			return fn(ctx, sess, claims)
+		}, slog.String("user_id", sess.Claims().UserId))
+	}
+}
+
+// BST (Bind + Session + Typed claims) 带参数绑定和类型化 Claims 的 Session 包装器
+// 与 BS 相同，额外将 Claims.Data 解码为 T（见 DecodeClaims），解码失败时响应 401
+func BST[Req, T any](fn func(ctx *gctx.Context, req Req, sess session.Session, claims T) (Result, error), opts ...Option) gin.HandlerFunc {
+	mustClaimsStruct[T]()
+	checkRules[Req]()
+	o := newOptions(fn, opts)
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := newContext(c, o)
+
+		// 获取 Session 并解码 Claims
+		sess, claims, ok := typedSession[T](ctx, o, start)
+		if !ok {
+			return
+		}
+
+		// 绑定参数、执行业务逻辑并响应
+		userAttr := slog.String("user_id", sess.Claims().UserId)
+		handle(c, o, func() (Result, error) {
+			req, ok := bind[Req](c, o, userAttr)
+			if !ok {
+				return Result{}, errBindFailed
+			}
+			return fn(ctx, req, sess, claims)
+		}, userAttr)
+	}
+}
+
+// mustClaimsStruct 注册路由时检查 T 是否为结构体
+func mustClaimsStruct[T any]() {
+	if t := reflect.TypeFor[T](); t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("gint: claims 类型 %s 必须是结构体", t))
+	}
+}
+
+// typedSession 获取 Session 并将 Claims 解码为 T，失败时直接输出响应并返回 ok=false
+func typedSession[T any](ctx *gctx.Context, o *options, start time.Time) (session.Session, T, bool) {
+	var zero T
+	sess, err := session.Get(ctx)
+	if err != nil {
+		rejectSession(ctx.Context, o, start, "获取 Session 失败", err)
+		return nil, zero, false
+	}
+
+	claims, err := DecodeClaims[T](sess.Claims())
+	if err != nil {
+		slog.Warn("解析 Claims 失败", withTrace(ctx.Context, []any{
+			slog.String("path", ctx.Request.URL.Path),
+			slog.String("user_id", sess.Claims().UserId),
+			slog.Any("err", err)})...)
+		recordError(ctx.Context, err, http.StatusUnauthorized, http.StatusUnauthorized, false)
+		abortUnauthorized(ctx.Context)
+		observe(ctx.Context, o, start, 0, err)
+		return nil, zero, false
+	}
+	return sess, claims, true
+}
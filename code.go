@@ -14,6 +14,12 @@
 
 package gint
 
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
 // 统一的响应码定义
 const (
 	// CodeSuccess 成功
@@ -26,6 +32,10 @@ const (
 	// CodeError 错误
 	// 请求处理失败
 	CodeError = 2
+
+	// CodeResponseTooLarge 响应过大
+	// 响应体超过 WithResponseSizeLimit 设置的限制且策略为 SizeReject
+	CodeResponseTooLarge = 3
 )
 
 // CodeMessage 响应码对应的默认消息
@@ -34,6 +44,8 @@ var CodeMessage = map[int]string{
 	CodeSuccess: "成功",
 	CodeWarning: "警告",
 	CodeError:   "错误",
+
+	CodeResponseTooLarge: "响应数据过大",
 }
 
 // GetCodeMessage 获取响应码对应的默认消息
@@ -41,6 +53,9 @@ func GetCodeMessage(code int) string {
 	if msg, ok := CodeMessage[code]; ok {
 		return msg
 	}
+	if msg, ok := definedCodeMessage(code); ok {
+		return msg
+	}
 
 	// 根据范围返回默认消息
 	switch {
@@ -102,3 +117,53 @@ func ErrorWithCode(code int, msg string) Result {
 		Data: nil,
 	}
 }
+
+// ============ 业务码与 HTTP 状态码的映射 ============
+
+var (
+	errorHTTPStatus atomic.Int64 // 业务逻辑返回 error 时的 HTTP 状态码，0 表示 200
+	codeHTTPStatus  sync.Map     // 业务码 -> HTTP 状态码
+)
+
+// SetErrorHTTPStatus 设置业务逻辑返回 error 时响应的 HTTP 状态码，默认 200
+// 适用于依赖 HTTP 状态码判断成功与否的 API 网关和监控系统
+// 注意：应该在程序启动时调用
+//
+// 示例:
+//
+//	gint.SetErrorHTTPStatus(http.StatusInternalServerError)
+func SetErrorHTTPStatus(status int) {
+	errorHTTPStatus.Store(int64(status))
+}
+
+// SetCodeHTTPStatus 设置业务码对应的 HTTP 状态码
+// 对业务逻辑返回 error 以及直接返回该业务码的 Result 均生效，优先级高于 SetErrorHTTPStatus
+// 注意：应该在程序启动时调用
+//
+// 示例:
+//
+//	gint.SetCodeHTTPStatus(gint.CodeError, http.StatusBadRequest)
+//	gint.SetCodeHTTPStatus(40401, http.StatusNotFound)
+func SetCodeHTTPStatus(code, status int) {
+	codeHTTPStatus.Store(code, status)
+}
+
+// httpStatusForCode 获取业务码对应的 HTTP 状态码
+func httpStatusForCode(code int) (int, bool) {
+	status, ok := codeHTTPStatus.Load(code)
+	if !ok {
+		return 0, false
+	}
+	return status.(int), true
+}
+
+// httpStatusForError 获取业务逻辑返回 error 时的 HTTP 状态码
+func httpStatusForError(code int) int {
+	if status, ok := httpStatusForCode(code); ok {
+		return status
+	}
+	if status := errorHTTPStatus.Load(); status != 0 {
+		return int(status)
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ctxCodeNamespaceKey 在 Context 中存储路由组业务码命名空间的 key
+const ctxCodeNamespaceKey = "gint:code_namespace"
+
+// CodeNamespace 模块的业务码命名空间，如用户模块使用 10000-19999、订单模块使用 20000-29999
+// 模块内只使用从 0 开始的局部码，由命名空间加上前缀，避免多个模块的业务码重叠
+//
+// 示例:
+//
+//	var userCodes = gint.NewCodeNamespace("user", 10000, 10000)
+//	var CodeUserNotFound = userCodes.Define(1, "用户不存在") // 10001
+//
+//	return userCodes.Error(1, ""), nil // {"code": 10001, "msg": "用户不存在"}
+type CodeNamespace struct {
+	name string
+	base int // 起始业务码
+	size int // 业务码数量
+}
+
+var (
+	codeNamespacesMu sync.RWMutex
+	codeNamespaces   []*CodeNamespace
+	codeMessages     = make(map[int]string) // 命名空间中定义的业务码 -> 默认消息
+)
+
+// NewCodeNamespace 注册业务码命名空间，包含 [base, base+size) 范围内的业务码
+// 名称重复、范围与已注册的命名空间重叠或包含 0-3 的通用响应码时 panic
+// 注意：应该在程序启动时调用，通常定义为包级变量
+func NewCodeNamespace(name string, base, size int) *CodeNamespace {
+	if size <= 0 {
+		panic(fmt.Sprintf("gint: 业务码命名空间 %s 的大小必须大于 0", name))
+	}
+	if base <= CodeResponseTooLarge {
+		panic(fmt.Sprintf("gint: 业务码命名空间 %s 不能包含通用响应码 0-3", name))
+	}
+
+	codeNamespacesMu.Lock()
+	defer codeNamespacesMu.Unlock()
+	ns := &CodeNamespace{name: name, base: base, size: size}
+	for _, other := range codeNamespaces {
+		if other.name == name {
+			panic(fmt.Sprintf("gint: 业务码命名空间 %s 重复注册", name))
+		}
+		if base < other.base+other.size && other.base < base+size {
+			panic(fmt.Sprintf("gint: 业务码命名空间 %s %s 与 %s %s 重叠", name, ns.rangeString(), other.name, other.rangeString()))
+		}
+	}
+	codeNamespaces = append(codeNamespaces, ns)
+	return ns
+}
+
+// CodeNamespaceOf 查找业务码所属的命名空间
+func CodeNamespaceOf(code int) (*CodeNamespace, bool) {
+	codeNamespacesMu.RLock()
+	defer codeNamespacesMu.RUnlock()
+	for _, ns := range codeNamespaces {
+		if ns.contains(code) {
+			return ns, true
+		}
+	}
+	return nil, false
+}
+
+// Name 返回命名空间名称
+func (ns *CodeNamespace) Name() string {
+	return ns.name
+}
+
+// Code 将模块内的局部码转换为完整的业务码，局部码超出命名空间范围时 panic
+func (ns *CodeNamespace) Code(local int) int {
+	if local < 0 || local >= ns.size {
+		panic(fmt.Sprintf("gint: 局部码 %d 超出业务码命名空间 %s 的范围 %s", local, ns.name, ns.rangeString()))
+	}
+	return ns.base + local
+}
+
+// Define 定义业务码的默认消息并返回完整的业务码，同一个业务码重复定义时 panic
+// 定义后 ErrorWithCode、Error 在消息为空时使用该默认消息
+func (ns *CodeNamespace) Define(local int, msg string) int {
+	code := ns.Code(local)
+
+	codeNamespacesMu.Lock()
+	defer codeNamespacesMu.Unlock()
+	if _, ok := codeMessages[code]; ok {
+		panic(fmt.Sprintf("gint: 业务码 %d（%s 命名空间的 %d）重复定义", code, ns.name, local))
+	}
+	codeMessages[code] = msg
+	return code
+}
+
+// Error 创建命名空间内业务码的错误响应，msg 为空时使用 Define 定义的默认消息
+func (ns *CodeNamespace) Error(local int, msg string) Result {
+	return ErrorWithCode(ns.Code(local), msg)
+}
+
+// HTTPStatus 设置命名空间内业务码对应的 HTTP 状态码，参见 SetCodeHTTPStatus
+func (ns *CodeNamespace) HTTPStatus(local, status int) *CodeNamespace {
+	SetCodeHTTPStatus(ns.Code(local), status)
+	return ns
+}
+
+// Group 创建使用该命名空间的路由组
+// 组内的处理函数和中间件可以通过 CodeNamespaceFrom 获取命名空间，如公共的校验逻辑按所在模块返回业务码
+//
+// 示例:
+//
+//	users := userCodes.Group(router, "/users")
+//	users.GET("/:id", gint.S(getUser))
+func (ns *CodeNamespace) Group(router gin.IRouter, relativePath string, handlers ...gin.HandlerFunc) *gin.RouterGroup {
+	return router.Group(relativePath, append([]gin.HandlerFunc{func(c *gin.Context) {
+		c.Set(ctxCodeNamespaceKey, ns)
+		c.Next()
+	}}, handlers...)...)
+}
+
+// CodeNamespaceFrom 获取当前请求所在路由组的业务码命名空间
+func CodeNamespaceFrom(c *gin.Context) (*CodeNamespace, bool) {
+	v, ok := c.Get(ctxCodeNamespaceKey)
+	if !ok {
+		return nil, false
+	}
+	ns, ok := v.(*CodeNamespace)
+	return ns, ok
+}
+
+// contains 判断业务码是否属于该命名空间
+func (ns *CodeNamespace) contains(code int) bool {
+	return code >= ns.base && code < ns.base+ns.size
+}
+
+// rangeString 返回命名空间的范围，如 [10000, 19999]
+func (ns *CodeNamespace) rangeString() string {
+	return fmt.Sprintf("[%d, %d]", ns.base, ns.base+ns.size-1)
+}
+
+// definedCodeMessage 获取命名空间中定义的业务码默认消息
+func definedCodeMessage(code int) (string, bool) {
+	codeNamespacesMu.RLock()
+	defer codeNamespacesMu.RUnlock()
+	msg, ok := codeMessages[code]
+	return msg, ok
+}
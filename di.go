@@ -0,0 +1,295 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Starter 需要在服务启动时执行初始化的依赖
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper 需要在服务停止时释放资源的依赖
+// 实现了 io.Closer 的依赖同样会在停止时关闭
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+var (
+	errorType       = reflect.TypeFor[error]()
+	handlerFuncType = reflect.TypeFor[gin.HandlerFunc]()
+)
+
+// Container 轻量的依赖注入容器
+// 通过构造函数注册依赖，构造函数的参数由容器自动注入；每种类型只构造一次（单例）
+type Container struct {
+	mu        sync.Mutex
+	providers map[reflect.Type]*provider
+	built     []reflect.Value // 已构造的依赖，按构造顺序排列（被依赖者在前）
+}
+
+// provider 单个依赖的构造函数与实例
+type provider struct {
+	ctor     reflect.Value
+	value    reflect.Value
+	built    bool
+	building bool // 正在构造，用于检测循环依赖
+}
+
+// NewContainer 创建依赖注入容器
+func NewContainer() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]*provider),
+	}
+}
+
+// defaultContainer 包级函数使用的默认容器
+var defaultContainer = NewContainer()
+
+// DefaultContainer 返回包级函数使用的默认容器
+func DefaultContainer() *Container {
+	return defaultContainer
+}
+
+// Provide 注册构造函数
+// 构造函数的形式为 func(deps...) T 或 func(deps...) (T, error)，参数为其他已注册的依赖
+// 构造函数不合法或同一类型重复注册时 panic（属于启动阶段的编程错误）
+func (c *Container) Provide(ctor any) {
+	v := reflect.ValueOf(ctor)
+	t := v.Type()
+	if t.Kind() != reflect.Func {
+		panic(fmt.Sprintf("gint: Provide 需要构造函数，实际为 %s", t))
+	}
+	if t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorType) {
+		panic(fmt.Sprintf("gint: 构造函数 %s 的返回值必须为 T 或 (T, error)", t))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := t.Out(0)
+	if _, ok := c.providers[out]; ok {
+		panic(fmt.Sprintf("gint: 类型 %s 重复注册", out))
+	}
+	c.providers[out] = &provider{ctor: v}
+}
+
+// Supply 直接注册已构造好的实例，如配置、数据库连接
+// 这些实例的生命周期由调用方管理，Start/Stop 不会处理
+// 实例为 nil 时无法确定类型，返回错误且不注册任何实例
+func (c *Container) Supply(values ...any) error {
+	for i, val := range values {
+		if val == nil {
+			return fmt.Errorf("Supply 的第%d个实例为 nil，无法确定类型", i+1)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, val := range values {
+		v := reflect.ValueOf(val)
+		if _, ok := c.providers[v.Type()]; ok {
+			panic(fmt.Sprintf("gint: 类型 %s 重复注册", v.Type()))
+		}
+		c.providers[v.Type()] = &provider{value: v, built: true}
+	}
+	return nil
+}
+
+// Invoke 调用函数，参数由容器注入，返回函数的返回值
+func (c *Container) Invoke(fn any) ([]reflect.Value, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return nil, fmt.Errorf("Invoke 需要函数，实际为 %s", v.Type())
+	}
+
+	args, err := c.lockedArgs(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	return v.Call(args), nil
+}
+
+// lockedResolve 持有锁获取类型对应的实例，构造函数 panic 时同样释放锁
+func (c *Container) lockedResolve(t reflect.Type) (reflect.Value, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resolve(t, nil)
+}
+
+// lockedArgs 持有锁解析函数的全部参数，构造函数 panic 时同样释放锁
+func (c *Container) lockedArgs(fn reflect.Type) ([]reflect.Value, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.args(fn, nil)
+}
+
+// Inject 使用容器中的依赖调用处理函数工厂，返回其创建的处理函数
+// 依赖在注册路由时解析，缺少依赖或构造失败时 panic，使问题在启动阶段暴露
+//
+// 示例:
+//
+//	router.GET("/users/:id", container.Inject(func(svc *UserService) gin.HandlerFunc {
+//	   return gint.B(svc.GetUser)
+//	}))
+func (c *Container) Inject(factory any) gin.HandlerFunc {
+	t := reflect.TypeOf(factory)
+	if t == nil || t.Kind() != reflect.Func || t.NumOut() != 1 || !t.Out(0).ConvertibleTo(handlerFuncType) {
+		panic(fmt.Sprintf("gint: Inject 需要返回 gin.HandlerFunc 的工厂函数，实际为 %v", t))
+	}
+	out, err := c.Invoke(factory)
+	if err != nil {
+		panic(fmt.Sprintf("gint: 注入依赖失败: %v", err))
+	}
+	return out[0].Convert(handlerFuncType).Interface().(gin.HandlerFunc)
+}
+
+// Start 按构造顺序调用已构造依赖的 Start 方法，通常在服务启动前调用
+// 某个依赖启动失败时立即返回错误
+func (c *Container) Start(ctx context.Context) error {
+	for _, v := range c.instances() {
+		if s, ok := v.Interface().(Starter); ok {
+			if err := s.Start(ctx); err != nil {
+				return fmt.Errorf("启动 %s 失败: %w", v.Type(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Stop 按构造的相反顺序调用已构造依赖的 Stop 或 Close 方法，通常在服务停止后调用
+// 会尝试停止所有依赖，返回合并后的错误
+func (c *Container) Stop(ctx context.Context) error {
+	instances := c.instances()
+	var errs []error
+	for i := len(instances) - 1; i >= 0; i-- {
+		v := instances[i]
+		var err error
+		switch s := v.Interface().(type) {
+		case Stopper:
+			err = s.Stop(ctx)
+		case io.Closer:
+			err = s.Close()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("停止 %s 失败: %w", v.Type(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// instances 返回已构造依赖的快照
+func (c *Container) instances() []reflect.Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]reflect.Value(nil), c.built...)
+}
+
+// resolve 获取类型对应的实例，必要时调用构造函数，调用方需持有锁
+// path 为当前的依赖链，用于输出循环依赖和缺失依赖的错误信息
+func (c *Container) resolve(t reflect.Type, path []reflect.Type) (reflect.Value, error) {
+	p, ok := c.providers[t]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("缺少依赖 %s%s", t, chain(path))
+	}
+	if p.built {
+		return p.value, nil
+	}
+	if p.building {
+		return reflect.Value{}, fmt.Errorf("存在循环依赖 %s", chain(append(path, t)))
+	}
+
+	p.building = true
+	defer func() { p.building = false }()
+
+	args, err := c.args(p.ctor.Type(), append(path, t))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	out := p.ctor.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("构造 %s 失败: %w", t, out[1].Interface().(error))
+	}
+
+	p.value, p.built = out[0], true
+	c.built = append(c.built, out[0])
+	return out[0], nil
+}
+
+// args 解析函数的全部参数
+func (c *Container) args(fn reflect.Type, path []reflect.Type) ([]reflect.Value, error) {
+	args := make([]reflect.Value, fn.NumIn())
+	for i := range args {
+		v, err := c.resolve(fn.In(i), path)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return args, nil
+}
+
+// chain 格式化依赖链
+func chain(path []reflect.Type) string {
+	if len(path) == 0 {
+		return ""
+	}
+	names := make([]string, len(path))
+	for i, t := range path {
+		names[i] = t.String()
+	}
+	return "（" + strings.Join(names, " -> ") + "）"
+}
+
+// Provide 在默认容器中注册构造函数
+//
+// 示例:
+//
+//	gint.Provide(NewDB)
+//	gint.Provide(NewUserRepository)
+//	gint.Provide(NewUserService)
+func Provide(ctor any) {
+	defaultContainer.Provide(ctor)
+}
+
+// Supply 在默认容器中注册已构造好的实例
+func Supply(values ...any) error {
+	return defaultContainer.Supply(values...)
+}
+
+// Inject 使用默认容器中的依赖创建处理函数
+func Inject(factory any) gin.HandlerFunc {
+	return defaultContainer.Inject(factory)
+}
+
+// Resolve 从容器中获取指定类型的依赖
+func Resolve[T any](c *Container) (T, error) {
+	var zero T
+	v, err := c.lockedResolve(reflect.TypeFor[T]())
+	if err != nil {
+		return zero, err
+	}
+	t, _ := v.Interface().(T)
+	return t, nil
+}
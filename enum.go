@@ -0,0 +1,232 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// EnumType 可注册为枚举的底层类型
+type EnumType interface {
+	~string | ~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// enumInfo 已注册枚举的取值信息
+type enumInfo struct {
+	values []any
+	set    map[any]struct{}
+}
+
+var (
+	enumMu       sync.RWMutex
+	enumRegistry = make(map[reflect.Type]*enumInfo)
+	enumStructs  sync.Map // reflect.Type -> bool，缓存结构体是否包含枚举字段
+)
+
+// RegisterEnum 注册枚举类型及其允许的取值
+// 注册后，B/BS 绑定参数时会自动校验该类型的字段（零值视为未传，是否必填由 binding 标签决定），
+// 文档生成工具也可以通过 EnumValues 获取取值列表
+// 注意：应该在程序启动时注册，重复注册会覆盖之前的取值
+//
+// 示例:
+//
+//	type OrderStatus string
+//
+//	const (
+//	   OrderPending OrderStatus = "pending"
+//	   OrderDone    OrderStatus = "done"
+//	)
+//
+//	func init() {
+//	   gint.RegisterEnum(OrderPending, OrderDone)
+//	}
+func RegisterEnum[T EnumType](values ...T) {
+	info := &enumInfo{
+		values: make([]any, 0, len(values)),
+		set:    make(map[any]struct{}, len(values)),
+	}
+	for _, v := range values {
+		info.values = append(info.values, v)
+		info.set[v] = struct{}{}
+	}
+
+	enumMu.Lock()
+	enumRegistry[reflect.TypeFor[T]()] = info
+	enumMu.Unlock()
+
+	// 注册信息变化后清空结构体缓存
+	enumStructs.Clear()
+}
+
+// EnumValues 获取已注册枚举类型的取值列表
+// 供 OpenAPI 等文档生成工具使用
+func EnumValues(t reflect.Type) ([]any, bool) {
+	enumMu.RLock()
+	defer enumMu.RUnlock()
+	info, ok := enumRegistry[t]
+	if !ok {
+		return nil, false
+	}
+	return append([]any(nil), info.values...), true
+}
+
+// lookupEnum 查找枚举类型信息
+func lookupEnum(t reflect.Type) (*enumInfo, bool) {
+	enumMu.RLock()
+	defer enumMu.RUnlock()
+	info, ok := enumRegistry[t]
+	return info, ok
+}
+
+// EnumRule 已注册枚举规则
+type EnumRule[T EnumType] struct{}
+
+func (r *EnumRule[T]) Validate(value any) error {
+	v, ok := value.(T)
+	if !ok {
+		return nil
+	}
+	var zero T
+	if v == zero {
+		return nil
+	}
+	info, ok := lookupEnum(reflect.TypeFor[T]())
+	if !ok {
+		return nil
+	}
+	if _, ok := info.set[v]; !ok {
+		return fmt.Errorf("的值不在允许的范围内")
+	}
+	return nil
+}
+
+// Enum 已注册枚举规则构造函数
+// 取值来自 RegisterEnum，避免在校验和文档中重复维护 In(...) 列表
+func Enum[T EnumType]() ValidationRule {
+	return &EnumRule[T]{}
+}
+
+// validateEnums 校验结构体中所有已注册枚举类型的字段
+func validateEnums(obj any) []string {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || !hasEnumField(v.Type()) {
+		return nil
+	}
+
+	var errs []string
+	walkEnums(v, "", &errs)
+	return errs
+}
+
+// walkEnums 递归检查结构体字段
+func walkEnums(v reflect.Value, prefix string, errs *[]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := fieldName(field)
+		if prefix != "" && !field.Anonymous {
+			name = prefix + "." + name
+		} else if field.Anonymous {
+			name = prefix
+		}
+		checkEnumValue(v.Field(i), name, errs)
+	}
+}
+
+// checkEnumValue 检查单个值，支持指针、切片和嵌套结构体
+func checkEnumValue(v reflect.Value, name string, errs *[]string) {
+	if info, ok := lookupEnum(v.Type()); ok {
+		if !v.IsZero() {
+			if _, ok := info.set[v.Interface()]; !ok {
+				*errs = append(*errs, fmt.Sprintf("%s的值不在允许的范围内", name))
+			}
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			checkEnumValue(v.Elem(), name, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			checkEnumValue(v.Index(i), fmt.Sprintf("%s[%d]", name, i), errs)
+		}
+	case reflect.Struct:
+		if hasEnumField(v.Type()) {
+			walkEnums(v, name, errs)
+		}
+	}
+}
+
+// hasEnumField 判断类型中是否包含已注册的枚举字段（结果会被缓存）
+func hasEnumField(t reflect.Type) bool {
+	if cached, ok := enumStructs.Load(t); ok {
+		return cached.(bool)
+	}
+	// 只缓存最终结果，并发的首次调用各自计算，不会读到未完成的结果
+	found := containsEnum(t, make(map[reflect.Type]bool))
+	enumStructs.Store(t, found)
+	return found
+}
+
+// containsEnum 递归判断类型是否包含枚举，visiting 为正在检查的结构体，防止自引用类型无限递归
+func containsEnum(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if _, ok := lookupEnum(t); ok {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return containsEnum(t.Elem(), visiting)
+	case reflect.Struct:
+		if cached, ok := enumStructs.Load(t); ok {
+			return cached.(bool)
+		}
+		if visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() && containsEnum(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldName 获取字段对外的名称，依次使用 json、form、uri 标签，都没有时使用字段名
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
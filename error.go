@@ -33,4 +33,8 @@ var (
 
 	// ErrInvalidToken 表示无效的 Token
 	ErrInvalidToken = errors.New("无效的令牌")
+
+	// ErrResponseTooLarge 表示响应体超过大小限制被拒绝输出（SizeReject）
+	// 记录在 gin.Errors 中，访问日志和指标通过它识别被拒绝的响应
+	ErrResponseTooLarge = errors.New("响应体超过大小限制")
 )
@@ -0,0 +1,108 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errpage 为浏览器直接访问的请求渲染 HTML 错误页面
+// 开启后，包装器和中间件输出错误响应时，如果客户端的 Accept 头优先接受 text/html（如 OAuth 回调、浏览器直接打开链接），
+// 输出简单的 HTML 页面，而不是把 JSON 响应直接展示在浏览器中；其他客户端仍然输出 JSON
+package errpage
+
+import (
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page 错误页面模板的数据
+type Page struct {
+	Status  int    // HTTP 状态码
+	Code    int    // 业务码
+	Msg     string // 错误信息
+	TraceID string // 追踪 ID，便于用户反馈问题时提供
+}
+
+// Title 状态码对应的标准描述，如 Not Found
+func (p Page) Title() string {
+	return http.StatusText(p.Status)
+}
+
+// DefaultTemplate 默认的错误页面模板
+var DefaultTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Status}} {{.Title}}</title>
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;color:#333;display:flex;align-items:center;justify-content:center;min-height:90vh;margin:0}
+main{text-align:center;padding:24px}
+h1{font-size:64px;margin:0;color:#999}
+p{font-size:16px}
+small{color:#999}
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Status}}</h1>
+<p>{{.Msg}}</p>
+{{if .TraceID}}<small>Trace ID: {{.TraceID}}</small>{{end}}
+</main>
+</body>
+</html>
+`))
+
+// tmpl 当前使用的模板，为 nil 时未开启
+var tmpl atomic.Pointer[template.Template]
+
+// Enable 开启 HTML 错误页面，t 为 nil 时使用 DefaultTemplate
+// 模板的数据为 Page
+//
+// 示例:
+//
+//	errpage.Enable(template.Must(template.ParseFiles("templates/error.html")))
+func Enable(t *template.Template) {
+	if t == nil {
+		t = DefaultTemplate
+	}
+	tmpl.Store(t)
+}
+
+// Disable 关闭 HTML 错误页面
+func Disable() {
+	tmpl.Store(nil)
+}
+
+// Render 客户端优先接受 HTML 时渲染错误页面并返回 true，未开启或客户端偏好 JSON 时返回 false，由调用方输出 JSON
+// 不会终止请求，需要时由调用方调用 c.Abort
+func Render(c *gin.Context, p Page) bool {
+	t := tmpl.Load()
+	if t == nil || !PrefersHTML(c) {
+		return false
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(p.Status)
+	if err := t.Execute(c.Writer, p); err != nil {
+		slog.Error("渲染错误页面失败", slog.String("path", c.Request.URL.Path), slog.Any("err", err))
+	}
+	return true
+}
+
+// PrefersHTML 判断客户端是否优先接受 HTML
+// 按 Accept 头中的顺序协商，未携带 Accept 或为 */* 时视为偏好 JSON
+func PrefersHTML(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+}
@@ -0,0 +1,411 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/session"
+)
+
+// FieldAuthorizer 判断当前请求是否满足字段的授权条件
+// kind 为条件类型（如 role、perm），value 为要求的值
+type FieldAuthorizer func(c *gin.Context, kind, value string) bool
+
+// fieldAuthorizer 全局字段授权函数，为 nil 时使用 DefaultFieldAuthorizer
+var fieldAuthorizer atomic.Pointer[FieldAuthorizer]
+
+// SetFieldAuthorizer 设置字段级授权的判断函数，传入 nil 恢复默认实现
+//
+// 响应数据的结构体字段可以通过 auth 标签声明授权条件，不满足条件时该字段不会出现在响应中：
+//
+//	type ProductResp struct {
+//	   Name      string  `json:"name"`
+//	   Price     float64 `json:"price"`
+//	   CostPrice float64 `json:"cost_price" auth:"role=admin|finance"`
+//	   Supplier  string  `json:"supplier" auth:"perm=supplier:read"`
+//	}
+//
+// 同一标签中用 "|" 分隔多个可接受的值，用 "," 分隔多个条件，满足任意一个即可
+func SetFieldAuthorizer(fn FieldAuthorizer) {
+	if fn == nil {
+		fieldAuthorizer.Store(nil)
+		return
+	}
+	fieldAuthorizer.Store(&fn)
+}
+
+// DefaultFieldAuthorizer 默认的字段授权函数
+// 从当前请求已获取的 Session 或 Claims 中读取 Data["roles"]、Data["permissions"]（逗号分隔）进行匹配，
+// 支持的条件类型为 role 和 perm；未登录（或 W/B 包装器中未获取会话）时不满足任何条件
+func DefaultFieldAuthorizer(c *gin.Context, kind, value string) bool {
+	return claimsGrant(contextClaims(c), kind, value)
+}
+
+// claimsGrant 判断 Claims.Data 中的角色或权限列表是否包含 value
+func claimsGrant(claims *session.Claims, kind, value string) bool {
+	if claims == nil {
+		return false
+	}
+
+	var key string
+	switch kind {
+	case "role":
+		key = "roles"
+	case "perm":
+		key = "permissions"
+	default:
+		return false
+	}
+	for _, v := range strings.Split(claims.Data[key], ",") {
+		if strings.TrimSpace(v) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// contextClaims 获取当前请求已校验的 Claims，不触发会话查询
+func contextClaims(c *gin.Context) *session.Claims {
+	if val, ok := c.Get(session.CtxClaimsKey); ok {
+		if claims, ok := val.(*session.Claims); ok {
+			return claims
+		}
+	}
+	if val, ok := c.Get(session.CtxSessionKey); ok {
+		if sess, ok := val.(session.Session); ok {
+			return sess.Claims()
+		}
+	}
+	return nil
+}
+
+// authCondition 字段的单个授权条件
+type authCondition struct {
+	kind   string
+	values []string
+}
+
+// authField 结构体字段的编码信息
+type authField struct {
+	index     int
+	name      string // JSON 字段名，嵌入字段展开时为空
+	omitEmpty bool
+	embedded  bool
+	conds     []authCondition
+}
+
+// authType 类型的字段授权信息
+type authType struct {
+	hasAuth bool // 类型（含嵌套类型）中是否存在 auth 标签
+	fields  []authField
+}
+
+// authTypes 缓存类型的字段授权信息
+var authTypes sync.Map // map[reflect.Type]*authType
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// filterFields 移除响应数据中当前请求无权查看的字段
+// 数据类型中不包含 auth 标签时原样返回
+func filterFields(c *gin.Context, data any) any {
+	if data == nil {
+		return nil
+	}
+	v := reflect.ValueOf(data)
+	if !typeHasAuth(v.Type()) {
+		return data
+	}
+
+	allow := DefaultFieldAuthorizer
+	if fn := fieldAuthorizer.Load(); fn != nil {
+		allow = *fn
+	}
+	// 同一请求中相同条件只判断一次
+	decided := make(map[string]bool)
+	permit := func(conds []authCondition) bool {
+		for _, cond := range conds {
+			for _, val := range cond.values {
+				key := cond.kind + "=" + val
+				ok, seen := decided[key]
+				if !seen {
+					ok = allow(c, cond.kind, val)
+					decided[key] = ok
+				}
+				if ok {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return filterValue(v, permit)
+}
+
+// filterValue 递归过滤值
+func filterValue(v reflect.Value, permit func([]authCondition) bool) any {
+	if !v.IsValid() {
+		return nil
+	}
+	t := v.Type()
+	if !typeHasAuth(t) {
+		return v.Interface()
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return filterValue(v.Elem(), permit)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = filterValue(v.Index(i), permit)
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := reflect.MakeMapWithSize(reflect.MapOf(t.Key(), reflect.TypeFor[any]()), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item := filterValue(iter.Value(), permit)
+			if item == nil {
+				m.SetMapIndex(iter.Key(), reflect.Zero(m.Type().Elem()))
+			} else {
+				m.SetMapIndex(iter.Key(), reflect.ValueOf(item))
+			}
+		}
+		return m.Interface()
+	case reflect.Struct:
+		obj := &orderedObject{}
+		filterStruct(v, permit, obj, nil)
+		return obj
+	}
+	return v.Interface()
+}
+
+// filterStruct 将结构体字段按声明顺序写入 obj，嵌入结构体的字段展开到同一层
+// outer 为外层结构体的字段名，与 encoding/json 一致，外层字段优先于嵌入结构体中的同名字段
+func filterStruct(v reflect.Value, permit func([]authCondition) bool, obj *orderedObject, outer map[string]bool) {
+	info := loadAuthType(v.Type())
+	names := maps.Clone(outer)
+	if names == nil {
+		names = make(map[string]bool)
+	}
+	for _, f := range info.fields {
+		if !f.embedded {
+			names[f.name] = true
+		}
+	}
+
+	for _, f := range info.fields {
+		fv := v.Field(f.index)
+		if len(f.conds) > 0 && !permit(f.conds) {
+			continue
+		}
+		if f.embedded {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			filterStruct(fv, permit, obj, names)
+			continue
+		}
+		if outer[f.name] || obj.has(f.name) {
+			continue
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		obj.add(f.name, filterValue(fv, permit))
+	}
+}
+
+// typeHasAuth 判断类型中是否存在需要过滤的字段
+func typeHasAuth(t reflect.Type) bool {
+	return hasAuth(t, nil)
+}
+
+// hasAuth 判断类型中是否存在需要过滤的字段，visiting 为正在解析的类型
+func hasAuth(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return hasAuth(t.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			// 递归类型按需要过滤处理，过滤结果与直接编码一致，只是多一次遍历
+			return true
+		}
+		return buildAuthType(t, visiting).hasAuth
+	case reflect.Interface:
+		// 接口的动态类型在编码时才能确定（如 gin.H、[]any、any 字段），由 filterValue 按实际的值判断
+		return true
+	}
+	return false
+}
+
+// loadAuthType 获取类型的字段授权信息
+func loadAuthType(t reflect.Type) *authType {
+	return buildAuthType(t, nil)
+}
+
+// buildAuthType 解析并缓存类型的字段授权信息
+func buildAuthType(t reflect.Type, visiting map[reflect.Type]bool) *authType {
+	if cached, ok := authTypes.Load(t); ok {
+		return cached.(*authType)
+	}
+
+	info := &authType{}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		authTypes.Store(t, info)
+		return info
+	}
+
+	if visiting == nil {
+		visiting = make(map[reflect.Type]bool)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		f := authField{index: i, name: name, omitEmpty: strings.Contains(","+opts+",", ",omitempty,")}
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case sf.Anonymous && name == "" && ft.Kind() == reflect.Struct:
+			f.embedded = true
+		case !sf.IsExported():
+			continue
+		case name == "":
+			f.name = sf.Name
+		}
+
+		if auth := sf.Tag.Get("auth"); auth != "" {
+			f.conds = parseAuthTag(auth)
+			info.hasAuth = true
+		}
+		if hasAuth(sf.Type, visiting) {
+			info.hasAuth = true
+		}
+		info.fields = append(info.fields, f)
+	}
+
+	actual, _ := authTypes.LoadOrStore(t, info)
+	return actual.(*authType)
+}
+
+// parseAuthTag 解析 auth 标签，如 "role=admin|finance,perm=cost:read"
+func parseAuthTag(tag string) []authCondition {
+	var conds []authCondition
+	for _, part := range strings.Split(tag, ",") {
+		kind, values, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || kind == "" || values == "" {
+			panic("gint: auth 标签格式错误: " + tag)
+		}
+		conds = append(conds, authCondition{kind: kind, values: strings.Split(values, "|")})
+	}
+	return conds
+}
+
+// isEmptyValue 与 encoding/json 的 omitempty 判断一致
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// orderedObject 保持字段顺序的 JSON 对象
+type orderedObject struct {
+	keys   []string
+	values []any
+}
+
+func (o *orderedObject) add(key string, val any) {
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, val)
+}
+
+func (o *orderedObject) has(key string) bool {
+	for _, k := range o.keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON 按字段顺序输出 JSON 对象
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		val, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gclient 提供调用其他 gint 服务的 HTTP 客户端辅助方法
+package gclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ink-code/gint/middlewares/deadline"
+)
+
+// Transport 将请求 Context 的剩余超时预算通过 X-Request-Timeout 请求头传递给下游服务
+// 下游服务使用 deadline 中间件读取后设置为自身的截止时间，整条调用链共用同一个超时预算
+type Transport struct {
+	// Base 实际发送请求的 RoundTripper，为 nil 时使用 http.DefaultTransport
+	Base http.RoundTripper
+	// Reserve 从剩余预算中预留的时间，用于本服务处理下游响应，默认不预留
+	Reserve time.Duration
+}
+
+// RoundTrip 实现 http.RoundTripper
+// 预算已经耗尽时不发送请求，直接返回 context.DeadlineExceeded
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if dl, ok := req.Context().Deadline(); ok {
+		remaining := time.Until(dl) - t.Reserve
+		if remaining <= 0 {
+			return nil, context.DeadlineExceeded
+		}
+		// RoundTripper 不能修改调用方的请求
+		req = req.Clone(req.Context())
+		req.Header.Set(deadline.Header, deadline.Format(remaining))
+	}
+	return t.base().RoundTrip(req)
+}
+
+// base 返回实际发送请求的 RoundTripper
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// New 创建传递超时预算的 HTTP 客户端
+//
+// 示例:
+//
+//	client := gclient.New()
+//	req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, "http://user-svc/users/1", nil)
+//	resp, err := client.Do(req)
+func New() *http.Client {
+	return &http.Client{Transport: &Transport{}}
+}
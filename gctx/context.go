@@ -20,14 +20,54 @@ package gctx
 
 import (
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// CtxTxKey 在 Context 中存储请求范围事务的 key
+	CtxTxKey = "gint:tx"
+	// CtxBasePathKey 在 Context 中存储部署基础路径的 key（由 basepath 中间件设置）
+	CtxBasePathKey = "gint:base_path"
+)
+
 // Context 是对 gin.Context 的增强封装
 // 提供了更便捷的参数获取和类型转换方法
 type Context struct {
 	*gin.Context
+	followRequest bool // Done、Err、Deadline 是否跟随请求的 Context
+}
+
+// FollowRequest 创建 Done、Err、Deadline 跟随请求 Context（c.Request.Context()）的 Context
+// gin 只在开启 engine.ContextWithFallback 时才这样做；客户端断开后，以该 Context 执行的数据库查询等操作会被取消
+func FollowRequest(c *gin.Context) *Context {
+	return &Context{Context: c, followRequest: true}
+}
+
+// Deadline 见 FollowRequest
+func (c *Context) Deadline() (time.Time, bool) {
+	if c.followRequest && c.Request != nil {
+		return c.Request.Context().Deadline()
+	}
+	return c.Context.Deadline()
+}
+
+// Done 见 FollowRequest
+func (c *Context) Done() <-chan struct{} {
+	if c.followRequest && c.Request != nil {
+		return c.Request.Context().Done()
+	}
+	return c.Context.Done()
+}
+
+// Err 见 FollowRequest
+func (c *Context) Err() error {
+	if c.followRequest && c.Request != nil {
+		return c.Request.Context().Err()
+	}
+	return c.Context.Err()
 }
 
 // Value 封装了值和错误，支持链式类型转换
@@ -158,6 +198,37 @@ func (c *Context) SetUserId(userId string) {
 	c.Set("user_id", userId)
 }
 
+// Tx 获取请求范围内的事务（由 tx 中间件开启），未开启时返回 nil
+// 返回值为事务开启函数创建的对象，如 *sql.Tx，使用时需要类型断言
+func (c *Context) Tx() any {
+	val, _ := c.Get(CtxTxKey)
+	return val
+}
+
+// BasePath 获取服务对外的基础路径，如部署在网关 /api/v1/serviceX 下时返回 "/api/v1/serviceX"
+// 未配置 basepath 中间件时返回空字符串
+func (c *Context) BasePath() string {
+	return c.GetString(CtxBasePathKey)
+}
+
+// URL 将服务内的绝对路径转换为客户端可访问的路径（加上基础路径）
+// 非 "/" 开头的路径（相对路径、完整 URL）原样返回
+func (c *Context) URL(path string) string {
+	return JoinBasePath(c.BasePath(), path)
+}
+
+// JoinBasePath 拼接基础路径与服务内的绝对路径
+// 非 "/" 开头或 "//" 开头（协议相对 URL）的路径原样返回
+func JoinBasePath(base, path string) string {
+	if base == "" || !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return path
+	}
+	if path == "/" {
+		return base
+	}
+	return base + path
+}
+
 // EventStream 返回一个用于 Server-Sent Events 的通道
 // 用于实现服务器推送功能
 // 注意：调用者需要在完成后关闭返回的 channel
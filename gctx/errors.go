@@ -0,0 +1,41 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gctx
+
+import "github.com/gin-gonic/gin"
+
+// ErrorMeta 包装器写入 gin.Errors 的错误元数据（gin.Error.Meta）
+// 错误信息已作为响应的 msg 返回给客户端时，gin.Error.Type 为 gin.ErrorTypePublic，否则为 gin.ErrorTypePrivate
+type ErrorMeta struct {
+	Code   int // 响应的业务码
+	Status int // 响应的 HTTP 状态码
+}
+
+// HandlerError 获取包装器记录的请求错误，包括参数绑定和校验失败、会话校验失败、处理函数返回的 error 和失败的业务码
+// 访问日志、指标等中间件应通过它读取错误，与客户端看到的响应保持一致；没有错误时 ok 为 false
+//
+// 示例:
+//
+//	if ge, meta, ok := gctx.HandlerError(c); ok {
+//	   slog.Info("请求失败", slog.Int("code", meta.Code), slog.Any("err", ge.Err))
+//	}
+func HandlerError(c *gin.Context) (ge *gin.Error, meta ErrorMeta, ok bool) {
+	for i := len(c.Errors) - 1; i >= 0; i-- {
+		if meta, ok := c.Errors[i].Meta.(ErrorMeta); ok {
+			return c.Errors[i], meta, true
+		}
+	}
+	return nil, ErrorMeta{}, false
+}
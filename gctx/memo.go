@@ -0,0 +1,130 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gctx
+
+import (
+	"fmt"
+	"hash/maphash"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ctxMemoKey 在 Context 中存储 Memo 缓存的 key
+const ctxMemoKey = "gint:memo"
+
+// memoLocks 保护 Memo 缓存表的创建，按请求的 *gin.Context 分片，不同请求之间基本不会互相等待
+var (
+	memoLocks [64]sync.Mutex
+	memoSeed  = maphash.MakeSeed()
+)
+
+// keyStore Memo 使用的 Context 存取方法，*gin.Context 和 *Context 都满足
+type keyStore interface {
+	Get(key string) (value any, exists bool)
+	Set(key string, value any)
+}
+
+// memoTable 单个请求的 Memo 缓存
+type memoTable struct {
+	mu      sync.Mutex
+	entries map[string]any // key -> *memoEntry[T]
+}
+
+// memoEntry 单个缓存值，once 保证并发调用时只计算一次
+type memoEntry[T any] struct {
+	once sync.Once
+	val  T
+	err  error
+}
+
+// Memo 在当前请求内只计算一次 fn，之后以相同 key 调用时直接返回缓存的结果（包括 error）
+// 适用于多个中间件和处理函数都需要的派生数据，如解析后的客户端信息、加载的用户资料
+// 同一 key 必须使用相同的类型 T，否则 panic；缓存随请求结束释放
+//
+// 示例:
+//
+//	profile, err := gctx.Memo(c, "profile", func() (*Profile, error) {
+//	   return loadProfile(c.Request.Context(), c.GetString("user_id"))
+//	})
+func Memo[T any](c keyStore, key string, fn func() (T, error)) (T, error) {
+	table := memoTableOf(c)
+
+	table.mu.Lock()
+	raw, ok := table.entries[key]
+	if !ok {
+		raw = &memoEntry[T]{}
+		table.entries[key] = raw
+	}
+	table.mu.Unlock()
+
+	entry, ok := raw.(*memoEntry[T])
+	if !ok {
+		var zero T
+		panic(fmt.Sprintf("gint: Memo key %s 已用于其他类型，当前类型为 %T", key, zero))
+	}
+	entry.once.Do(func() {
+		// fn panic 时 once 同样会结束，记录为 error，避免之后的调用拿到零值和 nil
+		defer func() {
+			if r := recover(); r != nil {
+				entry.err = fmt.Errorf("gint: Memo key %s 计算时 panic: %v", key, r)
+				panic(r)
+			}
+		}()
+		entry.val, entry.err = fn()
+	})
+	return entry.val, entry.err
+}
+
+// memoTableOf 获取或创建当前请求的 Memo 缓存
+func memoTableOf(c keyStore) *memoTable {
+	if table, ok := loadMemoTable(c); ok {
+		return table
+	}
+
+	mu := memoLock(c)
+	mu.Lock()
+	defer mu.Unlock()
+	if table, ok := loadMemoTable(c); ok {
+		return table
+	}
+	table := &memoTable{entries: make(map[string]any)}
+	c.Set(ctxMemoKey, table)
+	return table
+}
+
+// loadMemoTable 读取当前请求已创建的 Memo 缓存
+func loadMemoTable(c keyStore) (*memoTable, bool) {
+	val, ok := c.Get(ctxMemoKey)
+	if !ok {
+		return nil, false
+	}
+	table, ok := val.(*memoTable)
+	return table, ok
+}
+
+// memoLock 返回当前请求对应的锁，*Context 与其包装的 *gin.Context 使用同一把锁
+func memoLock(c keyStore) *sync.Mutex {
+	var gc *gin.Context
+	switch v := c.(type) {
+	case *Context:
+		gc = v.Context
+	case *gin.Context:
+		gc = v
+	default:
+		return &memoLocks[0]
+	}
+	return &memoLocks[maphash.Comparable(memoSeed, gc)%uint64(len(memoLocks))]
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// gRPC 健康检查协议（grpc.health.v1）的方法
+const (
+	grpcHealthCheck = "/grpc.health.v1.Health/Check"
+	grpcHealthWatch = "/grpc.health.v1.Health/Watch"
+)
+
+// gRPC 状态码，只列出用到的
+const (
+	grpcOK            = 0
+	grpcUnknown       = 2
+	grpcInvalidArg    = 3
+	grpcNotFound      = 5
+	grpcUnimplemented = 12
+)
+
+// grpc.health.v1.HealthCheckResponse.ServingStatus
+const (
+	grpcServing    = 1
+	grpcNotServing = 2
+)
+
+// WithGRPC 在同一端口上同时提供 gRPC 服务，handler 通常为 *grpc.Server（实现了 http.Handler）
+// Content-Type 为 application/grpc 的 HTTP/2 请求交给 handler 处理，不经过 engine 和 Wrap 添加的包装，其余请求照常交给 engine；
+// 同时开启明文 HTTP/2（h2c），配置了 TLS 时使用 TLS 上的 HTTP/2。
+// gRPC 请求计入处理中的请求数，与 HTTP 请求共用优雅关闭流程，并上报给 SetMetricsFunc 设置的指标回调
+//
+// 示例:
+//
+//	grpcServer := grpc.NewServer()
+//	pb.RegisterOrderServiceServer(grpcServer, orderService)
+//	srv := gint.NewServer(":8080", engine).WithGRPC(grpcServer).WithGRPCHealth()
+func (s *Server) WithGRPC(handler http.Handler) *Server {
+	s.grpcHandler = handler
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	s.httpServer.Protocols = protocols
+	return s
+}
+
+// WithGRPCHealth 由 Server 提供 gRPC 健康检查服务（grpc.health.v1.Health/Check），结果与 HTTP 就绪检查一致：
+// 开始排空或任意 ReadinessCheck 失败时为 NOT_SERVING，否则为 SERVING。
+// 只支持整体状态（service 为空），不支持 Watch；handler 自己注册了健康检查服务时不要开启
+func (s *Server) WithGRPCHealth() *Server {
+	s.grpcHealth = true
+	return s
+}
+
+// isGRPC 判断是否为 gRPC 请求
+func isGRPC(r *http.Request) bool {
+	return r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc")
+}
+
+// serveGRPC 处理 gRPC 请求并上报指标，健康检查不计入处理中的请求
+func (s *Server) serveGRPC(w http.ResponseWriter, r *http.Request) {
+	if s.grpcHealth && s.serveGRPCHealth(w, r) {
+		return
+	}
+	s.inflight.Add(1)
+	defer s.inflight.Add(-1)
+
+	fn := metricsFunc.Load()
+	if fn == nil {
+		s.grpcHandler.ServeHTTP(w, r)
+		return
+	}
+	start := time.Now()
+	sw := &grpcStatusWriter{ResponseWriter: w, status: http.StatusOK}
+	s.grpcHandler.ServeHTTP(sw, r)
+
+	code, err := grpcResult(w.Header(), sw.status)
+	(*fn)(Metrics{
+		Handler:  strings.TrimPrefix(r.URL.Path, "/"),
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Code:     code,
+		Status:   sw.status,
+		Duration: time.Since(start),
+		Err:      err,
+	})
+}
+
+// grpcResult 从响应的 trailer 中读取 gRPC 状态码和错误信息
+func grpcResult(h http.Header, status int) (int, error) {
+	raw := h.Get("Grpc-Status")
+	if raw == "" {
+		raw = h.Get(http.TrailerPrefix + "Grpc-Status")
+	}
+	code, err := strconv.Atoi(raw)
+	if err != nil {
+		if status == http.StatusOK {
+			return grpcUnknown, errors.New("missing grpc-status")
+		}
+		return grpcUnknown, errors.New(http.StatusText(status))
+	}
+	if code == grpcOK {
+		return code, nil
+	}
+	msg := h.Get("Grpc-Message")
+	if msg == "" {
+		msg = h.Get(http.TrailerPrefix + "Grpc-Message")
+	}
+	if unescaped, err := url.PathUnescape(msg); err == nil {
+		msg = unescaped
+	}
+	return code, errors.New(msg)
+}
+
+// grpcStatusWriter 记录 HTTP 状态码，gRPC 需要 Flush，不能丢失 http.Flusher
+type grpcStatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *grpcStatusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *grpcStatusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 使用
+func (w *grpcStatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// serveGRPCHealth 响应 gRPC 健康检查，返回 false 表示不是健康检查请求
+func (s *Server) serveGRPCHealth(w http.ResponseWriter, r *http.Request) bool {
+	switch r.URL.Path {
+	case grpcHealthCheck:
+	case grpcHealthWatch:
+		writeGRPCStatus(w, grpcUnimplemented, "watch is not supported")
+		return true
+	default:
+		return false
+	}
+
+	service, err := readHealthRequest(r.Body)
+	if err != nil {
+		writeGRPCStatus(w, grpcInvalidArg, err.Error())
+		return true
+	}
+	if service != "" {
+		writeGRPCStatus(w, grpcNotFound, "unknown service")
+		return true
+	}
+
+	status := uint64(grpcServing)
+	if s.readiness(r.Context()).Status != "ok" {
+		status = grpcNotServing
+	}
+	// HealthCheckResponse{status = 1}
+	msg := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), status)
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	frame = append(frame, msg...)
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(frame)
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(grpcOK))
+	return true
+}
+
+// readHealthRequest 读取 HealthCheckRequest 中的 service 字段
+func readHealthRequest(body io.Reader) (string, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(body, prefix[:]); err != nil {
+		return "", errors.New("invalid request frame")
+	}
+	if prefix[0] != 0 {
+		return "", errors.New("compressed request is not supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > 1024 {
+		return "", errors.New("request too large")
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(body, msg); err != nil {
+		return "", errors.New("invalid request frame")
+	}
+
+	var service string
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		if n < 0 {
+			return "", errors.New("invalid request message")
+		}
+		msg = msg[n:]
+		if num == 1 && typ == protowire.BytesType {
+			v, m := protowire.ConsumeString(msg)
+			if m < 0 {
+				return "", errors.New("invalid request message")
+			}
+			service, msg = v, msg[m:]
+			continue
+		}
+		m := protowire.ConsumeFieldValue(num, typ, msg)
+		if m < 0 {
+			return "", errors.New("invalid request message")
+		}
+		msg = msg[m:]
+	}
+	return service, nil
+}
+
+// writeGRPCStatus 输出只有状态的 gRPC 响应（Trailers-Only）
+func writeGRPCStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	w.Header().Set("Grpc-Message", url.PathEscape(msg))
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// readinessCheck 就绪检查
+type readinessCheck struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// healthResponse 健康检查响应
+type healthResponse struct {
+	Status string   `json:"status"`           // ok、draining 或 unavailable
+	Checks []string `json:"checks,omitempty"` // 失败的就绪检查名称，错误信息只写入日志
+}
+
+// readinessTimeout 单次就绪检查的超时时间
+const readinessTimeout = 2 * time.Second
+
+// WithHealthPaths 设置存活检查和就绪检查的路径，如 /livez、/readyz
+// 由 Server 直接响应，不经过 engine 的中间件（鉴权、限流、访问日志等），也不计入处理中的请求
+// 开始排空后就绪检查返回 503，存活检查始终返回 200
+func (s *Server) WithHealthPaths(live, ready string) *Server {
+	s.livePath, s.readyPath = live, ready
+	return s
+}
+
+// ReadinessCheck 添加就绪检查，任意一个检查失败时就绪检查返回 503
+// 用于依赖（数据库、Redis 等）不可用时让负载均衡暂时不再转发请求
+func (s *Server) ReadinessCheck(name string, fn func(ctx context.Context) error) *Server {
+	s.checks = append(s.checks, readinessCheck{name: name, fn: fn})
+	return s
+}
+
+// WithDrainGrace 设置排空时间：开始排空后就绪检查返回 503，但继续正常处理请求，等待负载均衡摘除实例后再关闭
+// 收到 SIGTERM 时如果还没有开始排空，先排空再关闭；默认为 0，收到信号后立即关闭
+func (s *Server) WithDrainGrace(grace time.Duration) *Server {
+	s.drainGrace = grace
+	return s
+}
+
+// WithDrainPath 设置触发排空的管理接口，用于 Kubernetes 的 preStop 钩子
+// 请求需要在 X-Drain-Token 请求头中携带 token，接口在排空时间结束后才返回，kubelet 随后发送 SIGTERM 时直接关闭
+func (s *Server) WithDrainPath(path, token string) *Server {
+	s.drainPath, s.drainToken = path, token
+	return s
+}
+
+// Drain 开始排空并等待排空时间结束，ctx 结束时提前返回
+// 多次调用只在第一次开始排空，之后的调用等待剩余的排空时间
+func (s *Server) Drain(ctx context.Context) {
+	now := time.Now()
+	if s.drainStart.CompareAndSwap(nil, &now) {
+		slog.Info("开始排空，就绪检查将返回失败", slog.Duration("grace", s.drainGrace))
+	}
+	remaining := s.drainGrace - time.Since(*s.drainStart.Load())
+	if remaining <= 0 {
+		return
+	}
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Draining 判断是否已经开始排空
+func (s *Server) Draining() bool {
+	return s.drainStart.Load() != nil
+}
+
+// serveHealth 响应健康检查和排空请求，返回 false 表示不是这些路径
+func (s *Server) serveHealth(w http.ResponseWriter, r *http.Request) bool {
+	switch path := r.URL.Path; {
+	case path == "":
+		return false
+	case path == s.livePath:
+		writeHealth(w, http.StatusOK, healthResponse{Status: "ok"})
+	case path == s.readyPath:
+		s.serveReadiness(w, r)
+	case path == s.drainPath:
+		if s.drainToken == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Drain-Token")), []byte(s.drainToken)) != 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return true
+		}
+		s.Drain(r.Context())
+		writeHealth(w, http.StatusOK, healthResponse{Status: "draining"})
+	default:
+		return false
+	}
+	return true
+}
+
+// serveReadiness 响应就绪检查
+func (s *Server) serveReadiness(w http.ResponseWriter, r *http.Request) {
+	resp := s.readiness(r.Context())
+	if resp.Status != "ok" {
+		writeHealth(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+	writeHealth(w, http.StatusOK, resp)
+}
+
+// readiness 执行就绪检查，HTTP 和 gRPC 健康检查共用
+func (s *Server) readiness(ctx context.Context) healthResponse {
+	if s.Draining() {
+		return healthResponse{Status: "draining"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	// 健康检查接口不经过鉴权，错误信息可能包含地址、DSN 等，只记录到日志，响应中只返回检查名称
+	var failed []string
+	for _, check := range s.checks {
+		if err := check.fn(ctx); err != nil {
+			slog.Warn("就绪检查失败", slog.String("check", check.name), slog.Any("err", err))
+			failed = append(failed, check.name)
+		}
+	}
+	if failed != nil {
+		return healthResponse{Status: "unavailable", Checks: failed}
+	}
+	return healthResponse{Status: "ok"}
+}
+
+// writeHealth 输出健康检查响应
+func writeHealth(w http.ResponseWriter, status int, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
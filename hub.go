@@ -0,0 +1,350 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+)
+
+// defaultHubBuffer 每个订阅者默认的事件缓冲大小
+const defaultHubBuffer = 16
+
+// HubBroker 事件中心的消息代理，负责在实例之间传递事件
+// 单实例部署使用内存实现，多实例部署使用 Redis 等实现（见 hub/redis）
+type HubBroker interface {
+	// Publish 向主题发布消息
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe 订阅主题，收到消息时调用 deliver，返回取消订阅的函数
+	// deliver 可能在代理的协程中调用，不应阻塞
+	Subscribe(topic string, deliver func(payload []byte)) (unsubscribe func(), err error)
+}
+
+// hubMessage 在代理之间传递的事件
+type hubMessage struct {
+	ID     string `json:"id,omitempty"`
+	Event  string `json:"event,omitempty"`
+	Data   string `json:"data"`
+	Retry  int64  `json:"retry,omitempty"`  // 毫秒
+	Origin string `json:"origin,omitempty"` // 发布事件的请求的追踪 ID
+}
+
+// Hub SSE 事件中心
+// 按主题将事件推送给所有连接的客户端；配合 Redis 代理时，任意实例发布的事件都能送达连接在其他实例上的客户端
+type Hub struct {
+	broker HubBroker
+	local  bool // 代理是否为内存实现
+	buffer int
+	filter HubFilter
+	logged bool // 是否记录每个连接的投递结果
+
+	mu     sync.RWMutex
+	topics map[string]*hubTopic
+}
+
+// HubFilter 投递前的事件过滤函数，返回 false 时不推送给该用户
+// userId 为连接的用户 ID（gctx.Context.UserId），未登录时为空字符串
+type HubFilter func(userId string, ev Event) bool
+
+// hubTopic 主题在本实例上的订阅者
+type hubTopic struct {
+	subs        map[chan Event]hubClient
+	unsubscribe func()
+}
+
+// hubClient 订阅者对应的连接，用于投递日志
+type hubClient struct {
+	userId  string
+	traceID string // SSE 连接请求的追踪 ID
+}
+
+// 投递结果，见 WithDeliveryLog
+const (
+	hubDelivered = "delivered" // 已写入连接
+	hubFiltered  = "filtered"  // 被过滤函数屏蔽
+	hubDropped   = "dropped"   // 订阅者缓冲已满，丢弃
+	hubFailed    = "failed"    // 写入失败，连接已断开
+)
+
+// NewHub 创建 SSE 事件中心，broker 为 nil 时使用内存代理（仅本实例内投递）
+func NewHub(broker HubBroker) *Hub {
+	_, local := broker.(*MemoryHubBroker)
+	if broker == nil {
+		broker, local = NewMemoryHubBroker(), true
+	}
+	return &Hub{
+		broker: broker,
+		local:  local,
+		buffer: defaultHubBuffer,
+		topics: make(map[string]*hubTopic),
+	}
+}
+
+// WithBuffer 设置每个订阅者的事件缓冲大小，缓冲已满时丢弃新事件，避免慢客户端拖慢其他客户端
+func (h *Hub) WithBuffer(size int) *Hub {
+	h.buffer = size
+	return h
+}
+
+// WithFilter 设置投递前的事件过滤函数，用于按用户的通知偏好屏蔽某类事件，发布方不需要各自判断
+// 过滤函数在每个连接自己的协程中执行，可以查询缓存等，但耗时会延迟该连接后续事件的推送
+//
+// 示例:
+//
+//	hub.WithFilter(func(userId string, ev gint.Event) bool {
+//	   return !prefs.Muted(userId, ev.Event)
+//	})
+func (h *Hub) WithFilter(fn HubFilter) *Hub {
+	h.filter = fn
+	return h
+}
+
+// WithDeliveryLog 开启投递日志，以 Info 级别记录每个事件在每个连接上的投递结果
+// 日志包含主题、事件 ID、事件类型、发布事件的请求的追踪 ID（origin）、连接的用户 ID 和连接请求的追踪 ID，
+// 结果为 delivered（已推送）、filtered（被过滤）、dropped（缓冲已满丢弃）或 failed（连接已断开），
+// 可以从日志中查到某个用户是否收到过某条通知
+func (h *Hub) WithDeliveryLog() *Hub {
+	h.logged = true
+	return h
+}
+
+// Publish 向主题发布事件
+// ctx 为处理函数的 *gctx.Context 或 *gin.Context 时，事件的 Origin 为空则填充为当前请求的追踪 ID
+// 代理发布失败时仍会投递给本实例的订阅者，并返回错误
+func (h *Hub) Publish(ctx context.Context, topic string, ev Event) error {
+	data, err := eventPayload(ev.Data)
+	if err != nil {
+		return err
+	}
+	if ev.Origin == "" {
+		ev.Origin = originTraceID(ctx)
+	}
+	payload, err := json.Marshal(hubMessage{
+		ID:     ev.ID,
+		Event:  ev.Event,
+		Data:   data,
+		Retry:  ev.Retry.Milliseconds(),
+		Origin: ev.Origin,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化事件失败: %w", err)
+	}
+
+	if err := h.broker.Publish(ctx, topic, payload); err != nil {
+		if !h.local {
+			h.deliver(topic, payload)
+		}
+		return fmt.Errorf("发布事件失败: %w", err)
+	}
+	return nil
+}
+
+// originTraceID 从发布事件的 ctx 中获取请求的追踪 ID
+func originTraceID(ctx context.Context) string {
+	switch c := ctx.(type) {
+	case *gctx.Context:
+		return traceID(c.Context)
+	case *gin.Context:
+		return traceID(c)
+	}
+	return ""
+}
+
+// Subscribe 订阅主题，返回事件通道和取消订阅的函数
+// 取消订阅后事件通道会被关闭
+func (h *Hub) Subscribe(topic string) (<-chan Event, func(), error) {
+	return h.subscribe(topic, hubClient{})
+}
+
+// subscribe 订阅主题，client 为订阅者对应的连接
+func (h *Hub) subscribe(topic string, client hubClient) (<-chan Event, func(), error) {
+	ch := make(chan Event, h.buffer)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[topic]
+	if !ok {
+		unsubscribe, err := h.broker.Subscribe(topic, func(payload []byte) {
+			h.deliver(topic, payload)
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("订阅主题失败: %w", err)
+		}
+		t = &hubTopic{subs: make(map[chan Event]hubClient), unsubscribe: unsubscribe}
+		h.topics[topic] = t
+	}
+	t.subs[ch] = client
+
+	cancel := sync.OnceFunc(func() {
+		h.mu.Lock()
+		delete(t.subs, ch)
+		if len(t.subs) == 0 {
+			delete(h.topics, topic)
+			t.unsubscribe()
+		}
+		close(ch)
+		h.mu.Unlock()
+	})
+	return ch, cancel, nil
+}
+
+// Handler 创建订阅主题的 SSE 处理函数，topicFunc 根据请求确定主题（如按用户 ID）
+//
+// 示例:
+//
+//	hub := gint.NewHub(redis.NewBroker(client))
+//	router.GET("/notifications", hub.Handler(func(ctx *gctx.Context) (string, error) {
+//	   return "user:" + ctx.UserId(), nil
+//	}))
+//
+//	// 任意实例上发布
+//	hub.Publish(ctx, "user:"+userId, gint.Event{Event: "notice", Data: notice})
+func (h *Hub) Handler(topicFunc func(ctx *gctx.Context) (string, error), opts ...Option) gin.HandlerFunc {
+	return Stream(func(ctx *gctx.Context, send SendFunc) error {
+		topic, err := topicFunc(ctx)
+		if err != nil {
+			return err
+		}
+		client := hubClient{userId: ctx.UserId(), traceID: traceID(ctx.Context)}
+		events, cancel, err := h.subscribe(topic, client)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		reqCtx := ctx.Request.Context()
+		for {
+			select {
+			case ev := <-events:
+				if h.filter != nil && !h.filter(client.userId, ev) {
+					h.logDelivery(topic, client, ev, hubFiltered)
+					continue
+				}
+				if err := send(ev); err != nil {
+					h.logDelivery(topic, client, ev, hubFailed)
+					return err
+				}
+				h.logDelivery(topic, client, ev, hubDelivered)
+			case <-reqCtx.Done():
+				return reqCtx.Err()
+			}
+		}
+	}, opts...)
+}
+
+// deliver 将代理收到的消息投递给本实例的订阅者
+func (h *Hub) deliver(topic string, payload []byte) {
+	var msg hubMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		slog.Warn("解析事件失败", slog.String("topic", topic), slog.Any("err", err))
+		return
+	}
+	ev := Event{
+		ID:     msg.ID,
+		Event:  msg.Event,
+		Data:   msg.Data,
+		Retry:  time.Duration(msg.Retry) * time.Millisecond,
+		Origin: msg.Origin,
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	t, ok := h.topics[topic]
+	if !ok {
+		return
+	}
+	for ch, client := range t.subs {
+		select {
+		case ch <- ev:
+		default:
+			slog.Debug("订阅者缓冲已满，丢弃事件", slog.String("topic", topic))
+			h.logDelivery(topic, client, ev, hubDropped)
+		}
+	}
+}
+
+// logDelivery 记录事件在连接上的投递结果，未开启投递日志时不记录
+func (h *Hub) logDelivery(topic string, client hubClient, ev Event, outcome string) {
+	if !h.logged {
+		return
+	}
+	slog.Info("SSE 事件投递",
+		slog.String("topic", topic),
+		slog.String("event_id", ev.ID),
+		slog.String("event", ev.Event),
+		slog.String("origin", ev.Origin),
+		slog.String("user_id", client.userId),
+		slog.String("trace_id", client.traceID),
+		slog.String("outcome", outcome))
+}
+
+// MemoryHubBroker 内存消息代理，只在本实例内投递，适用于单实例部署
+type MemoryHubBroker struct {
+	mu     sync.RWMutex
+	nextID uint64
+	subs   map[string]map[uint64]func(payload []byte)
+}
+
+// NewMemoryHubBroker 创建内存消息代理
+func NewMemoryHubBroker() *MemoryHubBroker {
+	return &MemoryHubBroker{subs: make(map[string]map[uint64]func(payload []byte))}
+}
+
+// Publish 向主题发布消息，同步投递给订阅者
+func (b *MemoryHubBroker) Publish(ctx context.Context, topic string, payload []byte) error {
+	b.mu.RLock()
+	delivers := make([]func(payload []byte), 0, len(b.subs[topic]))
+	for _, deliver := range b.subs[topic] {
+		delivers = append(delivers, deliver)
+	}
+	b.mu.RUnlock()
+
+	// 在锁外投递，避免与取消订阅互相等待
+	for _, deliver := range delivers {
+		deliver(payload)
+	}
+	return nil
+}
+
+// Subscribe 订阅主题
+func (b *MemoryHubBroker) Subscribe(topic string, deliver func(payload []byte)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	if b.subs[topic] == nil {
+		b.subs[topic] = make(map[uint64]func(payload []byte))
+	}
+	b.subs[topic][id] = deliver
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs[topic], id)
+		if len(b.subs[topic]) == 0 {
+			delete(b.subs, topic)
+		}
+	}, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ink-code/gint"
+	"github.com/ink-code/gint/internal/supervisor"
+)
+
+var _ gint.HubBroker = (*Broker)(nil)
+
+// Broker 基于 Redis Pub/Sub 的事件中心消息代理，适用于多实例部署
+// 所有主题共用一个 Pub/Sub 连接，本实例没有订阅者的主题不会订阅对应频道
+// 注意：Pub/Sub 不持久化消息，客户端断线期间的事件不会补发
+type Broker struct {
+	client redis.UniversalClient
+	prefix string
+
+	mu       sync.Mutex
+	nextID   uint64
+	subs     map[string]map[uint64]func(payload []byte)
+	pubsub   *redis.PubSub
+	receiver *supervisor.Supervisor
+}
+
+// NewBroker 创建 Redis 消息代理，频道的默认前缀为 gint:hub:
+func NewBroker(client redis.UniversalClient) *Broker {
+	return &Broker{
+		client: client,
+		prefix: "gint:hub:",
+		subs:   make(map[string]map[uint64]func(payload []byte)),
+	}
+}
+
+// WithPrefix 设置频道前缀
+func (b *Broker) WithPrefix(prefix string) *Broker {
+	b.prefix = prefix
+	return b
+}
+
+// Publish 向主题发布消息
+func (b *Broker) Publish(ctx context.Context, topic string, payload []byte) error {
+	if err := b.client.Publish(ctx, b.prefix+topic, payload).Err(); err != nil {
+		return fmt.Errorf("发布消息失败: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 订阅主题，首个订阅时建立 Pub/Sub 连接并启动接收协程
+func (b *Broker) Subscribe(topic string, deliver func(payload []byte)) (func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	channel := b.prefix + topic
+	if len(b.subs[topic]) == 0 {
+		ctx := context.Background()
+		if b.pubsub == nil {
+			b.pubsub = b.client.Subscribe(ctx, channel)
+			// 确认订阅成功，连接失败时及时返回错误
+			if _, err := b.pubsub.Receive(ctx); err != nil {
+				b.pubsub.Close()
+				b.pubsub = nil
+				return nil, fmt.Errorf("订阅频道失败: %w", err)
+			}
+			b.receiver = supervisor.Go("redis-hub-receiver", b.receive(b.pubsub))
+		} else if err := b.pubsub.Subscribe(ctx, channel); err != nil {
+			return nil, fmt.Errorf("订阅频道失败: %w", err)
+		}
+		b.subs[topic] = make(map[uint64]func(payload []byte))
+	}
+
+	b.nextID++
+	id := b.nextID
+	b.subs[topic][id] = deliver
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs[topic], id)
+		if len(b.subs[topic]) == 0 {
+			delete(b.subs, topic)
+			if b.pubsub != nil {
+				_ = b.pubsub.Unsubscribe(context.Background(), channel)
+			}
+		}
+	}, nil
+}
+
+// Close 关闭 Pub/Sub 连接并停止接收协程
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	pubsub, receiver := b.pubsub, b.receiver
+	b.pubsub, b.receiver = nil, nil
+	b.subs = make(map[string]map[uint64]func(payload []byte))
+	b.mu.Unlock()
+
+	if pubsub == nil {
+		return nil
+	}
+	err := pubsub.Close()
+	receiver.Close()
+	return err
+}
+
+// receive 接收 Pub/Sub 消息并分发给订阅者
+func (b *Broker) receive(pubsub *redis.PubSub) func(stop <-chan struct{}) {
+	return func(stop <-chan struct{}) {
+		ch := pubsub.Channel()
+		for {
+			select {
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				b.dispatch(strings.TrimPrefix(msg.Channel, b.prefix), []byte(msg.Payload))
+			case <-stop:
+				return
+			}
+		}
+	}
+}
+
+// dispatch 在锁外调用订阅者，避免与取消订阅互相等待
+func (b *Broker) dispatch(topic string, payload []byte) {
+	b.mu.Lock()
+	delivers := make([]func(payload []byte), 0, len(b.subs[topic]))
+	for _, deliver := range b.subs[topic] {
+		delivers = append(delivers, deliver)
+	}
+	b.mu.Unlock()
+
+	for _, deliver := range delivers {
+		deliver(payload)
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/internal/supervisor"
+	"github.com/ink-code/gint/session"
+)
+
+// IdempotencyHeader 幂等键请求头
+const IdempotencyHeader = "Idempotency-Key"
+
+// idempotencyLockTTL 处理中的占用记录的有效期
+// 处理期间定期续期，进程崩溃或处理函数 panic 时占用记录很快过期，客户端可以重试，而不是在整个 ttl 内都收到 409
+const idempotencyLockTTL = 30 * time.Second
+
+// IdempotencyRecord 幂等记录
+type IdempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"` // 请求指纹，未开启指纹校验时为空
+	Done        bool   `json:"done"`        // 首次请求是否已处理完成
+	Result      Result `json:"result"`      // 首次请求的处理结果
+}
+
+// IdempotencyStore 幂等记录存储
+type IdempotencyStore interface {
+	// Reserve 尝试占用幂等键，ttl 为处理中的占用记录的有效期
+	// 占用成功返回 (nil, true)；键已存在时返回已有记录和 false
+	Reserve(ctx context.Context, key string, rec *IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, bool, error)
+
+	// Save 保存记录，覆盖已有的记录；处理期间也用于续期占用记录（Done 为 false）
+	Save(ctx context.Context, key string, rec *IdempotencyRecord, ttl time.Duration) error
+
+	// Release 释放幂等键，处理失败时调用，允许客户端使用同一个键重试
+	Release(ctx context.Context, key string) error
+}
+
+// idempotencyConfig 幂等配置
+type idempotencyConfig struct {
+	store       IdempotencyStore
+	ttl         time.Duration
+	fingerprint bool
+}
+
+// WithIdempotency 开启幂等支持
+// 请求携带 Idempotency-Key 头时，同一个键的重复请求直接返回首次处理的结果（响应头 Idempotent-Replayed: true），
+// 首次请求仍在处理中时返回 409；业务逻辑返回 error 时不保存结果，允许客户端重试
+// 幂等键按 HTTP 方法、路由和当前用户（session.UserId）隔离；无法确定当前用户时不启用幂等保护，避免不同用户读到彼此的结果
+// ttl 为处理完成的结果的保存时间，必须大于 0；处理中的占用记录只保存 30 秒并在处理期间续期
+//
+// 示例:
+//
+//	store := gint.NewMemoryIdempotencyStore()
+//	router.POST("/orders", gint.BS(createOrder, gint.WithIdempotency(store, 24*time.Hour)))
+func WithIdempotency(store IdempotencyStore, ttl time.Duration) Option {
+	if ttl <= 0 {
+		panic("gint: WithIdempotency 的 ttl 必须大于 0")
+	}
+	return func(o *options) {
+		if o.idempotency == nil {
+			o.idempotency = &idempotencyConfig{}
+		}
+		o.idempotency.store = store
+		o.idempotency.ttl = ttl
+	}
+}
+
+// WithRequestFingerprint 开启请求指纹校验，需要与 WithIdempotency 一起使用
+// 对请求方法、路径、查询参数和请求体计算指纹并与幂等记录一起保存，
+// 同一个幂等键携带不同的请求内容重试时返回 409（支付网关类接口的常见要求）
+// 请求体只有前 1MB 参与计算
+func WithRequestFingerprint() Option {
+	return func(o *options) {
+		if o.idempotency == nil {
+			o.idempotency = &idempotencyConfig{}
+		}
+		o.idempotency.fingerprint = true
+	}
+}
+
+// handleIdempotent 带幂等保护的执行流程
+// replayed 在重放首次处理的结果时调用，用于上报重放结果的业务码
+func handleIdempotent(c *gin.Context, o *options, call func() (Result, error), replayed func(Result), attrs ...any) {
+	cfg := o.idempotency
+	key := c.GetHeader(IdempotencyHeader)
+	userId := session.UserId(&gctx.Context{Context: c})
+	if key == "" || cfg.store == nil || userId == "" {
+		if key != "" && cfg.store != nil {
+			slog.Debug("无法确定当前用户，不启用幂等保护", append([]any{
+				slog.String("path", c.Request.URL.Path)}, attrs...)...)
+		}
+		res, err := call()
+		render(c, o, res, err, attrs...)
+		return
+	}
+	key = idempotencyScope(c, userId) + key
+
+	var fingerprint string
+	if cfg.fingerprint {
+		var err error
+		if fingerprint, err = requestFingerprint(c.Request); err != nil {
+			render(c, o, Result{Code: 400}, err, attrs...)
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	lockTTL := min(idempotencyLockTTL, cfg.ttl)
+	existing, reserved, err := cfg.store.Reserve(ctx, key, &IdempotencyRecord{Fingerprint: fingerprint}, lockTTL)
+	if err != nil {
+		slog.Error("占用幂等键失败", append([]any{
+			slog.String("path", c.Request.URL.Path),
+			slog.Any("err", err)}, attrs...)...)
+		render(c, o, Result{Code: CodeError}, err, attrs...)
+		return
+	}
+
+	if !reserved {
+		switch {
+		case cfg.fingerprint && existing.Fingerprint != fingerprint:
+			rejectIdempotent(c, "幂等键已被内容不同的请求使用")
+		case !existing.Done:
+			rejectIdempotent(c, "请求正在处理中，请稍后重试")
+		default:
+			c.Header("Idempotent-Replayed", "true")
+			replayed(existing.Result)
+			render(c, o, existing.Result, nil, attrs...)
+		}
+		return
+	}
+
+	// 处理期间续期占用记录；处理函数 panic 时释放幂等键，允许客户端重试
+	stopRenew := renewReservation(ctx, cfg.store, key, &IdempotencyRecord{Fingerprint: fingerprint}, lockTTL, attrs)
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		stopRenew()
+		if releaseErr := cfg.store.Release(context.WithoutCancel(ctx), key); releaseErr != nil {
+			slog.Error("释放幂等键失败", append([]any{
+				slog.String("key", key),
+				slog.Any("err", releaseErr)}, attrs...)...)
+		}
+	}()
+	res, err := call()
+	completed = true
+	stopRenew()
+	if err != nil || !replayable(res) {
+		if releaseErr := cfg.store.Release(ctx, key); releaseErr != nil {
+			slog.Error("释放幂等键失败", append([]any{
+				slog.String("key", key),
+				slog.Any("err", releaseErr)}, attrs...)...)
+		}
+	} else if saveErr := cfg.store.Save(ctx, key, &IdempotencyRecord{
+		Fingerprint: fingerprint,
+		Done:        true,
+		Result:      res,
+	}, cfg.ttl); saveErr != nil {
+		slog.Error("保存幂等记录失败", append([]any{
+			slog.String("key", key),
+			slog.Any("err", saveErr)}, attrs...)...)
+	}
+
+	render(c, o, res, err, attrs...)
+}
+
+// renewReservation 每隔 ttl/3 续期一次处理中的占用记录，返回的函数停止续期并等待续期协程退出
+func renewReservation(ctx context.Context, store IdempotencyStore, key string, rec *IdempotencyRecord, ttl time.Duration, attrs []any) (stop func()) {
+	ctx = context.WithoutCancel(ctx)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(max(ttl/3, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := store.Save(ctx, key, rec, ttl); err != nil {
+					slog.Warn("续期幂等键失败", append([]any{
+						slog.String("key", key),
+						slog.Any("err", err)}, attrs...)...)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+		})
+	}
+}
+
+// rejectIdempotent 以 409 拒绝幂等键冲突的请求
+func rejectIdempotent(c *gin.Context, msg string) {
+	recordError(c, errors.New(msg), http.StatusConflict, http.StatusConflict, true)
+	writeError(c, http.StatusConflict, Result{Code: http.StatusConflict, Msg: msg, TraceID: traceID(c)})
+}
+
+// idempotencyScope 幂等键的作用域，避免不同接口或不同用户的键互相冲突
+func idempotencyScope(c *gin.Context, userId string) string {
+	return c.Request.Method + " " + c.FullPath() + ":" + userId + ":"
+}
+
+// maxFingerprintBody 参与指纹计算的最大请求体长度
+const maxFingerprintBody = 1 << 20
+
+// requestFingerprint 计算请求指纹，读取后恢复请求体
+// 可以定位的请求体（如 spool 转存的）流式计算完整请求体的哈希；
+// 其他请求体只读取前 maxFingerprintBody 字节，未读取的部分保留在请求体中
+func requestFingerprint(req *http.Request) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, req.Method+"\n"+req.URL.Path+"\n"+req.URL.RawQuery+"\n")
+	if rs, ok := req.Body.(io.ReadSeeker); ok {
+		if err := rewind(rs, func(r io.Reader) error {
+			_, err := io.Copy(h, r)
+			return err
+		}); err != nil {
+			return "", err
+		}
+	} else if req.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxFingerprintBody))
+		if err != nil {
+			return "", err
+		}
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		h.Write(body)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// replayable 判断结果是否可以被重放（流式文件内容只能读取一次）
+func replayable(res Result) bool {
+	switch data := res.Data.(type) {
+	case FileResult:
+		return data.Reader == nil
+	case *FileResult:
+		return data == nil || data.Reader == nil
+	}
+	return true
+}
+
+// MemoryIdempotencyStore 内存幂等记录存储
+// 注意：仅适用于单实例部署，多实例部署请使用共享存储
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryIdempotencyEntry
+	cleaner *supervisor.Supervisor
+}
+
+type memoryIdempotencyEntry struct {
+	record   IdempotencyRecord
+	expireAt time.Time
+}
+
+// NewMemoryIdempotencyStore 创建内存幂等记录存储
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	s := &MemoryIdempotencyStore{
+		records: make(map[string]*memoryIdempotencyEntry),
+	}
+	s.cleaner = supervisor.Go("memory-idempotency-cleaner", s.cleanupLoop)
+	return s
+}
+
+// Reserve 尝试占用幂等键
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key string, rec *IdempotencyRecord, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.records[key]; ok && now.Before(entry.expireAt) {
+		existing := entry.record
+		return &existing, false, nil
+	}
+
+	s.records[key] = &memoryIdempotencyEntry{record: *rec, expireAt: now.Add(ttl)}
+	return nil, true, nil
+}
+
+// Save 保存处理完成的记录
+func (s *MemoryIdempotencyStore) Save(ctx context.Context, key string, rec *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	s.records[key] = &memoryIdempotencyEntry{record: *rec, expireAt: time.Now().Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+// Release 释放幂等键
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.records, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// Close 停止后台清理协程
+func (s *MemoryIdempotencyStore) Close() error {
+	return s.cleaner.Close()
+}
+
+// cleanupLoop 定期清理过期记录
+func (s *MemoryIdempotencyStore) cleanupLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		now := time.Now()
+		s.mu.Lock()
+		for key, entry := range s.records {
+			if now.After(entry.expireAt) {
+				delete(s.records, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
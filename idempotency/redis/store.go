@@ -0,0 +1,101 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ink-code/gint"
+)
+
+var _ gint.IdempotencyStore = (*Store)(nil)
+
+// reserveScript 原子地占用幂等键，键已存在时返回已有记录
+var reserveScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return nil
+end
+return redis.call('GET', KEYS[1])
+`)
+
+// Store 基于 Redis 的幂等记录存储，适用于多实例部署
+// 注意：记录以 JSON 保存，重放时 Result.Data 为反序列化后的通用结构（map/slice 等），
+// Raw、Redirect 等特殊响应类型不会被还原，这类接口不建议开启幂等
+type Store struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewStore 创建 Redis 幂等记录存储，键的默认前缀为 gint:idempotency:
+func NewStore(client redis.Cmdable) *Store {
+	return &Store{
+		client: client,
+		prefix: "gint:idempotency:",
+	}
+}
+
+// WithPrefix 设置键前缀
+func (s *Store) WithPrefix(prefix string) *Store {
+	s.prefix = prefix
+	return s
+}
+
+// Reserve 尝试占用幂等键
+func (s *Store) Reserve(ctx context.Context, key string, rec *gint.IdempotencyRecord, ttl time.Duration) (*gint.IdempotencyRecord, bool, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, false, fmt.Errorf("序列化幂等记录失败: %w", err)
+	}
+
+	val, err := reserveScript.Run(ctx, s.client, []string{s.prefix + key}, data, max(ttl.Milliseconds(), 1)).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("占用幂等键失败: %w", err)
+	}
+
+	var existing gint.IdempotencyRecord
+	if err := json.Unmarshal([]byte(val), &existing); err != nil {
+		return nil, false, fmt.Errorf("解析幂等记录失败: %w", err)
+	}
+	return &existing, false, nil
+}
+
+// Save 保存处理完成的记录
+func (s *Store) Save(ctx context.Context, key string, rec *gint.IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("序列化幂等记录失败: %w", err)
+	}
+	if err := s.client.Set(ctx, s.prefix+key, data, ttl).Err(); err != nil {
+		return fmt.Errorf("保存幂等记录失败: %w", err)
+	}
+	return nil
+}
+
+// Release 释放幂等键
+func (s *Store) Release(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil {
+		return fmt.Errorf("释放幂等键失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clientip 解析请求的客户端 IP
+// 默认只使用 TCP 连接的对端地址；只有对端是配置的可信代理时才读取 X-Forwarded-For，避免客户端伪造请求头绕过按 IP 的限制
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver 客户端 IP 解析器
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// New 创建客户端 IP 解析器，trusted 为可信代理的 IP 或网段，为空时只使用连接的对端地址
+func New(trusted []string) (*Resolver, error) {
+	prefixes, err := ParsePrefixes(trusted)
+	if err != nil {
+		return nil, err
+	}
+	return &Resolver{trusted: prefixes}, nil
+}
+
+// IP 返回请求的客户端 IP，无法解析时返回零值
+// 对端是可信代理时，从右向左查找 X-Forwarded-For 中第一个不是可信代理的地址
+func (r *Resolver) IP(req *http.Request) netip.Addr {
+	addr := Remote(req)
+	if !addr.IsValid() || r == nil || !r.isTrusted(addr) {
+		return addr
+	}
+	forwarded := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !r.isTrusted(addr) {
+			break
+		}
+	}
+	return addr
+}
+
+// isTrusted 判断地址是否为可信代理
+func (r *Resolver) isTrusted(addr netip.Addr) bool {
+	for _, p := range r.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remote 返回 TCP 连接的对端地址，无法解析时返回零值
+func Remote(req *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(req.RemoteAddr))
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// ParsePrefixes 解析 IP 或网段，单个 IP 视为 /32 或 /128 的网段
+func ParsePrefixes(items []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if strings.Contains(item, "/") {
+			p, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("网段 %q 格式不正确", item)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("IP %q 格式不正确", item)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
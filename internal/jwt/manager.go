@@ -15,7 +15,9 @@
 package jwt
 
 import (
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -27,6 +29,10 @@ var _ Manager = (*manager)(nil)
 // manager JWT 管理器实现
 type manager struct {
 	opts Options
+
+	legacy      []LegacyKey       // 只用于验签的旧密钥，按顺序尝试
+	legacyUse   func(name string) // 使用旧密钥验签成功时的回调
+	legacyCount []atomic.Uint64   // 各旧密钥验签成功的次数，与 legacy 一一对应
 }
 
 // NewManager 创建 JWT 管理器
@@ -38,19 +44,19 @@ func NewManager(opts Options) Manager {
 
 // GenerateToken 生成 Access Token（兼容旧版本）
 func (m *manager) GenerateToken(claims Claims) (string, error) {
-	return m.generateToken(claims, m.opts.AccessExpire)
+	return m.generateToken(claims, m.opts.AccessExpire, TokenTypeAccess)
 }
 
 // GenerateTokenPair 生成 Token 对（Access Token + Refresh Token）
 func (m *manager) GenerateTokenPair(claims Claims) (*TokenPair, error) {
 	// 生成 Access Token
-	accessToken, err := m.generateToken(claims, m.opts.AccessExpire)
+	accessToken, err := m.generateToken(claims, m.opts.AccessExpire, TokenTypeAccess)
 	if err != nil {
 		return nil, fmt.Errorf("生成 Access Token 失败: %w", err)
 	}
 
 	// 生成 Refresh Token
-	refreshToken, err := m.generateToken(claims, m.opts.RefreshExpire)
+	refreshToken, err := m.generateToken(claims, m.opts.RefreshExpire, TokenTypeRefresh)
 	if err != nil {
 		return nil, fmt.Errorf("生成 Refresh Token 失败: %w", err)
 	}
@@ -62,8 +68,9 @@ func (m *manager) GenerateTokenPair(claims Claims) (*TokenPair, error) {
 }
 
 // generateToken 生成 Token 的内部方法
-func (m *manager) generateToken(claims Claims, expire time.Duration) (string, error) {
+func (m *manager) generateToken(claims Claims, expire time.Duration, tokenType string) (string, error) {
 	now := time.Now()
+	claims.TokenType = tokenType
 
 	// 设置标准声明
 	claims.RegisteredClaims = jwt.RegisteredClaims{
@@ -83,24 +90,96 @@ func (m *manager) generateToken(claims Claims, expire time.Duration) (string, er
 
 // VerifyToken 验证 Access Token
 func (m *manager) VerifyToken(tokenString string) (*Claims, error) {
-	return m.verifyToken(tokenString)
+	return m.verifyToken(tokenString, TokenTypeAccess)
 }
 
 // VerifyRefreshToken 验证 Refresh Token
 func (m *manager) VerifyRefreshToken(tokenString string) (*Claims, error) {
-	return m.verifyToken(tokenString)
+	return m.verifyToken(tokenString, TokenTypeRefresh)
+}
+
+// tokenType 返回 Token 的类型
+// 旧版本签发的 Token 没有类型声明，按有效期判断：超过 Access Token 有效期的为 Refresh Token
+func (m *manager) tokenType(claims *Claims) string {
+	if claims.TokenType != "" {
+		return claims.TokenType
+	}
+	if claims.ExpiresAt != nil && claims.IssuedAt != nil &&
+		claims.ExpiresAt.Sub(claims.IssuedAt.Time) > m.opts.AccessExpire {
+		return TokenTypeRefresh
+	}
+	return TokenTypeAccess
+}
+
+// WithLegacyKeys 返回同时接受旧密钥签发的 Token 的管理器
+func (m *manager) WithLegacyKeys(onUse func(name string), keys ...LegacyKey) Manager {
+	legacy := make([]LegacyKey, len(keys))
+	for i, key := range keys {
+		if key.Method == nil {
+			key.Method = jwt.SigningMethodHS256
+		}
+		if s, ok := key.Key.(string); ok {
+			key.Key = []byte(s)
+		}
+		legacy[i] = key
+	}
+	return &manager{
+		opts:        m.opts,
+		legacy:      legacy,
+		legacyUse:   onUse,
+		legacyCount: make([]atomic.Uint64, len(legacy)),
+	}
+}
+
+// LegacyUsage 返回各旧密钥验签成功的次数
+func (m *manager) LegacyUsage() map[string]uint64 {
+	usage := make(map[string]uint64, len(m.legacy))
+	for i, key := range m.legacy {
+		usage[key.Name] = m.legacyCount[i].Load()
+	}
+	return usage
 }
 
-// verifyToken 验证 Token 的内部方法
-func (m *manager) verifyToken(tokenString string) (*Claims, error) {
-	// 解析 Token
+// errSignature 签名方法不符或签名无效，可以尝试其他密钥
+var errSignature = errors.New("签名不匹配")
+
+// parse 使用指定的签名方法和密钥解析 Token
+func (m *manager) parse(tokenString string, method jwt.SigningMethod, key any) (*jwt.Token, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// 验证签名方法
-		if token.Method != m.opts.Method {
-			return nil, fmt.Errorf("意外的签名方法: %v", token.Header["alg"])
+		if token.Method != method {
+			return nil, fmt.Errorf("%w: 意外的签名方法 %v", errSignature, token.Header["alg"])
 		}
-		return []byte(m.opts.SignKey), nil
+		return key, nil
 	})
+	if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		err = fmt.Errorf("%w: %w", errSignature, err)
+	}
+	return token, err
+}
+
+// verifyToken 验证 Token 的内部方法，tokenType 为期望的 Token 类型
+// 当前密钥验签失败时依次尝试迁移窗口内的旧密钥，都失败时返回当前密钥的错误
+func (m *manager) verifyToken(tokenString, tokenType string) (*Claims, error) {
+	token, err := m.parse(tokenString, m.opts.Method, []byte(m.opts.SignKey))
+	if errors.Is(err, errSignature) {
+		now := time.Now()
+		for i, key := range m.legacy {
+			if !key.Until.IsZero() && now.After(key.Until) {
+				continue
+			}
+			legacyToken, legacyErr := m.parse(tokenString, key.Method, key.Key)
+			if legacyErr != nil {
+				continue
+			}
+			m.legacyCount[i].Add(1)
+			if m.legacyUse != nil {
+				m.legacyUse(key.Name)
+			}
+			token, err = legacyToken, nil
+			break
+		}
+	}
 
 	if err != nil {
 		return nil, fmt.Errorf("解析 Token 失败: %w", err)
@@ -116,6 +195,9 @@ func (m *manager) verifyToken(tokenString string) (*Claims, error) {
 	if !ok {
 		return nil, fmt.Errorf("无效的 Claims 类型")
 	}
+	if m.tokenType(claims) != tokenType {
+		return nil, fmt.Errorf("Token 类型不正确，需要 %s Token", tokenType)
+	}
 
 	return claims, nil
 }
@@ -20,11 +20,18 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token 类型，签发时写入 Claims.TokenType，验证时检查，避免 Refresh Token 被当作 Access Token 使用
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 // Claims JWT 声明结构
 type Claims struct {
-	UserId string            `json:"user_id"` // 用户 ID（使用 string 类型）
-	SSID   string            `json:"ssid"`    // Session ID
-	Data   map[string]string `json:"data"`    // 额外数据
+	UserId    string            `json:"user_id"`              // 用户 ID（使用 string 类型）
+	SSID      string            `json:"ssid"`                 // Session ID
+	Data      map[string]string `json:"data"`                 // 额外数据
+	TokenType string            `json:"token_type,omitempty"` // Token 类型（access、refresh），签发时设置
 	jwt.RegisteredClaims
 }
 
@@ -55,6 +62,19 @@ func NewOptions(signKey string, accessExpire, refreshExpire time.Duration) Optio
 	}
 }
 
+// LegacyKey 迁移期间只用于验签的旧密钥
+// 更换签名密钥或算法时，旧密钥签发的 Token 在迁移窗口内仍然有效，新 Token 使用新密钥签发
+type LegacyKey struct {
+	// Name 名称，用于统计和日志，如 "2024-hs256"
+	Name string
+	// Method 签名方法，为 nil 时为 HS256
+	Method jwt.SigningMethod
+	// Key 验签密钥：HMAC 为 []byte 或 string，RSA、ECDSA、EdDSA 为公钥
+	Key any
+	// Until 迁移窗口结束时间，之后不再接受该密钥签发的 Token，零值表示不限制
+	Until time.Time
+}
+
 // TokenPair Token 对（Access Token + Refresh Token）
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`  // 访问令牌（短期有效）
@@ -69,9 +89,14 @@ type Manager interface {
 	// GenerateTokenPair 生成 Token 对（Access Token + Refresh Token）
 	GenerateTokenPair(claims Claims) (*TokenPair, error)
 
-	// VerifyToken 验证 Token
+	// VerifyToken 验证 Access Token，Refresh Token 验证失败
 	VerifyToken(token string) (*Claims, error)
 
-	// VerifyRefreshToken 验证 Refresh Token
+	// VerifyRefreshToken 验证 Refresh Token，Access Token 验证失败
 	VerifyRefreshToken(token string) (*Claims, error)
+
+	// WithLegacyKeys 返回同时接受旧密钥签发的 Token 的管理器，使用旧密钥验签成功时调用 onUse（可以为 nil）
+	WithLegacyKeys(onUse func(name string), keys ...LegacyKey) Manager
+	// LegacyUsage 返回各旧密钥验签成功的次数
+	LegacyUsage() map[string]uint64
 }
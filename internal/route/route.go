@@ -0,0 +1,29 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package route
+
+import "github.com/gin-gonic/gin"
+
+// Unmatched 未匹配路由的统一标签
+// 扫描器请求的随机路径统一归入该标签，避免指标标签和日志索引的基数失控
+const Unmatched = "unmatched"
+
+// Label 返回请求的路由标签：匹配到路由时为路由模板（如 /users/:id），否则为 Unmatched
+func Label(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return path
+	}
+	return Unmatched
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supervisor
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	// minBackoff 首次重启前的等待时间
+	minBackoff = time.Second
+	// maxBackoff 重启等待时间的上限
+	maxBackoff = time.Minute
+	// healthyRun 协程连续运行超过该时间后视为恢复正常，下次 panic 时退避时间从 minBackoff 重新开始
+	healthyRun = maxBackoff
+)
+
+// Supervisor 受监管的后台协程
+// 协程发生 panic 时记录日志并按指数退避重启，调用 Close 后停止
+type Supervisor struct {
+	name string
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Go 启动受监管的后台协程
+// name: 协程名称，用于日志
+// fn: 协程主体，应在 stop 关闭后尽快返回；正常返回视为结束，不会重启
+func Go(name string, fn func(stop <-chan struct{})) *Supervisor {
+	s := &Supervisor{
+		name: name,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.run(fn)
+	return s
+}
+
+// Close 停止协程并等待其退出，可重复调用
+func (s *Supervisor) Close() error {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+	return nil
+}
+
+// run 运行协程，panic 后按退避时间重启
+func (s *Supervisor) run(fn func(stop <-chan struct{})) {
+	defer close(s.done)
+
+	backoff := minBackoff
+	for {
+		start := time.Now()
+		if !s.safeRun(fn) {
+			// 正常返回
+			return
+		}
+		// 长时间正常运行后才 panic，不是连续崩溃，重置退避时间
+		if time.Since(start) > healthyRun {
+			backoff = minBackoff
+		}
+
+		slog.Warn("后台协程将在退避后重启",
+			slog.String("name", s.name),
+			slog.Duration("backoff", backoff))
+
+		select {
+		case <-time.After(backoff):
+		case <-s.stop:
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// safeRun 执行一次协程主体，返回是否发生了 panic
+func (s *Supervisor) safeRun(fn func(stop <-chan struct{})) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			slog.Error("后台协程发生 panic",
+				slog.String("name", s.name),
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())))
+		}
+	}()
+	fn(s.stop)
+	return false
+}
@@ -0,0 +1,317 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/internal/clientip"
+)
+
+// ctxLoginResultKey 在 Context 中存储登录结果的 key
+const ctxLoginResultKey = "gint:login_result"
+
+// LoginProtectionConfig 登录保护配置
+type LoginProtectionConfig struct {
+	// IPLimit 单个 IP 在 IPWindow 内最多的登录尝试次数，默认 20 次/分钟，小于 0 表示不限制
+	IPLimit  int
+	IPWindow time.Duration
+
+	// MaxFailures 单个账号连续失败多少次后锁定，默认 5 次，小于 0 表示不锁定
+	MaxFailures int
+	// LockoutDuration 失败次数的统计窗口和账号锁定时间，默认 15 分钟
+	LockoutDuration time.Duration
+
+	// CaptchaAfter 账号或 IP 失败多少次后要求验证码，0 表示不要求
+	CaptchaAfter int
+	// VerifyCaptcha 校验请求中的验证码，CaptchaAfter 大于 0 时必须设置
+	VerifyCaptcha func(c *gin.Context) bool
+
+	// AccountFunc 获取请求中的账号，默认读取 JSON 或表单请求体（前 64KB）中的 username 字段
+	AccountFunc func(c *gin.Context) string
+
+	// TrustedProxies 可信代理的 IP 或网段，默认为空，按 TCP 连接的对端地址统计 IP
+	// 部署在反向代理之后时设置，只有对端是可信代理时才从 X-Forwarded-For 中读取客户端 IP
+	TrustedProxies []string
+
+	// Store 计数存储，默认为单机内存存储，多实例部署时需要使用共享存储
+	Store LoginAttemptStore
+}
+
+// LoginAttemptStore 登录尝试次数的计数存储
+type LoginAttemptStore interface {
+	// Incr 计数加一并返回加一后的值，计数不存在时创建，window 后过期
+	Incr(ctx context.Context, key string, window time.Duration) (int, error)
+	// Get 返回计数及剩余的过期时间，计数不存在时返回 0
+	Get(ctx context.Context, key string) (int, time.Duration, error)
+	// Delete 删除计数
+	Delete(ctx context.Context, key string) error
+}
+
+// LoginRejection 登录请求被拒绝时响应中 data 字段的内容
+type LoginRejection struct {
+	Reason     string `json:"reason"`                // ip_limited、account_locked 或 captcha_required
+	RetryAfter int    `json:"retry_after,omitempty"` // 建议的重试等待秒数
+}
+
+// LoginSucceeded 标记登录成功，清除账号和 IP 的失败计数
+func LoginSucceeded(c *gin.Context) {
+	c.Set(ctxLoginResultKey, true)
+}
+
+// LoginFailed 标记登录失败（如密码错误），保留处理前计入的账号和 IP 失败次数
+// 未标记时，响应状态码小于 400 视为成功，其他情况视为失败
+func LoginFailed(c *gin.Context) {
+	c.Set(ctxLoginResultKey, false)
+}
+
+// LoginProtection 创建登录保护中间件，组合了按 IP 限流、账号锁定和失败后要求验证码
+// 处理函数通过 LoginSucceeded、LoginFailed 标记登录结果
+//
+// 示例:
+//
+//	router.POST("/login", gint.LoginProtection(gint.LoginProtectionConfig{
+//	   CaptchaAfter:  3,
+//	   VerifyCaptcha: func(c *gin.Context) bool { return captcha.Verify(c.GetHeader("X-Captcha-Id"), c.GetHeader("X-Captcha")) },
+//	}), gint.BS(login))
+func LoginProtection(config LoginProtectionConfig) gin.HandlerFunc {
+	if config.IPLimit == 0 {
+		config.IPLimit = 20
+	}
+	if config.IPWindow <= 0 {
+		config.IPWindow = time.Minute
+	}
+	if config.MaxFailures == 0 {
+		config.MaxFailures = 5
+	}
+	if config.LockoutDuration <= 0 {
+		config.LockoutDuration = 15 * time.Minute
+	}
+	if config.CaptchaAfter > 0 && config.VerifyCaptcha == nil {
+		panic("gint: LoginProtection 设置了 CaptchaAfter 但未设置 VerifyCaptcha")
+	}
+	if config.AccountFunc == nil {
+		config.AccountFunc = bodyAccount("username")
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryLoginAttemptStore()
+	}
+	resolver, err := clientip.New(config.TrustedProxies)
+	if err != nil {
+		panic("gint: LoginProtection 的 TrustedProxies 配置错误: " + err.Error())
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		store := config.Store
+		ip := resolver.IP(c.Request).String()
+		ipKey, ipFailKey := "login:ip:"+ip, "login:ip_fail:"+ip
+		var accountKey string
+		if account := strings.ToLower(strings.TrimSpace(config.AccountFunc(c))); account != "" {
+			accountKey = "login:account:" + account
+		}
+
+		// 按 IP 限流
+		if config.IPLimit > 0 {
+			n, err := store.Incr(ctx, ipKey, config.IPWindow)
+			if err != nil {
+				slog.Error("记录登录尝试次数失败", slog.String("key", ipKey), slog.Any("err", err))
+			} else if n > config.IPLimit {
+				_, ttl, _ := store.Get(ctx, ipKey)
+				rejectLogin(c, http.StatusTooManyRequests, "登录尝试过于频繁，请稍后再试", "ip_limited", ttl)
+				return
+			}
+		}
+
+		// 处理之前先计入失败次数，成功后再清除：并发的登录请求各自拿到递增后的计数，不能同时绕过锁定和验证码
+		// 计数减一即为之前未成功的尝试次数，包括仍在处理中的请求
+		accountFailures := countAttempt(ctx, store, accountKey, config.LockoutDuration) - 1
+		ipFailures := countAttempt(ctx, store, ipFailKey, config.LockoutDuration) - 1
+
+		// 账号锁定
+		if accountKey != "" && config.MaxFailures > 0 && accountFailures >= config.MaxFailures {
+			_, ttl, _ := store.Get(ctx, accountKey)
+			rejectLogin(c, http.StatusTooManyRequests, "登录失败次数过多，账号已临时锁定", "account_locked", ttl)
+			return
+		}
+
+		// 失败次数较多时要求验证码
+		if config.CaptchaAfter > 0 && max(accountFailures, ipFailures) >= config.CaptchaAfter && !config.VerifyCaptcha(c) {
+			rejectLogin(c, http.StatusForbidden, "请输入正确的验证码", "captcha_required", 0)
+			return
+		}
+
+		c.Next()
+
+		// 标记成功，或未标记且响应成功时清除计数；其他情况保留已计入的失败
+		succeeded, marked := c.Get(ctxLoginResultKey)
+		if marked && succeeded.(bool) || !marked && c.Writer.Status() < http.StatusBadRequest {
+			for _, key := range []string{accountKey, ipFailKey} {
+				if key == "" {
+					continue
+				}
+				if err := store.Delete(ctx, key); err != nil {
+					slog.Error("清除登录失败次数失败", slog.String("key", key), slog.Any("err", err))
+				}
+			}
+		}
+	}
+}
+
+// countAttempt 计入一次登录尝试并返回计入后的次数，key 为空或计数失败时返回 0
+func countAttempt(ctx context.Context, store LoginAttemptStore, key string, window time.Duration) int {
+	if key == "" {
+		return 0
+	}
+	n, err := store.Incr(ctx, key, window)
+	if err != nil {
+		slog.Error("记录登录失败次数失败", slog.String("key", key), slog.Any("err", err))
+		return 0
+	}
+	return n
+}
+
+// rejectLogin 拒绝登录请求
+func rejectLogin(c *gin.Context, status int, msg, reason string, retryAfter time.Duration) {
+	rejection := LoginRejection{Reason: reason}
+	if retryAfter > 0 {
+		rejection.RetryAfter = int(math.Ceil(retryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(rejection.RetryAfter))
+	}
+	abortError(c, status, Result{
+		Code:    status,
+		Msg:     msg,
+		Data:    rejection,
+		TraceID: traceID(c),
+	})
+}
+
+// maxAccountBody 读取账号字段时最多读取的请求体长度
+const maxAccountBody = 64 << 10
+
+// bodyAccount 从 JSON 或表单请求体中读取账号字段，读取后恢复请求体，不影响后续的参数绑定
+// 只读取前 maxAccountBody 字节，超过时不解析账号
+func bodyAccount(field string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody || !loggableBody(c.ContentType()) {
+			return ""
+		}
+		rest := c.Request.Body
+		body, _ := io.ReadAll(io.LimitReader(rest, maxAccountBody+1))
+		c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), rest), rest}
+		if len(body) > maxAccountBody {
+			return ""
+		}
+
+		if c.ContentType() == gin.MIMEPOSTForm {
+			values, _ := url.ParseQuery(string(body))
+			return values.Get(field)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return ""
+		}
+		account, _ := fields[field].(string)
+		return account
+	}
+}
+
+// MemoryLoginAttemptStore 单机内存的登录尝试计数存储
+type MemoryLoginAttemptStore struct {
+	mu        sync.Mutex
+	counters  map[string]*attemptCounter
+	lastSweep time.Time
+}
+
+// attemptCounter 计数及过期时间
+type attemptCounter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewMemoryLoginAttemptStore 创建内存计数存储
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{counters: make(map[string]*attemptCounter), lastSweep: time.Now()}
+}
+
+// Incr 计数加一
+func (s *MemoryLoginAttemptStore) Incr(_ context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	c, ok := s.counters[key]
+	if !ok || now.After(c.expiresAt) {
+		c = &attemptCounter{expiresAt: now.Add(window)}
+		s.counters[key] = c
+	}
+	c.count++
+	return c.count, nil
+}
+
+// Get 返回计数及剩余的过期时间
+func (s *MemoryLoginAttemptStore) Get(_ context.Context, key string) (int, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok {
+		return 0, 0, nil
+	}
+	ttl := time.Until(c.expiresAt)
+	if ttl <= 0 {
+		delete(s.counters, key)
+		return 0, 0, nil
+	}
+	return c.count, ttl, nil
+}
+
+// Delete 删除计数
+func (s *MemoryLoginAttemptStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.counters, key)
+	return nil
+}
+
+// sweep 每分钟清理一次过期的计数，避免随机账号名的请求让内存持续增长
+func (s *MemoryLoginAttemptStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, c := range s.counters {
+		if now.After(c.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+}
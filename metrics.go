@@ -0,0 +1,240 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/internal/route"
+)
+
+// Metrics 包装器单次调用的指标
+type Metrics struct {
+	Handler  string        // 业务处理函数名，可通过 WithName 指定
+	Method   string        // 请求方法
+	Path     string        // 路由模板（如 /users/:id），未匹配路由时为 unmatched
+	Code     int           // 业务码，参数绑定失败或返回 ValidationErrors 时为 400；Server.WithGRPC 的 gRPC 请求为 gRPC 状态码
+	Status   int           // 实际输出的 HTTP 状态码
+	Duration time.Duration // 处理耗时（含参数绑定与响应输出）
+	Err      error         // 业务逻辑返回的错误，参数绑定或校验失败时为对应的错误
+
+	// Failures 参数校验失败的字段和规则，仅 Code 为 400 时有值
+	// 来自 binding 标签、ValidatorBuilder 和处理函数返回的 ValidationErrors，每个处理函数不同的组合数受 SetValidationLabelLimit 限制
+	Failures []ValidationFailure
+
+	// Canceled 客户端在处理完成前断开；处理函数因此返回 context.Canceled 时 Status 为 499
+	Canceled bool
+	// Wasted 客户端断开后仍在处理的时间，即被浪费的工作量
+	Wasted time.Duration
+}
+
+// ValidationFailure 单个字段的校验失败
+type ValidationFailure struct {
+	Field string // 字段名，下标和键记为 [*]，如 items[*].name；请求参数类型中不存在的字段或超出基数上限时为 OtherLabel
+	Rule  string // 规则名，如 required、min、email、min_length；类型错误为 type，未知字段为 unknown，请求体格式错误时为 malformed
+}
+
+// OtherLabel 超出基数上限的字段在指标中的名称
+const OtherLabel = "other"
+
+// defaultValidationLabelLimit 默认每个处理函数的字段和规则组合数上限
+const defaultValidationLabelLimit = 100
+
+// ctxValidationFailuresKey 在 Context 中暂存校验失败信息的 key
+const ctxValidationFailuresKey = "gint:validation_failures"
+
+// MetricsFunc 指标回调
+// 在请求处理完成后同步调用，实现中不应执行耗时操作
+type MetricsFunc func(m Metrics)
+
+// metricsFunc 全局指标回调，为 nil 时不采集
+var metricsFunc atomic.Pointer[MetricsFunc]
+
+// SetMetricsFunc 设置包装器的指标回调，所有包装器（W/B/S/BS/C/Stream）均会调用
+// 用于按业务处理函数记录 Prometheus、OpenTelemetry 等指标，传入 nil 关闭采集
+//
+// 示例:
+//
+//	gint.SetMetricsFunc(func(m gint.Metrics) {
+//	   requestTotal.WithLabelValues(m.Handler, strconv.Itoa(m.Code)).Inc()
+//	   requestDuration.WithLabelValues(m.Handler).Observe(m.Duration.Seconds())
+//	   for _, f := range m.Failures {
+//	      validationFailures.WithLabelValues(m.Handler, f.Field, f.Rule).Inc()
+//	   }
+//	})
+func SetMetricsFunc(fn MetricsFunc) {
+	if fn == nil {
+		metricsFunc.Store(nil)
+		return
+	}
+	metricsFunc.Store(&fn)
+}
+
+// validationLabels 已上报的处理函数、字段和规则组合
+var validationLabels = newLabelLimiter(defaultValidationLabelLimit)
+
+// SetValidationLabelLimit 设置校验失败指标中每个处理函数的字段和规则组合数上限，默认 100
+// 超出上限后新出现的字段记为 OtherLabel；上限按处理函数分别计算，一个接口的异常请求不会影响其他接口的指标
+func SetValidationLabelLimit(limit int) {
+	validationLabels.limit.Store(int64(limit))
+}
+
+// labelLimiter 按处理函数限制标签组合数
+type labelLimiter struct {
+	mu    sync.Mutex
+	limit atomic.Int64
+	seen  map[string]map[string]struct{} // 处理函数 -> 字段和规则组合
+}
+
+func newLabelLimiter(limit int) *labelLimiter {
+	l := &labelLimiter{seen: make(map[string]map[string]struct{})}
+	l.limit.Store(int64(limit))
+	return l
+}
+
+// allow 判断处理函数的组合是否可以作为独立的标签上报
+func (l *labelLimiter) allow(handler, key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	seen, ok := l.seen[handler]
+	if !ok {
+		seen = make(map[string]struct{})
+		l.seen[handler] = seen
+	}
+	if _, ok := seen[key]; ok {
+		return true
+	}
+	if int64(len(seen)) >= l.limit.Load() {
+		return false
+	}
+	seen[key] = struct{}{}
+	return true
+}
+
+// WithName 设置处理函数在指标中的名称
+// 默认使用函数名（如 handler.ListUsers），匿名函数建议显式指定
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// observe 上报一次调用的指标
+func observe(c *gin.Context, o *options, start time.Time, code int, err error) {
+	fn := metricsFunc.Load()
+	if fn == nil {
+		return
+	}
+
+	path := route.Label(c)
+	var (
+		ve       ValidationErrors
+		failures []ValidationFailure
+	)
+	switch {
+	case errors.Is(err, errBindFailed):
+		code = http.StatusBadRequest
+		if val, ok := c.Get(ctxValidationFailuresKey); ok {
+			failures, _ = val.([]ValidationFailure)
+		}
+		// 记录实际的绑定或校验错误，与访问日志看到的一致
+		if ge, _, ok := gctx.HandlerError(c); ok {
+			err = ge.Err
+		}
+	case errors.As(err, &ve):
+		code = http.StatusBadRequest
+		failures = ve.failures()
+	case err == nil && code == CodeSuccess:
+		// 业务逻辑成功但输出响应时失败，如响应体超过限制、文件不存在、幂等键冲突
+		if ge, meta, ok := gctx.HandlerError(c); ok {
+			code, err = meta.Code, ge.Err
+		}
+	}
+	wasted, canceled := cancelInfo(c)
+	(*fn)(Metrics{
+		Handler:  o.name,
+		Method:   c.Request.Method,
+		Path:     path,
+		Code:     code,
+		Status:   c.Writer.Status(),
+		Duration: time.Since(start),
+		Err:      err,
+		Failures: capFailures(o.name, failures),
+		Canceled: canceled,
+		Wasted:   wasted,
+	})
+}
+
+// capFailures 将超出基数上限的字段替换为 OtherLabel
+func capFailures(handler string, failures []ValidationFailure) []ValidationFailure {
+	if len(failures) == 0 {
+		return nil
+	}
+	out := make([]ValidationFailure, len(failures))
+	for i, f := range failures {
+		if !validationLabels.allow(handler, f.Field+"\x00"+f.Rule) {
+			f.Field = OtherLabel
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// labelField 将字段路径中的下标和键替换为 [*]，如 items[3].name 为 items[*].name
+// 下标和 map 的键来自请求内容，直接作为标签会使基数不受控制
+func labelField(path string) string {
+	if !strings.Contains(path, "[") {
+		return path
+	}
+	var sb strings.Builder
+	for {
+		start := strings.IndexByte(path, '[')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(path[start:], ']')
+		if end < 0 {
+			break
+		}
+		sb.WriteString(path[:start])
+		sb.WriteString("[*]")
+		path = path[start+end+1:]
+	}
+	sb.WriteString(path)
+	return sb.String()
+}
+
+// funcName 返回函数的名称，去掉包路径前缀
+func funcName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+	name := f.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, "-fm")
+}
@@ -24,6 +24,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/internal/route"
+	"github.com/ink-code/gint/middlewares/canary"
+	"github.com/ink-code/gint/middlewares/diagnostics"
 )
 
 // AccessLog 访问日志结构
@@ -38,6 +43,17 @@ type AccessLog struct {
 	Status   int    `json:"status"`    // HTTP 状态码
 	Duration int64  `json:"duration"`  // 处理时间（毫秒）
 	Error    string `json:"error"`     // 错误信息
+	// ErrorCode 包装器记录的错误的业务码，没有错误时为 0
+	ErrorCode int `json:"error_code,omitempty"`
+
+	// Route 路由模板（如 /users/:id），未匹配路由时为 unmatched，适合作为日志索引字段
+	Route string `json:"route"`
+
+	// Variant 金丝雀分组，仅当请求经过 canary 中间件时存在
+	Variant string `json:"variant,omitempty"`
+
+	// Diagnostics 资源诊断数据，仅当请求被 diagnostics 中间件采样时存在
+	Diagnostics *diagnostics.Stats `json:"diagnostics,omitempty"`
 }
 
 // LogFunc 日志处理函数类型
@@ -49,6 +65,7 @@ type Builder struct {
 	logReqBody    bool    // 是否记录请求体
 	logRespBody   bool    // 是否记录响应体
 	maxBodyLength int     // 最大记录长度
+	rawUnmatched  bool    // 未匹配路由时是否保留原始路径
 }
 
 // NewBuilder 创建访问日志中间件构建器
@@ -79,6 +96,13 @@ func (b *Builder) WithMaxBodyLength(length int) *Builder {
 	return b
 }
 
+// WithRawUnmatched 未匹配路由的请求也记录原始路径和查询参数
+// 默认记录为 unmatched，避免扫描器请求的随机路径让日志索引膨胀
+func (b *Builder) WithRawUnmatched() *Builder {
+	b.rawUnmatched = true
+	return b
+}
+
 // Build 构建中间件
 func (b *Builder) Build() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -101,9 +125,22 @@ func (b *Builder) Build() gin.HandlerFunc {
 
 		// 记录请求体
 		if b.logReqBody && c.Request.Body != nil {
-			bodyBytes, _ := io.ReadAll(c.Request.Body)
-			// 恢复请求体，以便后续处理
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+			// 只读取记录所需的部分：可以定位的请求体（如 spool 转存的）读取后定位回原来的位置，
+			// 其他请求体把读取的部分与剩余部分拼接后恢复，以便后续处理
+			limited := io.LimitReader(c.Request.Body, int64(b.maxBodyLength)+1)
+			var bodyBytes []byte
+			if rs, ok := c.Request.Body.(io.ReadSeeker); ok {
+				if pos, err := rs.Seek(0, io.SeekCurrent); err == nil {
+					bodyBytes, _ = io.ReadAll(limited)
+					rs.Seek(pos, io.SeekStart)
+				}
+			} else {
+				bodyBytes, _ = io.ReadAll(limited)
+				c.Request.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.MultiReader(bytes.NewReader(bodyBytes), c.Request.Body), c.Request.Body}
+			}
 
 			if len(bodyBytes) > b.maxBodyLength {
 				log.ReqBody = string(bodyBytes[:b.maxBodyLength]) + "...(truncated)"
@@ -135,15 +172,32 @@ func (b *Builder) Build() gin.HandlerFunc {
 			c.Next()
 		}
 
+		// 记录路由，未匹配路由时不保留随机路径
+		log.Route = route.Label(c)
+		if log.Route == route.Unmatched && !b.rawUnmatched {
+			log.Path, log.Query = route.Unmatched, ""
+		}
+
 		// 记录状态码和处理时间
 		log.Status = c.Writer.Status()
 		log.Duration = time.Since(start).Milliseconds()
 
-		// 记录错误信息
-		if len(c.Errors) > 0 {
+		// 记录错误信息，优先使用包装器记录的错误
+		if ge, meta, ok := gctx.HandlerError(c); ok {
+			log.Error = ge.Error()
+			log.ErrorCode = meta.Code
+		} else if len(c.Errors) > 0 {
 			log.Error = c.Errors.String()
 		}
 
+		// 记录金丝雀分组
+		log.Variant = c.GetString(canary.CtxVariantKey)
+
+		// 记录诊断数据（如果请求被采样）
+		if stats, ok := diagnostics.FromContext(c); ok {
+			log.Diagnostics = stats
+		}
+
 		// 调用日志处理函数
 		b.logFunc(log)
 	}
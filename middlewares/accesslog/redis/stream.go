@@ -0,0 +1,111 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ink-code/gint/internal/supervisor"
+	"github.com/ink-code/gint/middlewares/accesslog"
+)
+
+// writeTimeout 单次写入 Redis 的超时时间，避免 Redis 故障拖慢请求
+const writeTimeout = time.Second
+
+// StreamSink 写入 Redis Stream 的访问日志存储，每条日志为一个条目，data 字段为 JSON
+// 写入时按 MaxEntries 近似裁剪长度，后台协程定期按 MaxAge 裁剪过期条目；MaxSize 不适用于 Redis
+// Log 方法可以直接作为 accesslog.NewBuilder 的日志处理函数
+//
+// 示例:
+//
+//	sink := redis.NewStreamSink(client, "app:access_log", accesslog.Retention{MaxAge: 72 * time.Hour, MaxEntries: 1_000_000})
+//	defer sink.Close()
+//	r.Use(accesslog.NewBuilder(sink.Log).Build())
+type StreamSink struct {
+	client    redis.UniversalClient
+	key       string
+	retention accesslog.Retention
+	cleaner   *supervisor.Supervisor
+}
+
+// NewStreamSink 创建 Redis Stream 存储，设置了 MaxAge 时启动后台清理协程
+func NewStreamSink(client redis.UniversalClient, key string, retention accesslog.Retention) *StreamSink {
+	s := &StreamSink{client: client, key: key, retention: retention}
+	if retention.MaxAge > 0 {
+		s.cleaner = supervisor.Go("accesslog-stream-cleaner", s.trimLoop)
+	}
+	return s
+}
+
+// Log 写入一条访问日志，写入失败时记录错误日志
+func (s *StreamSink) Log(log *accesslog.AccessLog) {
+	data, err := json.Marshal(log)
+	if err != nil {
+		slog.Warn("序列化访问日志失败", slog.Any("err", err))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+	args := &redis.XAddArgs{Stream: s.key, Values: []any{"data", data}}
+	if s.retention.MaxEntries > 0 {
+		args.MaxLen, args.Approx = s.retention.MaxEntries, true
+	}
+	if err := s.client.XAdd(ctx, args).Err(); err != nil {
+		slog.Warn("写入访问日志失败", slog.String("key", s.key), slog.Any("err", err))
+	}
+}
+
+// Trim 裁剪超过 MaxAge 的条目（近似裁剪，可能保留少量过期条目），后台协程会定期调用
+func (s *StreamSink) Trim(ctx context.Context) error {
+	if s.retention.MaxAge <= 0 {
+		return nil
+	}
+	// 条目 ID 的前半部分为写入时的毫秒时间戳
+	minID := strconv.FormatInt(time.Now().Add(-s.retention.MaxAge).UnixMilli(), 10)
+	return s.client.XTrimMinIDApprox(ctx, s.key, minID, 0).Err()
+}
+
+// Close 停止清理协程，不关闭 Redis 客户端
+func (s *StreamSink) Close() error {
+	if s.cleaner == nil {
+		return nil
+	}
+	return s.cleaner.Close()
+}
+
+// trimLoop 定期裁剪过期条目
+func (s *StreamSink) trimLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.retention.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := s.Trim(ctx); err != nil {
+				slog.Warn("裁剪访问日志失败", slog.String("key", s.key), slog.Any("err", err))
+			}
+			cancel()
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ink-code/gint/internal/supervisor"
+)
+
+const (
+	// defaultMaxFileSize 单个日志文件的默认大小上限
+	defaultMaxFileSize = 100 << 20
+	// defaultTrimInterval 默认的清理间隔
+	defaultTrimInterval = 10 * time.Minute
+	// filePrefix、fileSuffix 日志文件名的前缀和后缀，如 access-20250618-150405.log
+	filePrefix = "access-"
+	fileSuffix = ".log"
+)
+
+// Retention 访问日志的保留策略，为 0 的项不限制
+type Retention struct {
+	MaxAge time.Duration // 最长保留时间
+
+	// MaxSize 文件存储的总大小上限（字节），超过时从最早的文件开始删除，正在写入的文件不会被删除
+	MaxSize int64
+
+	// MaxEntries Redis Stream 存储的最大条数（近似值），见 accesslog/redis
+	MaxEntries int64
+
+	// TrimInterval 后台清理的间隔，默认 10 分钟
+	TrimInterval time.Duration
+}
+
+// Interval 返回后台清理的间隔
+func (r Retention) Interval() time.Duration {
+	if r.TrimInterval > 0 {
+		return r.TrimInterval
+	}
+	return defaultTrimInterval
+}
+
+// FileSink 按行写入 JSON 的文件存储，按日期和大小切分文件，并按保留策略定期删除旧文件
+// Log 方法可以直接作为 NewBuilder 的日志处理函数
+//
+// 示例:
+//
+//	sink, err := accesslog.NewFileSink("/var/log/app", accesslog.Retention{MaxAge: 7 * 24 * time.Hour, MaxSize: 10 << 30})
+//	if err != nil {
+//	   log.Fatal(err)
+//	}
+//	defer sink.Close()
+//	r.Use(accesslog.NewBuilder(sink.Log).Build())
+type FileSink struct {
+	dir         string
+	retention   Retention
+	maxFileSize int64
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64
+	day     string // 当前文件的日期
+	cleaner *supervisor.Supervisor
+}
+
+// NewFileSink 创建文件存储，dir 不存在时自动创建，并启动后台清理协程
+func NewFileSink(dir string, retention Retention) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	s := &FileSink{dir: dir, retention: retention, maxFileSize: defaultMaxFileSize}
+	s.cleaner = supervisor.Go("accesslog-file-cleaner", s.trimLoop)
+	return s, nil
+}
+
+// WithMaxFileSize 设置单个文件的大小上限，超过后切换到新文件，默认 100MB
+func (s *FileSink) WithMaxFileSize(size int64) *FileSink {
+	s.maxFileSize = size
+	return s
+}
+
+// Log 写入一条访问日志，写入失败时记录错误日志
+func (s *FileSink) Log(log *AccessLog) {
+	line, err := json.Marshal(log)
+	if err != nil {
+		slog.Warn("序列化访问日志失败", slog.Any("err", err))
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rotate(time.Now(), int64(len(line))); err != nil {
+		slog.Warn("打开访问日志文件失败", slog.Any("err", err))
+		return
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		slog.Warn("写入访问日志失败", slog.Any("err", err))
+	}
+}
+
+// rotate 日期变化或文件将超过大小上限时切换到新文件
+func (s *FileSink) rotate(now time.Time, n int64) error {
+	day := now.Format("20060102")
+	if s.file != nil && s.day == day && (s.maxFileSize <= 0 || s.size+n <= s.maxFileSize || s.size == 0) {
+		return nil
+	}
+	if s.file != nil {
+		_ = s.file.Close()
+		s.file = nil
+	}
+	name := filepath.Join(s.dir, filePrefix+now.Format("20060102-150405.000000")+fileSuffix)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	s.file, s.size, s.day = f, 0, day
+	return nil
+}
+
+// Trim 按保留策略删除旧文件，后台协程会定期调用
+func (s *FileSink) Trim() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	type logFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []logFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), filePrefix) || !strings.HasSuffix(e.Name(), fileSuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, logFile{path: filepath.Join(s.dir, e.Name()), size: info.Size(), modTime: info.ModTime()})
+	}
+	// 文件名包含创建时间，按名称排序即从旧到新
+	slices.SortFunc(files, func(a, b logFile) int { return strings.Compare(a.path, b.path) })
+
+	s.mu.Lock()
+	current := ""
+	if s.file != nil {
+		current = s.file.Name()
+	}
+	s.mu.Unlock()
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	deadline := time.Now().Add(-s.retention.MaxAge)
+	for _, f := range files {
+		if f.path == current {
+			continue
+		}
+		expired := s.retention.MaxAge > 0 && f.modTime.Before(deadline)
+		oversize := s.retention.MaxSize > 0 && total > s.retention.MaxSize
+		if !expired && !oversize {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil {
+			return err
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+// Close 停止清理协程并关闭当前文件
+func (s *FileSink) Close() error {
+	_ = s.cleaner.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// trimLoop 定期按保留策略删除旧文件
+func (s *FileSink) trimLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.retention.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Trim(); err != nil {
+				slog.Warn("清理访问日志文件失败", slog.String("dir", s.dir), slog.Any("err", err))
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -15,15 +15,52 @@
 package activelimit
 
 import (
-	"net/http"
+	"sync"
 	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ink-code/gint/internal/route"
+	"github.com/ink-code/gint/middlewares/unavailable"
 )
 
+// Stats 活跃连接统计
+type Stats struct {
+	Active   int64 `json:"active"`   // 当前正在处理的请求数
+	Rejected int64 `json:"rejected"` // 累计拒绝的请求数
+}
+
+// MetricsFunc 指标回调函数类型
+// 每次请求开始、结束或被拒绝时调用，route 为路由模板（如 /users/:id），可用于更新 Prometheus 等指标
+type MetricsFunc func(route string, stats Stats)
+
+// counter 并发安全的计数器
+type counter struct {
+	active   atomic.Int64
+	rejected atomic.Int64
+}
+
+func (c *counter) stats() Stats {
+	return Stats{
+		Active:   c.active.Load(),
+		Rejected: c.rejected.Load(),
+	}
+}
+
+// limiter 一次 Build 创建的中间件的计数
+type limiter struct {
+	total  counter  // 全局计数
+	routes sync.Map // 路由计数 map[string]*counter
+}
+
 // Builder 活跃连接限制中间件构建器
+// 每次 Build 创建独立的计数和限制，Stats、RouteStats 汇总该 Builder 构建的所有中间件
 type Builder struct {
-	maxActive int64 // 最大活跃连接数
+	maxActive   int64       // 最大活跃连接数
+	perRoute    bool        // 是否按路由分别限制
+	metricsFunc MetricsFunc // 指标回调
+
+	mu       sync.Mutex
+	limiters []*limiter // Build 创建的中间件的计数
 }
 
 // NewBuilder 创建活跃连接限制中间件构建器
@@ -34,25 +71,102 @@ func NewBuilder(maxActive int64) *Builder {
 	}
 }
 
-// Build 构建中间件
+// WithPerRoute 按路由模板分别限制，每个路由最多 maxActive 个活跃连接
+func (b *Builder) WithPerRoute() *Builder {
+	b.perRoute = true
+	return b
+}
+
+// WithMetricsFunc 设置指标回调函数
+func (b *Builder) WithMetricsFunc(fn MetricsFunc) *Builder {
+	b.metricsFunc = fn
+	return b
+}
+
+// Stats 获取全局统计
+func (b *Builder) Stats() Stats {
+	var result Stats
+	for _, l := range b.snapshot() {
+		s := l.total.stats()
+		result.Active += s.Active
+		result.Rejected += s.Rejected
+	}
+	return result
+}
+
+// RouteStats 获取按路由模板划分的统计
+func (b *Builder) RouteStats() map[string]Stats {
+	result := make(map[string]Stats)
+	for _, l := range b.snapshot() {
+		l.routes.Range(func(key, value any) bool {
+			s, rs := result[key.(string)], value.(*counter).stats()
+			s.Active += rs.Active
+			s.Rejected += rs.Rejected
+			result[key.(string)] = s
+			return true
+		})
+	}
+	return result
+}
+
+// snapshot 返回 Build 创建的所有计数
+func (b *Builder) snapshot() []*limiter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*limiter(nil), b.limiters...)
+}
+
+// Build 构建中间件，每次调用创建独立的计数，多个中间件之间不共享限制
 func (b *Builder) Build() gin.HandlerFunc {
-	var currentActive int64
+	l := &limiter{}
+	b.mu.Lock()
+	b.limiters = append(b.limiters, l)
+	b.mu.Unlock()
 
 	return func(c *gin.Context) {
+		route := route.Label(c)
+		rc := l.routeCounter(route)
+
 		// 增加活跃连接计数
-		current := atomic.AddInt64(&currentActive, 1)
+		current := l.total.active.Add(1)
+		routeCurrent := rc.active.Add(1)
 
 		// 请求结束后减少计数
 		defer func() {
-			atomic.AddInt64(&currentActive, -1)
+			l.total.active.Add(-1)
+			rc.active.Add(-1)
+			b.report(route, rc)
 		}()
 
 		// 检查是否超过限制
-		if current > b.maxActive {
-			c.AbortWithStatus(http.StatusTooManyRequests)
+		limited := current
+		if b.perRoute {
+			limited = routeCurrent
+		}
+		if limited > b.maxActive {
+			l.total.rejected.Add(1)
+			rc.rejected.Add(1)
+			unavailable.TooManyRequests(c, unavailable.ReasonOverloaded, 0)
 			return
 		}
 
+		b.report(route, rc)
 		c.Next()
 	}
 }
+
+// routeCounter 获取或创建路由计数器
+func (l *limiter) routeCounter(route string) *counter {
+	if val, ok := l.routes.Load(route); ok {
+		return val.(*counter)
+	}
+	val, _ := l.routes.LoadOrStore(route, &counter{})
+	return val.(*counter)
+}
+
+// report 调用指标回调
+func (b *Builder) report(route string, rc *counter) {
+	if b.metricsFunc != nil {
+		b.metricsFunc(route, rc.stats())
+	}
+}
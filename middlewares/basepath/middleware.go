@@ -0,0 +1,142 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package basepath
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+)
+
+// HeaderForwardedPrefix 网关转发时携带的路径前缀请求头
+const HeaderForwardedPrefix = "X-Forwarded-Prefix"
+
+// Builder 基础路径中间件构建器
+type Builder struct {
+	prefix         string
+	trustForwarded bool
+}
+
+// NewBuilder 创建基础路径中间件构建器
+// prefix 为服务对外的基础路径，如 "/api/v1/serviceX"，为空表示部署在根路径
+func NewBuilder(prefix string) *Builder {
+	return &Builder{prefix: Clean(prefix)}
+}
+
+// WithForwardedPrefix 优先使用网关传入的 X-Forwarded-Prefix 请求头作为基础路径
+// 只应在服务仅能通过可信网关访问时开启，否则客户端可以伪造该请求头
+func (b *Builder) WithForwardedPrefix() *Builder {
+	b.trustForwarded = true
+	return b
+}
+
+// Build 构建中间件
+// 将基础路径写入 Context，供 ctx.BasePath()、ctx.URL()、gint.Redirect 和 Cookie 路径使用
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		base := b.prefix
+		if b.trustForwarded {
+			if prefix, ok := parseForwarded(c.GetHeader(HeaderForwardedPrefix)); ok {
+				base = prefix
+			}
+		}
+		if base != "" {
+			c.Set(gctx.CtxBasePathKey, base)
+		}
+		c.Next()
+	}
+}
+
+// StripPrefix 在路由匹配之前去掉请求路径中的基础路径
+// 适用于网关转发时保留了前缀的场景，路由仍按不带前缀的路径注册；不带前缀的请求原样处理
+//
+// 示例:
+//
+//	engine := gin.New()
+//	engine.Use(basepath.NewBuilder("/api/v1/serviceX").Build())
+//	engine.GET("/users/:id", ...)
+//	http.ListenAndServe(":8080", basepath.StripPrefix(engine, "/api/v1/serviceX"))
+func StripPrefix(h http.Handler, prefix string) http.Handler {
+	prefix = Clean(prefix)
+	if prefix == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, ok := trimPrefix(r.URL.Path, prefix)
+		if !ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		u := *r.URL
+		u.Path = path
+		if r.URL.RawPath != "" {
+			if raw, ok := trimPrefix(r.URL.RawPath, prefix); ok {
+				u.RawPath = raw
+			} else {
+				u.RawPath = ""
+			}
+		}
+		r2.URL = &u
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// Clean 规范化基础路径：补全开头的 "/"，去掉结尾的 "/"，根路径返回空字符串
+func Clean(prefix string) string {
+	prefix = strings.TrimSpace(prefix)
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
+
+// trimPrefix 按路径段去掉前缀，"/api/v1x" 不会匹配前缀 "/api/v1"
+func trimPrefix(path, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok {
+		return path, false
+	}
+	if rest == "" {
+		return "/", true
+	}
+	if !strings.HasPrefix(rest, "/") {
+		return path, false
+	}
+	return rest, true
+}
+
+// parseForwarded 解析 X-Forwarded-Prefix，多级网关时取第一个值
+// 拒绝协议相对 URL 和包含控制字符的值，避免被用于开放重定向或响应头注入
+func parseForwarded(header string) (string, bool) {
+	if header == "" {
+		return "", false
+	}
+	header, _, _ = strings.Cut(header, ",")
+	prefix := Clean(header)
+	if prefix == "" || strings.HasPrefix(prefix, "//") || strings.ContainsAny(prefix, "\r\n\\?#") {
+		return "", false
+	}
+	return prefix, true
+}
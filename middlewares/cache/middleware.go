@@ -0,0 +1,263 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/session"
+)
+
+// HeaderCache 标识响应是否命中缓存的响应头（HIT/MISS）
+const HeaderCache = "X-Cache"
+
+// KeyFunc 缓存键生成函数，返回空字符串表示该请求不使用缓存
+// 失效规则按键的前缀匹配，自定义的键应以请求路径开头，如 path?query#user_id
+type KeyFunc func(c *gin.Context) string
+
+// skipHeaders 不缓存的响应头
+var skipHeaders = map[string]bool{
+	"Set-Cookie":   true,
+	HeaderCache:    true,
+	"Content-Type": true, // 单独保存在 Entry.ContentType 中
+}
+
+// Builder 响应缓存中间件构建器
+// 同一个 Builder 构建的缓存中间件和失效中间件共享存储
+type Builder struct {
+	store   Store
+	ttl     time.Duration
+	keyFunc KeyFunc
+	vary    []string // 参与缓存键的请求头
+	maxSize int      // 可缓存的最大响应体字节数
+}
+
+// NewBuilder 创建响应缓存中间件构建器，只缓存 1MB 以内的 200 响应
+// 默认以请求 URI（路径 + 查询参数）和当前用户（session.UserId）作为缓存键，如 /api/profile?tab=1#u123；
+// 携带 Authorization 或 Cookie 请求头但无法确定用户的请求不使用缓存，避免把某个用户的响应返回给其他用户
+func NewBuilder(store Store, ttl time.Duration) *Builder {
+	return &Builder{
+		store:   store,
+		ttl:     ttl,
+		keyFunc: defaultKey,
+		maxSize: 1 << 20,
+	}
+}
+
+// defaultKey 默认的缓存键：请求 URI，已登录时追加 #用户 ID
+func defaultKey(c *gin.Context) string {
+	key := c.Request.URL.RequestURI()
+	if userId := session.UserId(&gctx.Context{Context: c}); userId != "" {
+		return key + "#" + userId
+	}
+	if c.GetHeader("Authorization") != "" || c.GetHeader("Cookie") != "" {
+		return ""
+	}
+	return key
+}
+
+// WithKeyFunc 设置缓存键生成函数
+func (b *Builder) WithKeyFunc(fn KeyFunc) *Builder {
+	b.keyFunc = fn
+	return b
+}
+
+// WithVary 设置参与缓存键的请求头，如 Accept-Language、X-Api-Key，请求头不同的请求使用不同的缓存
+func (b *Builder) WithVary(headers ...string) *Builder {
+	b.vary = append(b.vary, headers...)
+	return b
+}
+
+// WithMaxSize 设置可缓存的最大响应体字节数
+func (b *Builder) WithMaxSize(size int) *Builder {
+	b.maxSize = size
+	return b
+}
+
+// Build 构建缓存中间件，只缓存 GET 请求
+// 命中缓存时直接输出并终止后续的中间件，因此必须注册在认证、权限等中间件之后
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		key := b.key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		entry, err := b.store.Get(c.Request.Context(), key)
+		if err != nil {
+			slog.Warn("读取响应缓存失败", slog.String("key", key), slog.Any("err", err))
+		}
+		if entry != nil {
+			header := c.Writer.Header()
+			for name, values := range entry.Header {
+				header[name] = append([]string(nil), values...)
+			}
+			c.Header(HeaderCache, "HIT")
+			c.Data(entry.Status, entry.ContentType, entry.Body)
+			c.Abort()
+			return
+		}
+
+		c.Header(HeaderCache, "MISS")
+		w := &teeWriter{ResponseWriter: c.Writer, max: b.maxSize}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.Status() != http.StatusOK || w.overflow {
+			return
+		}
+		entry = &Entry{
+			Status:      w.Status(),
+			ContentType: w.Header().Get("Content-Type"),
+			Header:      cacheHeader(w.Header()),
+			Body:        w.body.Bytes(),
+		}
+		if err := b.store.Set(c.Request.Context(), key, entry, b.ttl); err != nil {
+			slog.Warn("写入响应缓存失败", slog.String("key", key), slog.Any("err", err))
+		}
+	}
+}
+
+// key 生成缓存键，配置了 WithVary 时追加请求头的值
+func (b *Builder) key(c *gin.Context) string {
+	key := b.keyFunc(c)
+	if key == "" || len(b.vary) == 0 {
+		return key
+	}
+	var sb strings.Builder
+	sb.WriteString(key)
+	for _, name := range b.vary {
+		sb.WriteString("|")
+		sb.WriteString(c.GetHeader(name))
+	}
+	return sb.String()
+}
+
+// cacheHeader 复制需要缓存的响应头
+func cacheHeader(h http.Header) http.Header {
+	header := make(http.Header, len(h))
+	for name, values := range h {
+		if !skipHeaders[name] {
+			header[name] = append([]string(nil), values...)
+		}
+	}
+	return header
+}
+
+// InvalidatesCache 构建缓存失效中间件，注册在 POST/PUT/DELETE 等写接口上
+// 请求成功（状态码小于 400）后删除匹配的缓存；pattern 以 * 结尾时按前缀匹配，
+// 否则删除该键以及按用户（#）、请求头（|）区分的所有变体，其中的路径参数（如 :id）会替换为当前请求的参数值
+//
+// 示例:
+//
+//	products := cache.NewBuilder(store, time.Minute)
+//	r.GET("/api/products", products.Build(), listProducts)
+//	r.GET("/api/products/:id", products.Build(), getProduct)
+//	r.PUT("/api/products/:id", products.InvalidatesCache("/api/products/:id", "/api/products?*"), updateProduct)
+//	r.POST("/api/products", products.InvalidatesCache("/api/products*"), createProduct)
+func (b *Builder) InvalidatesCache(patterns ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		for _, pattern := range patterns {
+			for _, p := range keyVariants(expandParams(c, pattern)) {
+				if err := b.store.Delete(c.Request.Context(), p); err != nil {
+					slog.Warn("删除响应缓存失败", slog.String("pattern", p), slog.Any("err", err))
+				}
+			}
+		}
+	}
+}
+
+// keyVariants 返回需要删除的模式：精确匹配的键同时按前缀删除 defaultKey 追加的用户（#用户 ID）
+// 和 WithVary 追加的请求头（|值），否则已登录用户或请求头不同的请求仍会读到旧数据
+func keyVariants(pattern string) []string {
+	if strings.HasSuffix(pattern, "*") {
+		return []string{pattern}
+	}
+	return []string{pattern, pattern + "#*", pattern + "|*"}
+}
+
+// expandParams 将 pattern 中的路径参数替换为当前请求的参数值
+func expandParams(c *gin.Context, pattern string) string {
+	if !strings.Contains(pattern, ":") {
+		return pattern
+	}
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		name, ok := strings.CutPrefix(seg, ":")
+		if !ok {
+			continue
+		}
+		// 参数后可以跟 * 表示前缀匹配，如 /users/:id*
+		name, star := strings.CutSuffix(name, "*")
+		if val, exists := c.Params.Get(name); exists {
+			segments[i] = val
+			if star {
+				segments[i] += "*"
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// teeWriter 在输出响应的同时保存一份响应体
+type teeWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	max      int
+	overflow bool // 响应体超过上限，不再缓存
+}
+
+// Write 写入响应并保存副本
+func (w *teeWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写入响应并保存副本
+func (w *teeWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture 保存响应体副本，超过上限时放弃
+func (w *teeWriter) capture(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > w.max {
+		w.overflow = true
+		w.body = bytes.Buffer{}
+		return
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ink-code/gint/internal/supervisor"
+)
+
+// Entry 缓存的响应
+type Entry struct {
+	Status      int         `json:"status"`           // HTTP 状态码
+	ContentType string      `json:"content_type"`     // 响应的 Content-Type
+	Header      http.Header `json:"header,omitempty"` // 其他响应头，不包含 Set-Cookie
+	Body        []byte      `json:"body"`             // 响应体
+}
+
+// Store 缓存存储接口
+type Store interface {
+	// Get 获取缓存，不存在或已过期时返回 nil
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Set 写入缓存
+	Set(ctx context.Context, key string, entry *Entry, ttl time.Duration) error
+	// Delete 删除匹配的缓存，pattern 以 * 结尾时按前缀匹配，否则精确匹配
+	Delete(ctx context.Context, pattern string) error
+}
+
+// MemoryStore 基于内存的缓存存储
+// 适用于单机部署；多实例部署时各实例的缓存不会同步失效
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+	cleaner *supervisor.Supervisor // 清理协程
+}
+
+type memoryEntry struct {
+	entry    *Entry
+	expireAt time.Time
+}
+
+// NewMemoryStore 创建内存缓存存储，每分钟清理一次过期缓存
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		entries: make(map[string]memoryEntry),
+	}
+	s.cleaner = supervisor.Go("cache-memory-store-cleaner", s.cleanupLoop)
+	return s
+}
+
+// Get 获取缓存
+func (s *MemoryStore) Get(_ context.Context, key string) (*Entry, error) {
+	s.mu.RLock()
+	e, ok := s.entries[key]
+	s.mu.RUnlock()
+	if !ok || time.Now().After(e.expireAt) {
+		return nil, nil
+	}
+	return e.entry, nil
+}
+
+// Set 写入缓存
+func (s *MemoryStore) Set(_ context.Context, key string, entry *Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	s.entries[key] = memoryEntry{entry: entry, expireAt: time.Now().Add(ttl)}
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete 删除匹配的缓存
+func (s *MemoryStore) Delete(_ context.Context, pattern string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix, isPrefix := strings.CutSuffix(pattern, "*")
+	if !isPrefix {
+		delete(s.entries, pattern)
+		return nil
+	}
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+// Close 停止清理协程
+func (s *MemoryStore) Close() error {
+	return s.cleaner.Close()
+}
+
+// cleanupLoop 清理过期的缓存
+func (s *MemoryStore) cleanupLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			s.mu.Lock()
+			for key, e := range s.entries {
+				if now.After(e.expireAt) {
+					delete(s.entries, key)
+				}
+			}
+			s.mu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
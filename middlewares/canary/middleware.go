@@ -0,0 +1,186 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"hash/fnv"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/session"
+)
+
+// 分组名称
+const (
+	// Stable 稳定版本
+	Stable = "stable"
+	// Canary 金丝雀版本
+	Canary = "canary"
+)
+
+// CtxVariantKey 在 Context 中存储分组的 key
+const CtxVariantKey = "gint:canary_variant"
+
+// buckets 分桶数量，百分比精确到 0.01
+const buckets = 10000
+
+// KeyFunc 生成分组键的函数，返回空字符串时使用 Cookie 中的访客 ID
+type KeyFunc func(c *gin.Context) string
+
+// Builder 金丝雀分组中间件构建器
+type Builder struct {
+	percent    atomic.Int64 // 金丝雀比例（万分比）
+	salt       string
+	keyFunc    KeyFunc
+	cookieName string
+	cookieAge  int
+	secure     bool // 访客 ID Cookie 是否只通过 HTTPS 发送
+}
+
+// NewBuilder 创建金丝雀分组中间件构建器
+// percent 为进入金丝雀分组的用户比例（0-100），默认按 Session 中的用户 ID 分组，未登录时按 Cookie 中的访客 ID 分组
+func NewBuilder(percent float64) *Builder {
+	b := &Builder{
+		salt: "gint",
+		keyFunc: func(c *gin.Context) string {
+			return session.UserId(&gctx.Context{Context: c})
+		},
+		cookieName: "gint_canary_id",
+		cookieAge:  365 * 24 * 3600,
+	}
+	b.SetPercent(percent)
+	return b
+}
+
+// WithSalt 设置分组哈希的盐值，更换盐值会重新打散分组，用于开始新一轮灰度
+func (b *Builder) WithSalt(salt string) *Builder {
+	b.salt = salt
+	return b
+}
+
+// WithKeyFunc 设置分组键的生成函数，如按租户 ID 分组
+func (b *Builder) WithKeyFunc(keyFunc KeyFunc) *Builder {
+	b.keyFunc = keyFunc
+	return b
+}
+
+// WithCookie 设置保存访客 ID 的 Cookie 名称和有效期（秒）
+func (b *Builder) WithCookie(name string, maxAge int) *Builder {
+	b.cookieName = name
+	b.cookieAge = maxAge
+	return b
+}
+
+// WithSecureCookie 设置访客 ID Cookie 的 Secure 属性，只通过 HTTPS 部署时应该开启，默认不开启
+func (b *Builder) WithSecureCookie(secure bool) *Builder {
+	b.secure = secure
+	return b
+}
+
+// SetPercent 调整金丝雀比例（0-100），可在运行时调用逐步放量
+// 同一个键在比例提高时保持在金丝雀分组中，不会在两个版本之间来回切换
+func (b *Builder) SetPercent(percent float64) {
+	percent = min(max(percent, 0), 100)
+	b.percent.Store(int64(percent * buckets / 100))
+}
+
+// Build 构建中间件
+// 将分组写入 Context，通过 Variant、IsCanary 读取，或使用 Switch、Upstream 按分组处理
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := b.keyFunc(c)
+		if key == "" {
+			key = b.visitorID(c)
+		}
+
+		variant := Stable
+		if bucket(b.salt, key) < b.percent.Load() {
+			variant = Canary
+		}
+		c.Set(CtxVariantKey, variant)
+		c.Next()
+	}
+}
+
+// visitorID 获取或生成访客 ID
+func (b *Builder) visitorID(c *gin.Context) string {
+	if id, err := c.Cookie(b.cookieName); err == nil && id != "" {
+		return id
+	}
+	id := uuid.NewString()
+	path := gctx.JoinBasePath(c.GetString(gctx.CtxBasePathKey), "/")
+	c.SetCookie(b.cookieName, id, b.cookieAge, path, "", b.secure, true)
+	return id
+}
+
+// bucket 计算键所在的分桶
+func bucket(salt, key string) int64 {
+	h := fnv.New32a()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return int64(h.Sum32() % buckets)
+}
+
+// Variant 获取当前请求的分组，未经过分组中间件时返回 Stable
+func Variant(c *gin.Context) string {
+	if v := c.GetString(CtxVariantKey); v != "" {
+		return v
+	}
+	return Stable
+}
+
+// IsCanary 判断当前请求是否属于金丝雀分组
+func IsCanary(c *gin.Context) bool {
+	return Variant(c) == Canary
+}
+
+// Switch 按分组选择处理函数，金丝雀分组使用 canary，其余使用 stable
+//
+// 示例:
+//
+//	router.GET("/search", canary.Switch(gint.B(searchV1), gint.B(searchV2)))
+func Switch(stable, canary gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsCanary(c) {
+			canary(c)
+			return
+		}
+		stable(c)
+	}
+}
+
+// Upstream 将金丝雀分组的请求转发到上游服务（如新版本的部署），其余请求继续执行后续处理函数
+//
+// 示例:
+//
+//	target, _ := url.Parse("http://orders-v2.internal:8080")
+//	router.Use(canary.NewBuilder(5).Build(), canary.Upstream(target))
+func Upstream(target *url.URL) gin.HandlerFunc {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	return func(c *gin.Context) {
+		if !IsCanary(c) {
+			c.Next()
+			return
+		}
+		proxy.ServeHTTP(c.Writer, c.Request)
+		c.Abort()
+	}
+}
@@ -19,9 +19,15 @@
 package cors
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 )
@@ -30,22 +36,22 @@ import (
 type Config struct {
 	// AllowOrigins 允许的源列表，如 ["http://localhost:3000", "https://example.com"]
 	// 使用 "*" 表示允许所有源（不推荐用于生产环境）
-	AllowOrigins []string
+	AllowOrigins []string `json:"allow_origins"`
 
 	// AllowMethods 允许的 HTTP 方法
-	AllowMethods []string
+	AllowMethods []string `json:"allow_methods"`
 
 	// AllowHeaders 允许的请求头
-	AllowHeaders []string
+	AllowHeaders []string `json:"allow_headers"`
 
 	// ExposeHeaders 暴露给客户端的响应头
-	ExposeHeaders []string
+	ExposeHeaders []string `json:"expose_headers"`
 
 	// AllowCredentials 是否允许携带凭证（Cookie、HTTP 认证等）
-	AllowCredentials bool
+	AllowCredentials bool `json:"allow_credentials"`
 
 	// MaxAge 预检请求的缓存时间（秒）
-	MaxAge int
+	MaxAge int `json:"max_age"`
 }
 
 // DefaultConfig 返回默认的 CORS 配置
@@ -60,73 +66,154 @@ func DefaultConfig() Config {
 	}
 }
 
-// New 创建 CORS 中间件
-func New(config Config) gin.HandlerFunc {
-	// 如果没有配置，使用默认配置
+// Validate 校验配置，用于运行时更新前检查
+// 必须配置允许的源，避免更新时遗漏 allow_origins 退化为默认配置的 "*"，静默放开所有源；
+// 源必须为 "*" 或 scheme://host[:port] 格式；允许携带凭证时不能允许所有源（浏览器会拒绝）
+func (config Config) Validate() error {
 	if len(config.AllowOrigins) == 0 {
-		config = DefaultConfig()
+		return errors.New("allow_origins 不能为空，允许所有源时需要显式配置为 [\"*\"]")
 	}
-
-	// 预处理配置
-	allowAllOrigins := false
 	for _, origin := range config.AllowOrigins {
 		if origin == "*" {
-			allowAllOrigins = true
-			break
+			if config.AllowCredentials {
+				return errors.New("允许携带凭证时不能使用 * 允许所有源")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return fmt.Errorf("源 %q 格式不正确，应为 scheme://host[:port]", origin)
 		}
 	}
+	if config.MaxAge < 0 {
+		return errors.New("max_age 不能小于 0")
+	}
+	return nil
+}
 
-	allowMethodsStr := strings.Join(config.AllowMethods, ", ")
-	allowHeadersStr := strings.Join(config.AllowHeaders, ", ")
-	exposeHeadersStr := strings.Join(config.ExposeHeaders, ", ")
+// policy 预处理后的配置
+type policy struct {
+	config           Config
+	allowAllOrigins  bool
+	allowMethodsStr  string
+	allowHeadersStr  string
+	exposeHeadersStr string
+}
 
-	return func(c *gin.Context) {
-		origin := c.Request.Header.Get("Origin")
-
-		// 设置 Access-Control-Allow-Origin
-		if allowAllOrigins {
-			c.Header("Access-Control-Allow-Origin", "*")
-		} else if origin != "" {
-			// 检查 origin 是否在允许列表中
-			for _, allowedOrigin := range config.AllowOrigins {
-				if origin == allowedOrigin {
-					c.Header("Access-Control-Allow-Origin", origin)
-					break
-				}
+// newPolicy 预处理配置，没有配置允许的源时使用默认配置
+func newPolicy(config Config) *policy {
+	if len(config.AllowOrigins) == 0 {
+		config = DefaultConfig()
+	}
+	return &policy{
+		config:           config,
+		allowAllOrigins:  slices.Contains(config.AllowOrigins, "*"),
+		allowMethodsStr:  strings.Join(config.AllowMethods, ", "),
+		allowHeadersStr:  strings.Join(config.AllowHeaders, ", "),
+		exposeHeadersStr: strings.Join(config.ExposeHeaders, ", "),
+	}
+}
+
+// New 创建 CORS 中间件
+func New(config Config) gin.HandlerFunc {
+	p := newPolicy(config)
+	return p.handle
+}
+
+// handle 设置 CORS 响应头并处理预检请求
+func (p *policy) handle(c *gin.Context) {
+	config := p.config
+	origin := c.Request.Header.Get("Origin")
+
+	// 设置 Access-Control-Allow-Origin
+	if p.allowAllOrigins {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else if origin != "" {
+		// 检查 origin 是否在允许列表中
+		for _, allowedOrigin := range config.AllowOrigins {
+			if origin == allowedOrigin {
+				c.Header("Access-Control-Allow-Origin", origin)
+				break
 			}
 		}
+	}
 
-		// 设置其他 CORS 头
-		if len(config.AllowMethods) > 0 {
-			c.Header("Access-Control-Allow-Methods", allowMethodsStr)
-		}
+	// 设置其他 CORS 头
+	if len(config.AllowMethods) > 0 {
+		c.Header("Access-Control-Allow-Methods", p.allowMethodsStr)
+	}
 
-		if len(config.AllowHeaders) > 0 {
-			c.Header("Access-Control-Allow-Headers", allowHeadersStr)
-		}
+	if len(config.AllowHeaders) > 0 {
+		c.Header("Access-Control-Allow-Headers", p.allowHeadersStr)
+	}
 
-		if len(config.ExposeHeaders) > 0 {
-			c.Header("Access-Control-Expose-Headers", exposeHeadersStr)
-		}
+	if len(config.ExposeHeaders) > 0 {
+		c.Header("Access-Control-Expose-Headers", p.exposeHeadersStr)
+	}
 
-		if config.AllowCredentials {
-			c.Header("Access-Control-Allow-Credentials", "true")
-		}
+	if config.AllowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
 
-		// 处理预检请求
-		if c.Request.Method == "OPTIONS" {
-			if config.MaxAge > 0 {
-				c.Header("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
-			}
-			c.AbortWithStatus(http.StatusNoContent)
-			return
+	// 处理预检请求
+	if c.Request.Method == "OPTIONS" {
+		if config.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
 		}
-
-		c.Next()
+		c.AbortWithStatus(http.StatusNoContent)
+		return
 	}
+
+	c.Next()
 }
 
 // Default 返回使用默认配置的 CORS 中间件
 func Default() gin.HandlerFunc {
 	return New(DefaultConfig())
 }
+
+// Reloadable 支持运行时更新配置的 CORS 中间件
+// 配置整体原子替换，正在处理的请求使用旧配置或新配置中的一份，不会混用
+type Reloadable struct {
+	current atomic.Pointer[policy]
+}
+
+// NewReloadable 创建支持运行时更新配置的 CORS 中间件
+func NewReloadable(config Config) *Reloadable {
+	r := &Reloadable{}
+	r.current.Store(newPolicy(config))
+	return r
+}
+
+// Handler 返回中间件
+func (r *Reloadable) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r.current.Load().handle(c)
+	}
+}
+
+// Config 返回当前配置
+func (r *Reloadable) Config() Config {
+	return r.current.Load().config
+}
+
+// Update 校验并替换配置，校验失败时保留原配置
+func (r *Reloadable) Update(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	r.current.Store(newPolicy(config))
+	return nil
+}
+
+// Prepare 解析并校验 JSON 配置，返回应用配置的函数，供 reload.Watcher 使用
+func (r *Reloadable) Prepare(raw json.RawMessage) (func(), error) {
+	var config Config
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("解析 CORS 配置失败: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return func() { r.current.Store(newPolicy(config)) }, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csrf 校验非安全方法请求携带的 CSRF Token 是否与当前会话中保存的一致
+// Token 由 session.CSRFToken 签发并随 Session 存储，每个会话一个，登录创建新会话时自然更换
+package csrf
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/errpage"
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/session"
+)
+
+// SkipFunc 判断请求是否跳过 CSRF 校验
+type SkipFunc func(c *gin.Context) bool
+
+// Builder CSRF 中间件构建器
+type Builder struct {
+	header    string
+	formField string
+	skip      SkipFunc
+}
+
+// NewBuilder 创建 CSRF 中间件构建器
+// 默认从请求头 X-CSRF-Token 读取 Token，请求头为空时读取表单字段 _csrf；不携带 Cookie 的请求跳过校验
+func NewBuilder() *Builder {
+	return &Builder{
+		header:    "X-CSRF-Token",
+		formField: "_csrf",
+		skip:      NoCookie,
+	}
+}
+
+// NoCookie 默认的跳过规则：请求不携带 Cookie 时跳过校验
+// 浏览器只会在跨站请求中自动携带 Cookie，通过 Authorization 等请求头携带会话的 API 客户端不受 CSRF 影响
+func NoCookie(c *gin.Context) bool {
+	return c.GetHeader("Cookie") == ""
+}
+
+// WithSkip 设置跳过校验的规则，替换默认的 NoCookie，传入 nil 表示所有存在会话的请求都校验
+//
+// 示例（同时携带了 Cookie 的 Bearer 请求也跳过）:
+//
+//	csrf.NewBuilder().WithSkip(func(c *gin.Context) bool {
+//	   return csrf.NoCookie(c) || strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ")
+//	})
+func (b *Builder) WithSkip(fn SkipFunc) *Builder {
+	b.skip = fn
+	return b
+}
+
+// WithHeader 设置携带 Token 的请求头名称，传入空字符串表示不从请求头读取
+func (b *Builder) WithHeader(name string) *Builder {
+	b.header = name
+	return b
+}
+
+// WithFormField 设置携带 Token 的表单字段名称，传入空字符串表示不从表单读取
+func (b *Builder) WithFormField(name string) *Builder {
+	b.formField = name
+	return b
+}
+
+// Build 构建中间件
+// GET、HEAD、OPTIONS、TRACE 请求和跳过规则命中的请求直接放行；其余请求在存在会话时校验 Token，不一致时响应 403
+// 没有会话的请求（如未登录、登录接口本身）直接放行，由后续的认证逻辑处理
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if safeMethod(c.Request.Method) || (b.skip != nil && b.skip(c)) {
+			c.Next()
+			return
+		}
+		ctx := &gctx.Context{Context: c}
+		sess, err := session.Get(ctx)
+		if err != nil {
+			c.Next()
+			return
+		}
+		if err := session.VerifyCSRF(ctx, sess, b.token(c)); err != nil {
+			c.Abort()
+			if errpage.Render(c, errpage.Page{Status: http.StatusForbidden, Code: http.StatusForbidden, Msg: "CSRF Token 无效"}) {
+				return
+			}
+			c.JSON(http.StatusForbidden, gin.H{"code": http.StatusForbidden, "msg": "CSRF Token 无效", "data": nil})
+			return
+		}
+		c.Next()
+	}
+}
+
+// token 读取请求携带的 Token，请求头优先
+func (b *Builder) token(c *gin.Context) string {
+	if b.header != "" {
+		if token := c.GetHeader(b.header); token != "" {
+			return token
+		}
+	}
+	if b.formField != "" {
+		return c.PostForm(b.formField)
+	}
+	return ""
+}
+
+// safeMethod 判断是否为不修改状态的请求方法
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ink-code/gint/middlewares/unavailable"
+)
+
+const (
+	// Header 传递剩余超时预算的请求头
+	Header = "X-Request-Timeout"
+	// GRPCHeader gRPC 风格的超时请求头，未设置 Header 时读取
+	GRPCHeader = "Grpc-Timeout"
+)
+
+// errInvalidTimeout 超时请求头格式错误
+var errInvalidTimeout = errors.New("invalid timeout")
+
+// Builder 超时预算中间件构建器
+type Builder struct {
+	max        time.Duration // 允许的最大超时时间
+	defaultVal time.Duration // 未携带超时请求头时的超时时间，0 表示不设置
+}
+
+// NewBuilder 创建超时预算中间件构建器
+// max: 允许的最大超时时间，调用方请求的超时时间超过 max 时按 max 处理
+func NewBuilder(max time.Duration) *Builder {
+	return &Builder{max: max}
+}
+
+// WithDefault 设置未携带超时请求头时的超时时间，默认不设置
+func (b *Builder) WithDefault(timeout time.Duration) *Builder {
+	b.defaultVal = timeout
+	return b
+}
+
+// Build 构建中间件
+// 读取调用方的剩余超时预算，限制在 max 以内后设置为请求 Context 的截止时间；
+// 预算已经耗尽的请求直接返回 504，不再执行后续处理
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout, ok := b.timeout(c.Request)
+		if !ok {
+			c.Next()
+			return
+		}
+		if timeout <= 0 {
+			unavailable.Abort(c, http.StatusGatewayTimeout, unavailable.ReasonDeadlineExceeded, "", 0)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// timeout 计算请求的超时时间，返回 false 表示不设置
+// 格式错误的请求头按未携带处理
+func (b *Builder) timeout(r *http.Request) (time.Duration, bool) {
+	raw := r.Header.Get(Header)
+	if raw == "" {
+		raw = r.Header.Get(GRPCHeader)
+	}
+
+	timeout, err := Parse(raw)
+	if raw == "" || err != nil {
+		timeout = b.defaultVal
+		if timeout <= 0 {
+			return 0, false
+		}
+	}
+	if b.max > 0 && timeout > b.max {
+		timeout = b.max
+	}
+	return timeout, true
+}
+
+// Parse 解析超时请求头
+// 支持 gRPC 风格（如 100m、5S，单位 H/M/S/m/u/n）、Go 风格（如 1.5s、300ms）和不带单位的毫秒数
+// 注意 m 按 gRPC 风格表示毫秒，分钟需要写作 M 或 1m0s
+func Parse(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, errInvalidTimeout
+	}
+	if ms, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	if unit, ok := grpcUnits[raw[len(raw)-1]]; ok {
+		if n, err := strconv.ParseInt(raw[:len(raw)-1], 10, 64); err == nil && len(raw) <= 9 {
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, errInvalidTimeout
+	}
+	return d, nil
+}
+
+// grpcUnits gRPC 超时单位
+var grpcUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// Format 将超时时间格式化为请求头的值（毫秒，gRPC 风格，如 1500m）
+// 不足 1 毫秒的部分向上取整，避免把剩余的少量预算传成 0
+func Format(timeout time.Duration) string {
+	ms := (timeout + time.Millisecond - 1) / time.Millisecond
+	return strconv.FormatInt(int64(ms), 10) + "m"
+}
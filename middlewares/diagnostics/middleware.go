@@ -0,0 +1,188 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostics
+
+import (
+	"math/rand/v2"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/internal/route"
+)
+
+const (
+	// CtxStatsKey 在 Context 中存储诊断数据的 key
+	CtxStatsKey = "gint:diagnostics"
+
+	metricAllocBytes   = "/gc/heap/allocs:bytes"
+	metricAllocObjects = "/gc/heap/allocs:objects"
+	metricGoroutines   = "/sched/goroutines:goroutines"
+)
+
+// Stats 单个请求的资源诊断数据
+// 注意：分配量来自进程级的 runtime/metrics 差值，并发请求会互相叠加，仅用于定位问题，不是精确计量
+type Stats struct {
+	Path           string `json:"path"`            // 路由模板（未匹配时为 unmatched）
+	AllocBytes     uint64 `json:"alloc_bytes"`     // 请求期间堆分配字节数
+	AllocObjects   uint64 `json:"alloc_objects"`   // 请求期间堆分配对象数
+	GoroutineStart uint64 `json:"goroutine_start"` // 请求开始时的协程数
+	GoroutinePeak  uint64 `json:"goroutine_peak"`  // 请求期间观测到的协程数峰值
+	Duration       int64  `json:"duration"`        // 处理时间（毫秒）
+}
+
+// LogFunc 诊断数据处理函数类型
+type LogFunc func(stats *Stats)
+
+// Builder 诊断中间件构建器
+type Builder struct {
+	logFunc        LogFunc       // 诊断数据处理函数
+	sampleRate     float64       // 采样率（0-1）
+	sampleInterval time.Duration // 协程数采样间隔
+}
+
+// defaultSampleInterval 默认的协程数采样间隔
+const defaultSampleInterval = 10 * time.Millisecond
+
+// NewBuilder 创建诊断中间件构建器
+// 默认采样率为 1%，协程数每 10 毫秒采样一次
+func NewBuilder(logFunc LogFunc) *Builder {
+	return &Builder{
+		logFunc:        logFunc,
+		sampleRate:     0.01,
+		sampleInterval: defaultSampleInterval,
+	}
+}
+
+// WithSampleRate 设置采样率（0-1），1 表示记录所有请求
+func (b *Builder) WithSampleRate(rate float64) *Builder {
+	b.sampleRate = rate
+	return b
+}
+
+// WithSampleInterval 设置请求期间协程数的采样间隔，不大于 0 时使用默认的 10 毫秒
+func (b *Builder) WithSampleInterval(interval time.Duration) *Builder {
+	if interval <= 0 {
+		interval = defaultSampleInterval
+	}
+	b.sampleInterval = interval
+	return b
+}
+
+// Build 构建中间件
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 未命中采样直接放行
+		if b.sampleRate <= 0 || rand.Float64() >= b.sampleRate {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		before := readSamples()
+
+		// 请求期间定期采样协程数，记录峰值
+		peak := newPeak(before[2].Value.Uint64())
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(b.sampleInterval)
+			defer ticker.Stop()
+			sample := []metrics.Sample{{Name: metricGoroutines}}
+			for {
+				select {
+				case <-ticker.C:
+					metrics.Read(sample)
+					peak.observe(sample[0].Value.Uint64())
+				case <-done:
+					return
+				}
+			}
+		}()
+		// 处理函数 panic 时同样停止采样协程
+		stopSampling := sync.OnceFunc(func() {
+			close(done)
+			wg.Wait()
+		})
+		defer stopSampling()
+
+		c.Next()
+
+		stopSampling()
+		after := readSamples()
+		peak.observe(after[2].Value.Uint64())
+
+		stats := &Stats{
+			Path:           route.Label(c),
+			AllocBytes:     after[0].Value.Uint64() - before[0].Value.Uint64(),
+			AllocObjects:   after[1].Value.Uint64() - before[1].Value.Uint64(),
+			GoroutineStart: before[2].Value.Uint64(),
+			GoroutinePeak:  peak.value,
+			Duration:       time.Since(start).Milliseconds(),
+		}
+
+		// 存入上下文，供 accesslog 等外层中间件一并记录
+		c.Set(CtxStatsKey, stats)
+
+		if b.logFunc != nil {
+			b.logFunc(stats)
+		}
+	}
+}
+
+// FromContext 获取当前请求的诊断数据
+// 仅在请求被采样且诊断中间件已执行完成后存在
+func FromContext(c *gin.Context) (*Stats, bool) {
+	val, exists := c.Get(CtxStatsKey)
+	if !exists {
+		return nil, false
+	}
+	stats, ok := val.(*Stats)
+	return stats, ok
+}
+
+// readSamples 读取分配量和协程数指标
+func readSamples() []metrics.Sample {
+	samples := []metrics.Sample{
+		{Name: metricAllocBytes},
+		{Name: metricAllocObjects},
+		{Name: metricGoroutines},
+	}
+	metrics.Read(samples)
+	return samples
+}
+
+// peak 并发安全的峰值记录
+type peak struct {
+	mu    sync.Mutex
+	value uint64
+}
+
+func newPeak(initial uint64) *peak {
+	return &peak{value: initial}
+}
+
+// observe 记录一次观测值
+func (p *peak) observe(v uint64) {
+	p.mu.Lock()
+	if v > p.value {
+		p.value = v
+	}
+	p.mu.Unlock()
+}
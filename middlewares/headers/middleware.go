@@ -0,0 +1,187 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package headers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// policyKey 路由组声明的响应头策略在 Context 中的键
+	policyKey = "gint:headers"
+	// installedKey 标记已注册 Build 中间件
+	installedKey = "gint:headers_installed"
+)
+
+// Policy 响应头策略，键为响应头名称
+// 值为空字符串表示取消外层路由组（或默认策略）声明的同名响应头
+type Policy map[string]string
+
+// Set 声明单个响应头，如自定义的 X- 响应头
+func Set(key, value string) Policy {
+	return Policy{key: value}
+}
+
+// NoStore 禁止缓存，适用于包含用户数据的接口
+func NoStore() Policy {
+	return Policy{"Cache-Control": "no-store"}
+}
+
+// Cache 允许浏览器和 CDN 等共享缓存缓存 maxAge
+func Cache(maxAge time.Duration) Policy {
+	return Policy{"Cache-Control": "public, max-age=" + seconds(maxAge)}
+}
+
+// PrivateCache 只允许浏览器缓存 maxAge，共享缓存不缓存
+func PrivateCache(maxAge time.Duration) Policy {
+	return Policy{"Cache-Control": "private, max-age=" + seconds(maxAge)}
+}
+
+// Security 常用的安全响应头：禁止 MIME 嗅探、禁止被嵌入 iframe、跨域时只发送源作为 Referer
+func Security() Policy {
+	return Policy{
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        "DENY",
+		"Referrer-Policy":        "strict-origin-when-cross-origin",
+	}
+}
+
+// HSTS 要求浏览器在 maxAge 内只通过 HTTPS 访问
+func HSTS(maxAge time.Duration, includeSubDomains bool) Policy {
+	value := "max-age=" + seconds(maxAge)
+	if includeSubDomains {
+		value += "; includeSubDomains"
+	}
+	return Policy{"Strict-Transport-Security": value}
+}
+
+// seconds 将时长转换为秒数字符串
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// merge 按顺序合并策略，后面的覆盖前面的，响应头名称统一为规范格式
+func merge(dst Policy, policies ...Policy) Policy {
+	for _, p := range policies {
+		for k, v := range p {
+			dst[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	return dst
+}
+
+// Declare 为路由组或单个路由声明响应头策略
+// 嵌套的路由组依次声明时，内层的同名响应头覆盖外层；响应头由 Build 构建的中间件在输出响应时统一设置
+//
+// 示例:
+//
+//	api := r.Group("/api", headers.Declare(headers.NoStore()))
+//	static := r.Group("/assets", headers.Declare(headers.Cache(24*time.Hour)))
+func Declare(policies ...Policy) gin.HandlerFunc {
+	declared := merge(Policy{}, policies...)
+	return func(c *gin.Context) {
+		if !c.GetBool(installedKey) {
+			// 没有注册 Build 中间件时直接设置，不让安全响应头静默失效
+			fill(c.Writer.Header(), declared)
+			c.Next()
+			return
+		}
+		p := Policy{}
+		if outer, ok := c.Get(policyKey); ok {
+			merge(p, outer.(Policy))
+		}
+		c.Set(policyKey, merge(p, declared))
+		c.Next()
+	}
+}
+
+// fill 设置策略中的响应头，处理函数已经设置的响应头保持不变
+func fill(h http.Header, p Policy) {
+	for k, v := range p {
+		if v != "" && len(h.Values(k)) == 0 {
+			h.Set(k, v)
+		}
+	}
+}
+
+// Builder 响应头策略中间件构建器
+type Builder struct {
+	defaults Policy
+}
+
+// NewBuilder 创建响应头策略中间件构建器
+// defaults: 所有路由的默认策略，路由组通过 Declare 声明的同名响应头会覆盖默认策略
+func NewBuilder(defaults ...Policy) *Builder {
+	return &Builder{defaults: merge(Policy{}, defaults...)}
+}
+
+// Build 构建中间件，需要在 engine 上注册
+// 在响应头输出前合并默认策略和路由组声明的策略并设置，处理函数通过 c.Header 显式设置的响应头优先
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(installedKey, true)
+		w := &responseWriter{ResponseWriter: c.Writer, c: c, defaults: b.defaults}
+		c.Writer = w
+		c.Next()
+		// 没有写入响应体的请求（如 c.Status(204)）由 gin 在处理完成后直接输出，这里先设置响应头
+		w.apply()
+		c.Writer = w.ResponseWriter
+	}
+}
+
+// responseWriter 在第一次输出响应头之前设置策略中的响应头
+type responseWriter struct {
+	gin.ResponseWriter
+	c        *gin.Context
+	defaults Policy
+	applied  bool
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
+
+// apply 合并默认策略和路由组声明的策略并设置响应头，只执行一次
+func (w *responseWriter) apply() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+	p := w.defaults
+	if declared, ok := w.c.Get(policyKey); ok {
+		p = merge(merge(Policy{}, w.defaults), declared.(Policy))
+	}
+	fill(w.Header(), p)
+}
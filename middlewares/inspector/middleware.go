@@ -0,0 +1,331 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspector
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/internal/route"
+	"github.com/ink-code/gint/session"
+)
+
+// TokenHeader 访问检查页面时携带令牌的请求头，也可以使用查询参数 token
+const TokenHeader = "X-Inspector-Token"
+
+// Record 一次请求的记录
+type Record struct {
+	ID       uint64    `json:"id"`
+	Time     time.Time `json:"time"`     // 请求开始时间
+	Method   string    `json:"method"`   // HTTP 方法
+	Path     string    `json:"path"`     // 请求路径
+	Query    string    `json:"query"`    // 查询参数
+	Route    string    `json:"route"`    // 路由模板，未匹配路由时为 unmatched
+	IP       string    `json:"ip"`       // 客户端 IP
+	Status   int       `json:"status"`   // HTTP 状态码
+	Duration float64   `json:"duration"` // 处理时间（毫秒）
+	Error    string    `json:"error,omitempty"`
+
+	ReqHeader  http.Header `json:"req_header,omitempty"`  // 请求头
+	ReqBody    string      `json:"req_body,omitempty"`    // 请求体，超过长度限制时截断
+	RespHeader http.Header `json:"resp_header,omitempty"` // 响应头
+	RespBody   string      `json:"resp_body,omitempty"`   // 响应体，超过长度限制时截断
+
+	// Claims 处理过程中校验过的会话 Claims，未登录或处理函数没有读取会话时为 nil
+	Claims *session.Claims `json:"claims,omitempty"`
+}
+
+// summary 列表中展示的摘要，不含请求头和请求体
+func (r *Record) summary() gin.H {
+	return gin.H{
+		"id":       r.ID,
+		"time":     r.Time,
+		"method":   r.Method,
+		"path":     r.Path,
+		"route":    r.Route,
+		"status":   r.Status,
+		"duration": r.Duration,
+	}
+}
+
+// Builder 请求检查中间件构建器
+type Builder struct {
+	size          int    // 保留的请求数
+	path          string // 检查页面的路径前缀
+	token         string // 访问令牌，为空时只允许本机访问
+	maxBodyLength int    // 请求体、响应体的最大记录长度
+}
+
+// NewBuilder 创建请求检查中间件构建器，size 为保留的最近请求数，<= 0 时为 100
+// 默认检查页面位于 /_inspector，只允许本机直接访问，请求体和响应体最多记录 64KB
+// 注意：记录中包含完整的请求头、请求体和会话信息，只应在本地开发时使用
+func NewBuilder(size int) *Builder {
+	if size <= 0 {
+		size = 100
+	}
+	return &Builder{
+		size:          size,
+		path:          "/_inspector",
+		maxBodyLength: 64 << 10,
+	}
+}
+
+// WithPath 设置检查页面的路径前缀
+func (b *Builder) WithPath(path string) *Builder {
+	b.path = "/" + strings.Trim(path, "/")
+	return b
+}
+
+// WithToken 设置访问令牌，设置后任何来源的请求携带正确令牌即可访问，适用于在容器或虚拟机中开发
+func (b *Builder) WithToken(token string) *Builder {
+	b.token = token
+	return b
+}
+
+// WithMaxBodyLength 设置请求体、响应体的最大记录长度
+func (b *Builder) WithMaxBodyLength(length int) *Builder {
+	b.maxBodyLength = length
+	return b
+}
+
+// Build 构建中间件，应作为第一个中间件注册，以便记录其他中间件拒绝的请求
+// 访问检查页面的请求不会被记录
+// release 模式下必须通过 WithToken 设置访问令牌，否则 panic：反向代理与服务部署在同一台机器上时，所有请求的来源都是本机
+func (b *Builder) Build() gin.HandlerFunc {
+	if gin.Mode() == gin.ReleaseMode {
+		if b.token == "" {
+			panic("inspector: release 模式下必须通过 WithToken 设置访问令牌")
+		}
+		slog.Warn("请求检查中间件会记录完整的请求和会话信息，不应在生产环境中开启", slog.String("path", b.path))
+	}
+
+	ring := &ring{records: make([]*Record, b.size)}
+	return func(c *gin.Context) {
+		if p := c.Request.URL.Path; p == b.path || strings.HasPrefix(p, b.path+"/") {
+			b.serve(c, ring, strings.TrimPrefix(p, b.path))
+			return
+		}
+
+		rec := &Record{
+			Time:      time.Now(),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Query:     c.Request.URL.RawQuery,
+			IP:        c.ClientIP(),
+			ReqHeader: c.Request.Header.Clone(),
+		}
+
+		// 只读取记录长度内的请求体，其余部分留给处理函数继续读取
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			head, _ := io.ReadAll(io.LimitReader(c.Request.Body, int64(b.maxBodyLength)+1))
+			c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(head), c.Request.Body), c.Request.Body}
+			rec.ReqBody = b.text(head)
+		}
+
+		writer := &responseWriter{ResponseWriter: c.Writer, limit: b.maxBodyLength}
+		c.Writer = writer
+		c.Next()
+
+		rec.Route = route.Label(c)
+		rec.Status = c.Writer.Status()
+		rec.Duration = float64(time.Since(rec.Time).Microseconds()) / 1000
+		rec.RespHeader = c.Writer.Header().Clone()
+		rec.RespBody = b.text(writer.body.Bytes())
+		if writer.overflow {
+			rec.RespBody += "...(truncated)"
+		}
+		if len(c.Errors) > 0 {
+			rec.Error = c.Errors.String()
+		}
+		if val, ok := c.Get(session.CtxClaimsKey); ok {
+			rec.Claims, _ = val.(*session.Claims)
+		}
+		ring.add(rec)
+	}
+}
+
+// text 将请求体、响应体转为便于展示的文本
+func (b *Builder) text(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	truncated := len(body) > b.maxBodyLength
+	if truncated {
+		body = body[:b.maxBodyLength]
+	}
+	if !utf8.Valid(body) {
+		return "(二进制内容，" + strconv.Itoa(len(body)) + " 字节)"
+	}
+	if truncated {
+		return string(body) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// serve 处理检查页面的请求，sub 为去掉路径前缀后的路径
+//
+//	GET    {path}                 检查页面
+//	GET    {path}/api/requests      最近请求的摘要，最新的在前
+//	GET    {path}/api/requests/{id} 单个请求的完整记录
+//	DELETE {path}/api/requests      清空记录
+func (b *Builder) serve(c *gin.Context, ring *ring, sub string) {
+	c.Abort()
+	if !b.allowed(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "只允许本机或携带正确令牌访问"})
+		return
+	}
+
+	sub = strings.TrimSuffix(sub, "/")
+	switch {
+	case sub == "" && c.Request.Method == http.MethodGet:
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	case sub == "/api/requests" && c.Request.Method == http.MethodGet:
+		records := ring.list()
+		list := make([]gin.H, len(records))
+		for i, r := range records {
+			list[i] = r.summary()
+		}
+		c.JSON(http.StatusOK, list)
+	case sub == "/api/requests" && c.Request.Method == http.MethodDelete:
+		ring.clear()
+		c.Status(http.StatusNoContent)
+	case strings.HasPrefix(sub, "/api/requests/") && c.Request.Method == http.MethodGet:
+		id, _ := strconv.ParseUint(strings.TrimPrefix(sub, "/api/requests/"), 10, 64)
+		if r := ring.get(id); r != nil {
+			c.JSON(http.StatusOK, r)
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "记录不存在或已被覆盖"})
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	}
+}
+
+// allowed 检查是否允许访问检查页面
+// 设置了令牌时校验令牌，否则只允许直接来自本机的请求；
+// 带有代理头的请求经过了本机的反向代理（如 nginx、开发代理），实际来源未知，同样拒绝
+func (b *Builder) allowed(c *gin.Context) bool {
+	if b.token != "" {
+		token := c.GetHeader(TokenHeader)
+		if token == "" {
+			token = c.Query("token")
+		}
+		return subtle.ConstantTimeCompare([]byte(token), []byte(b.token)) == 1
+	}
+	for _, name := range []string{"X-Forwarded-For", "X-Real-Ip", "Forwarded"} {
+		if c.GetHeader(name) != "" {
+			return false
+		}
+	}
+	host, _, err := net.SplitHostPort(c.Request.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// ring 固定容量的环形缓冲区，写满后覆盖最早的记录
+type ring struct {
+	mu      sync.Mutex
+	records []*Record
+	next    int    // 下一个写入位置
+	seq     uint64 // 最后分配的 ID
+}
+
+func (r *ring) add(rec *Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	rec.ID = r.seq
+	r.records[r.next] = rec
+	r.next = (r.next + 1) % len(r.records)
+}
+
+// list 返回所有记录，最新的在前
+func (r *ring) list() []*Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]*Record, 0, len(r.records))
+	for i := 1; i <= len(r.records); i++ {
+		rec := r.records[(r.next-i+len(r.records))%len(r.records)]
+		if rec == nil {
+			break
+		}
+		list = append(list, rec)
+	}
+	return list
+}
+
+func (r *ring) get(id uint64) *Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rec := range r.records {
+		if rec != nil && rec.ID == id {
+			return rec
+		}
+	}
+	return nil
+}
+
+func (r *ring) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.records)
+	r.next = 0
+}
+
+// readCloser 读取已缓冲的开头和剩余的请求体，关闭时关闭原始请求体
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseWriter 在输出的同时记录响应体，超过长度限制的部分只输出不记录
+type responseWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseWriter) capture(data []byte) {
+	if room := w.limit - w.body.Len(); room < len(data) {
+		data = data[:max(room, 0)]
+		w.overflow = true
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inspector
+
+// page 检查页面，通过 JSON 接口读取记录
+const page = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>请求检查</title>
+<style>
+body { margin: 0; font: 13px/1.5 -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; display: flex; height: 100vh; }
+#list { width: 45%; overflow: auto; border-right: 1px solid #ddd; }
+#detail { flex: 1; overflow: auto; padding: 0 16px; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 4px 8px; text-align: left; border-bottom: 1px solid #eee; white-space: nowrap; }
+tr.row { cursor: pointer; }
+tr.row:hover, tr.active { background: #f0f6ff; }
+.s4 { color: #c77700; } .s5 { color: #d32f2f; }
+pre { background: #f7f7f7; padding: 8px; white-space: pre-wrap; word-break: break-all; }
+header { padding: 8px; position: sticky; top: 0; background: #fff; border-bottom: 1px solid #ddd; }
+</style>
+</head>
+<body>
+<div id="list">
+<header><button onclick="load()">刷新</button> <button onclick="clearAll()">清空</button> <label><input type="checkbox" id="auto" checked> 自动刷新</label></header>
+<table><thead><tr><th>时间</th><th>方法</th><th>路径</th><th>状态</th><th>耗时</th></tr></thead><tbody id="rows"></tbody></table>
+</div>
+<div id="detail"><p>选择左侧的请求查看详情</p></div>
+<script>
+const base = location.pathname.replace(/\/$/, '');
+const token = new URLSearchParams(location.search).get('token');
+const opts = token ? { headers: { 'X-Inspector-Token': token } } : {};
+let active = 0;
+
+function esc(s) {
+  return String(s ?? '').replace(/[&<>"]/g, c => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;' })[c]);
+}
+
+function pretty(body) {
+  try { return JSON.stringify(JSON.parse(body), null, 2); } catch (e) { return body; }
+}
+
+function headers(h) {
+  return Object.entries(h || {}).map(([k, v]) => k + ': ' + v.join(', ')).join('\n');
+}
+
+async function load() {
+  const res = await fetch(base + '/api/requests', opts);
+  const list = await res.json();
+  document.getElementById('rows').innerHTML = list.map(r =>
+    '<tr class="row' + (r.id === active ? ' active' : '') + '" onclick="show(' + r.id + ')">' +
+    '<td>' + esc(new Date(r.time).toLocaleTimeString()) + '</td><td>' + esc(r.method) + '</td>' +
+    '<td>' + esc(r.path) + '</td><td class="s' + String(r.status)[0] + '">' + r.status + '</td>' +
+    '<td>' + r.duration.toFixed(1) + 'ms</td></tr>').join('');
+}
+
+async function show(id) {
+  active = id;
+  const res = await fetch(base + '/api/requests/' + id, opts);
+  const r = await res.json();
+  if (!res.ok) { document.getElementById('detail').innerHTML = '<p>' + esc(r.error) + '</p>'; return; }
+  document.getElementById('detail').innerHTML =
+    '<h3>' + esc(r.method + ' ' + r.path + (r.query ? '?' + r.query : '')) + '</h3>' +
+    '<p>路由 ' + esc(r.route) + ' · 状态 ' + r.status + ' · 耗时 ' + r.duration.toFixed(1) + 'ms · IP ' + esc(r.ip) + '</p>' +
+    (r.error ? '<h4>错误</h4><pre>' + esc(r.error) + '</pre>' : '') +
+    (r.claims ? '<h4>会话 Claims</h4><pre>' + esc(JSON.stringify(r.claims, null, 2)) + '</pre>' : '') +
+    '<h4>请求头</h4><pre>' + esc(headers(r.req_header)) + '</pre>' +
+    (r.req_body ? '<h4>请求体</h4><pre>' + esc(pretty(r.req_body)) + '</pre>' : '') +
+    '<h4>响应头</h4><pre>' + esc(headers(r.resp_header)) + '</pre>' +
+    (r.resp_body ? '<h4>响应体</h4><pre>' + esc(pretty(r.resp_body)) + '</pre>' : '');
+  load();
+}
+
+async function clearAll() {
+  await fetch(base + '/api/requests', Object.assign({ method: 'DELETE' }, opts));
+  active = 0;
+  document.getElementById('detail').innerHTML = '<p>选择左侧的请求查看详情</p>';
+  load();
+}
+
+setInterval(() => { if (document.getElementById('auto').checked) load(); }, 2000);
+load();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,151 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipfilter 按客户端 IP 的白名单和黑名单控制访问，名单可以在运行时更新
+package ipfilter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/errpage"
+	"github.com/ink-code/gint/internal/clientip"
+)
+
+// rules 解析后的名单
+type rules struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// listConfig 名单的 JSON 配置，如 {"allow": ["10.0.0.0/8"], "deny": ["10.0.0.5"]}
+// 两个键都必须出现，字段为指针以区分缺省和空列表
+type listConfig struct {
+	Allow *[]string `json:"allow"`
+	Deny  *[]string `json:"deny"`
+}
+
+// Builder IP 过滤中间件构建器
+// 名单保存在 Builder 中，同一个 Builder 多次 Build 的中间件共享名单，Update 后同时生效
+type Builder struct {
+	current  atomic.Pointer[rules]
+	resolver *clientip.Resolver // 为 nil 时使用连接的对端地址
+}
+
+// NewBuilder 创建 IP 过滤中间件构建器
+// allow 为空时允许所有不在 deny 中的 IP；名单项可以是单个 IP（如 10.0.0.5）或网段（如 10.0.0.0/8）
+// 名单项格式错误时 panic
+func NewBuilder(allow, deny []string) *Builder {
+	b := &Builder{}
+	if err := b.Update(allow, deny); err != nil {
+		panic(fmt.Sprintf("ipfilter: %v", err))
+	}
+	return b
+}
+
+// WithTrustedProxies 设置可信代理，服务部署在反向代理之后时使用
+// 只有连接的对端是可信代理时才从 X-Forwarded-For 中读取客户端 IP，默认只使用对端地址，避免客户端伪造请求头绕过名单
+// 代理地址格式错误时 panic
+func (b *Builder) WithTrustedProxies(proxies ...string) *Builder {
+	resolver, err := clientip.New(proxies)
+	if err != nil {
+		panic(fmt.Sprintf("ipfilter: %v", err))
+	}
+	b.resolver = resolver
+	return b
+}
+
+// Update 校验并替换名单，任意一项格式错误时保留原名单
+func (b *Builder) Update(allow, deny []string) error {
+	r, err := parseRules(allow, deny)
+	if err != nil {
+		return err
+	}
+	b.current.Store(r)
+	return nil
+}
+
+// Prepare 解析并校验 JSON 配置，返回应用配置的函数，供 reload.Watcher 使用
+// allow 和 deny 都必须显式配置，避免拼错或遗漏 allow 时退化为空白名单，静默放开受限的路由；不限制时配置为 []
+func (b *Builder) Prepare(raw json.RawMessage) (func(), error) {
+	var cfg listConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("解析 IP 名单失败: %w", err)
+	}
+	if cfg.Allow == nil {
+		return nil, errors.New("allow 不能缺省，允许所有 IP 时需要显式配置为 []")
+	}
+	if cfg.Deny == nil {
+		return nil, errors.New("deny 不能缺省，没有黑名单时需要显式配置为 []")
+	}
+	r, err := parseRules(*cfg.Allow, *cfg.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return func() { b.current.Store(r) }, nil
+}
+
+// Build 构建中间件
+// 在黑名单中，或设置了白名单但不在白名单中的 IP 响应 403
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		addr := b.resolver.IP(c.Request)
+		if !addr.IsValid() || !b.current.Load().allowed(addr) {
+			c.Abort()
+			if errpage.Render(c, errpage.Page{Status: http.StatusForbidden, Code: http.StatusForbidden, Msg: "禁止访问"}) {
+				return
+			}
+			c.JSON(http.StatusForbidden, gin.H{"code": http.StatusForbidden, "msg": "禁止访问", "data": nil})
+			return
+		}
+		c.Next()
+	}
+}
+
+// allowed 判断 IP 是否允许访问，黑名单优先
+func (r *rules) allowed(addr netip.Addr) bool {
+	for _, p := range r.deny {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	if len(r.allow) == 0 {
+		return true
+	}
+	for _, p := range r.allow {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRules 解析名单
+func parseRules(allow, deny []string) (*rules, error) {
+	r := &rules{}
+	var err error
+	if r.allow, err = clientip.ParsePrefixes(allow); err != nil {
+		return nil, err
+	}
+	if r.deny, err = clientip.ParsePrefixes(deny); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,292 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Target 影子流量的接收方
+// 接收的是复制后的独立请求，可以安全地读取请求体
+type Target interface {
+	// Serve 处理影子请求，返回影子响应的状态码
+	Serve(req *http.Request) (int, error)
+}
+
+// Result 单次影子请求的结果
+type Result struct {
+	Method   string        // HTTP 方法
+	Path     string        // 请求路径
+	Status   int           // 影子响应状态码
+	Primary  int           // 主响应状态码
+	Duration time.Duration // 影子请求耗时
+	Err      error         // 影子请求错误
+}
+
+// ResultFunc 影子请求结果处理函数类型，用于对比主响应和影子响应
+type ResultFunc func(result *Result)
+
+// Builder 流量镜像中间件构建器
+type Builder struct {
+	target      Target          // 影子流量接收方
+	sampleRate  float64         // 采样率（0-1）
+	maxBodySize int64           // 可镜像的最大请求体大小
+	maxInflight int             // 同时进行的最大影子请求数
+	timeout     time.Duration   // 单个影子请求超时时间
+	resultFunc  ResultFunc      // 结果处理函数
+	methods     map[string]bool // 允许镜像的 HTTP 方法
+	credentials bool            // 是否保留 Cookie、Authorization 等凭证请求头
+}
+
+// credentialHeaders 默认从影子请求中移除的凭证请求头
+var credentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// NewBuilder 创建流量镜像中间件构建器
+// 默认采样率 10%，最大请求体 1MB，最多 100 个并发影子请求，超时 5 秒；
+// 只镜像 GET、HEAD 请求，并移除 Cookie、Authorization 等凭证请求头，避免在其他环境重放带凭证的写操作
+func NewBuilder(target Target) *Builder {
+	return &Builder{
+		target:      target,
+		sampleRate:  0.1,
+		maxBodySize: 1 << 20,
+		maxInflight: 100,
+		timeout:     5 * time.Second,
+		methods:     map[string]bool{http.MethodGet: true, http.MethodHead: true},
+	}
+}
+
+// WithMethods 设置允许镜像的 HTTP 方法，替换默认的 GET、HEAD
+// 镜像 POST、PUT、DELETE 等写操作前，需要确认影子环境的写入不会影响真实数据
+func (b *Builder) WithMethods(methods ...string) *Builder {
+	b.methods = make(map[string]bool, len(methods))
+	for _, m := range methods {
+		b.methods[strings.ToUpper(m)] = true
+	}
+	return b
+}
+
+// WithCredentials 设置是否在影子请求中保留 Cookie、Authorization 等凭证请求头，默认移除
+// 只在影子环境与主环境同样受信任时开启
+func (b *Builder) WithCredentials(keep bool) *Builder {
+	b.credentials = keep
+	return b
+}
+
+// WithSampleRate 设置采样率（0-1），1 表示镜像所有请求
+func (b *Builder) WithSampleRate(rate float64) *Builder {
+	b.sampleRate = rate
+	return b
+}
+
+// WithMaxBodySize 设置可镜像的最大请求体大小，超过该大小的请求不会被镜像
+func (b *Builder) WithMaxBodySize(size int64) *Builder {
+	b.maxBodySize = size
+	return b
+}
+
+// WithMaxInflight 设置同时进行的最大影子请求数，超出时丢弃新的镜像，避免拖垮主服务
+func (b *Builder) WithMaxInflight(n int) *Builder {
+	b.maxInflight = n
+	return b
+}
+
+// WithTimeout 设置单个影子请求的超时时间
+func (b *Builder) WithTimeout(timeout time.Duration) *Builder {
+	b.timeout = timeout
+	return b
+}
+
+// WithResultFunc 设置影子请求结果处理函数
+func (b *Builder) WithResultFunc(fn ResultFunc) *Builder {
+	b.resultFunc = fn
+	return b
+}
+
+// Build 构建中间件
+func (b *Builder) Build() gin.HandlerFunc {
+	inflight := make(chan struct{}, b.maxInflight)
+
+	return func(c *gin.Context) {
+		// 方法不在允许列表中或未命中采样直接放行
+		if !b.methods[c.Request.Method] || b.sampleRate <= 0 || rand.Float64() >= b.sampleRate {
+			c.Next()
+			return
+		}
+
+		// 复制请求体，超过大小限制时不镜像
+		var body []byte
+		if c.Request.Body != nil {
+			data, err := io.ReadAll(io.LimitReader(c.Request.Body, b.maxBodySize+1))
+			// 恢复请求体，以便后续处理
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), c.Request.Body))
+			if err != nil || int64(len(data)) > b.maxBodySize {
+				c.Next()
+				return
+			}
+			body = data
+		}
+
+		shadow := cloneRequest(c.Request, body)
+		if !b.credentials {
+			for _, name := range credentialHeaders {
+				shadow.Header.Del(name)
+			}
+		}
+
+		c.Next()
+
+		// 并发影子请求达到上限时丢弃
+		select {
+		case inflight <- struct{}{}:
+		default:
+			slog.Debug("影子请求过多，丢弃镜像", slog.String("path", shadow.URL.Path))
+			return
+		}
+
+		primary := c.Writer.Status()
+		go func() {
+			defer func() {
+				<-inflight
+				if r := recover(); r != nil {
+					slog.Error("影子请求发生 panic", slog.Any("panic", r))
+				}
+			}()
+			b.serve(shadow, primary)
+		}()
+	}
+}
+
+// serve 发送影子请求并回调结果
+func (b *Builder) serve(req *http.Request, primary int) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	start := time.Now()
+	status, err := b.target.Serve(req)
+	if err != nil {
+		slog.Debug("影子请求失败", slog.String("path", req.URL.Path), slog.Any("err", err))
+	}
+
+	if b.resultFunc != nil {
+		b.resultFunc(&Result{
+			Method:   req.Method,
+			Path:     req.URL.Path,
+			Status:   status,
+			Primary:  primary,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+	}
+}
+
+// cloneRequest 复制请求，影子请求与主请求的生命周期相互独立
+func cloneRequest(req *http.Request, body []byte) *http.Request {
+	shadow := req.Clone(context.Background())
+	shadow.Body = io.NopCloser(bytes.NewReader(body))
+	shadow.ContentLength = int64(len(body))
+	shadow.RequestURI = ""
+	shadow.Header.Set("X-Shadow-Request", "1")
+	return shadow
+}
+
+// upstreamTarget 转发到影子上游服务
+type upstreamTarget struct {
+	base   *url.URL
+	client *http.Client
+}
+
+// Upstream 创建转发到影子上游服务的 Target
+// baseURL: 影子服务地址，如 "http://user-service-v2:8080"，请求路径和查询参数保持不变
+// client: 为 nil 时使用 http.DefaultClient
+func Upstream(baseURL string, client *http.Client) (Target, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &upstreamTarget{base: base, client: client}, nil
+}
+
+// Serve 转发影子请求并丢弃响应体
+func (t *upstreamTarget) Serve(req *http.Request) (int, error) {
+	req.URL.Scheme = t.base.Scheme
+	req.URL.Host = t.base.Host
+	req.URL.Path = strings.TrimSuffix(t.base.Path, "/") + req.URL.Path
+	req.Host = t.base.Host
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// handlerTarget 交给进程内的新实现处理
+type handlerTarget struct {
+	handler http.Handler
+}
+
+// Handler 创建交给进程内 http.Handler（如新版本的 gin.Engine）处理的 Target
+func Handler(handler http.Handler) Target {
+	return &handlerTarget{handler: handler}
+}
+
+// Serve 调用 Handler 处理影子请求并丢弃响应
+func (t *handlerTarget) Serve(req *http.Request) (int, error) {
+	w := &discardWriter{header: make(http.Header)}
+	t.handler.ServeHTTP(w, req)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.status, nil
+}
+
+// discardWriter 只记录状态码的 ResponseWriter
+type discardWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return len(data), nil
+}
+
+func (w *discardWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package normalize 在路由匹配之前规范化请求路径，避免结尾斜杠、重复斜杠和大小写差异导致的 404
+package normalize
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Mode 规范化路径的处理方式
+type Mode int
+
+const (
+	// Rewrite 在服务内部改写路径后继续处理，客户端无感知
+	Rewrite Mode = iota
+	// Redirect 重定向到规范的路径，GET/HEAD 使用 301，其他方法使用 308 保留请求体
+	Redirect
+)
+
+// Builder 路径规范化构建器
+type Builder struct {
+	mode            Mode
+	trailingSlash   bool        // 去掉结尾的斜杠
+	collapseSlashes bool        // 合并重复的斜杠
+	engine          *gin.Engine // 不为 nil 时开启大小写不敏感的匹配
+
+	routesOnce sync.Once
+	routes     [][]string // 已注册路由的路径段
+}
+
+// NewBuilder 创建路径规范化构建器，默认使用 Rewrite 方式，不开启任何规范化
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// WithMode 设置处理方式，默认为 Rewrite
+func (b *Builder) WithMode(mode Mode) *Builder {
+	b.mode = mode
+	return b
+}
+
+// WithTrailingSlash 去掉路径结尾的斜杠，如 /users/ 规范化为 /users
+// 开启后应按不带结尾斜杠的路径注册路由
+func (b *Builder) WithTrailingSlash() *Builder {
+	b.trailingSlash = true
+	return b
+}
+
+// WithCollapseSlashes 合并重复的斜杠，如 //users///1 规范化为 /users/1
+func (b *Builder) WithCollapseSlashes() *Builder {
+	b.collapseSlashes = true
+	return b
+}
+
+// WithCaseInsensitive 开启大小写不敏感的路由匹配，用于兼容大小写不规范的旧客户端
+// 只有请求路径不能精确匹配任何路由、且忽略大小写后只匹配到一个路由时，才把静态部分改为注册时的写法，路径参数保持原样
+// engine 用于读取已注册的路由，路由在第一个请求到达时读取，之后注册的路由不参与匹配
+func (b *Builder) WithCaseInsensitive(engine *gin.Engine) *Builder {
+	b.engine = engine
+	return b
+}
+
+// Wrap 包装 handler，在路由匹配之前规范化路径
+//
+// 示例:
+//
+//	engine := gin.New()
+//	engine.GET("/users/:id", ...)
+//	h := normalize.NewBuilder().WithTrailingSlash().WithCollapseSlashes().WithCaseInsensitive(engine).Wrap(engine)
+//	http.ListenAndServe(":8080", h)
+func (b *Builder) Wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := b.normalize(r.URL.Path)
+		if path == r.URL.Path {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if b.mode == Redirect {
+			u := *r.URL
+			u.Path, u.RawPath = redirectPath(path), ""
+			status := http.StatusMovedPermanently
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				status = http.StatusPermanentRedirect
+			}
+			http.Redirect(w, r, u.RequestURI(), status)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		u := *r.URL
+		u.Path, u.RawPath = path, ""
+		r2.URL = &u
+		r2.RequestURI = u.RequestURI()
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// redirectPath 合并路径开头的斜杠和反斜杠
+// 以 // 或 /\ 开头的 Location 会被浏览器当作协议相对地址，重定向到其他站点
+func redirectPath(path string) string {
+	return "/" + strings.TrimLeft(path, "/\\")
+}
+
+// normalize 返回规范化后的路径
+func (b *Builder) normalize(path string) string {
+	if b.collapseSlashes && strings.Contains(path, "//") {
+		var sb strings.Builder
+		for i := 0; i < len(path); i++ {
+			if path[i] == '/' && i > 0 && path[i-1] == '/' {
+				continue
+			}
+			sb.WriteByte(path[i])
+		}
+		path = sb.String()
+	}
+	if b.trailingSlash && len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+		if path == "" {
+			path = "/"
+		}
+	}
+	if b.engine != nil {
+		path = b.matchCase(path)
+	}
+	return path
+}
+
+// matchCase 忽略大小写匹配已注册的路由，返回静态部分改为注册时写法的路径
+func (b *Builder) matchCase(path string) string {
+	b.routesOnce.Do(func() {
+		seen := make(map[string]bool)
+		for _, route := range b.engine.Routes() {
+			if seen[route.Path] {
+				continue
+			}
+			seen[route.Path] = true
+			b.routes = append(b.routes, strings.Split(route.Path, "/"))
+		}
+	})
+
+	segments := strings.Split(path, "/")
+	var match []string
+	for _, route := range b.routes {
+		if matchSegments(route, segments, false) {
+			// 能精确匹配时不改写
+			return path
+		}
+		if matchSegments(route, segments, true) {
+			if match != nil {
+				// 匹配到多个路由时不确定应该改写为哪一个
+				return path
+			}
+			match = route
+		}
+	}
+	if match == nil {
+		return path
+	}
+
+	for i, seg := range match {
+		if strings.HasPrefix(seg, "*") {
+			break
+		}
+		if !strings.HasPrefix(seg, ":") {
+			segments[i] = seg
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// matchSegments 判断请求路径段是否匹配路由路径段，:param 匹配任意一段，*param 匹配剩余部分
+func matchSegments(route, segments []string, fold bool) bool {
+	for i, seg := range route {
+		if strings.HasPrefix(seg, "*") {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if strings.HasPrefix(seg, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != segments[i] && (!fold || !strings.EqualFold(seg, segments[i])) {
+			return false
+		}
+	}
+	return len(route) == len(segments)
+}
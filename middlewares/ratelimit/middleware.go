@@ -20,13 +20,19 @@ package ratelimit
 
 import (
 	"fmt"
-	"net/http"
+	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/internal/supervisor"
+	"github.com/ink-code/gint/middlewares/unavailable"
 )
 
+// WarningHeader 超过软限流阈值时在响应中设置的响应头
+const WarningHeader = "X-RateLimit-Warning"
+
 // Limiter 限流器接口
 type Limiter interface {
 	// Allow 检查是否允许请求
@@ -35,13 +41,31 @@ type Limiter interface {
 	Allow(key string) bool
 }
 
+// RetryLimiter 拒绝请求时能给出重试等待时间的限流器
+// SimpleLimiter 和 SlidingWindowLimiter 均实现了该接口
+type RetryLimiter interface {
+	Limiter
+	// AllowRetry 检查是否允许请求，拒绝时返回距离下一次可以通过的等待时间
+	AllowRetry(key string) (allowed bool, retryAfter time.Duration)
+}
+
 // KeyFunc 生成限流键的函数类型
 type KeyFunc func(c *gin.Context) string
 
+// RejectFunc 请求被限流时的处理函数
+// retryAfter 为建议的重试等待时间，限流器未实现 RetryLimiter 时为 0
+type RejectFunc func(c *gin.Context, retryAfter time.Duration)
+
+// WarnFunc 请求超过软限流阈值时的回调，可用于记录日志或上报指标
+type WarnFunc func(c *gin.Context, key string)
+
 // Builder 限流中间件构建器
 type Builder struct {
-	limiter Limiter // 限流器
-	keyFunc KeyFunc // 生成限流键的函数
+	limiter  Limiter    // 限流器
+	keyFunc  KeyFunc    // 生成限流键的函数
+	onReject RejectFunc // 被限流时的处理函数
+	soft     Limiter    // 软限流器，为 nil 时不开启
+	onWarn   WarnFunc   // 超过软限流阈值时的回调
 }
 
 // NewBuilder 创建限流中间件构建器
@@ -52,7 +76,39 @@ func NewBuilder(limiter Limiter) *Builder {
 		keyFunc: func(c *gin.Context) string {
 			return "ip:" + c.ClientIP()
 		},
+		onReject: func(c *gin.Context, retryAfter time.Duration) {
+			unavailable.TooManyRequests(c, unavailable.ReasonRateLimited, retryAfter)
+		},
+	}
+}
+
+// WithRejectFunc 设置被限流时的处理函数
+// 默认返回 429 和统一的服务不可用响应，并在 Retry-After 响应头中给出重试等待秒数
+func (b *Builder) WithRejectFunc(fn RejectFunc) *Builder {
+	b.onReject = fn
+	return b
+}
+
+// WithSoftLimit 开启软限流：请求超过 soft 的阈值但未超过硬限流时照常放行，
+// 在响应头 X-RateLimit-Warning 中提示调用方，并调用 onWarn（为 nil 时记录一条警告日志）
+// soft 的阈值应低于构建器的限流器，两者使用相同的限流键，被硬限流拒绝的请求不计入软限流
+//
+// 示例:
+//
+//	ratelimit.NewBuilder(ratelimit.NewSlidingWindowLimiter(1000, time.Minute)).
+//	   WithSoftLimit(ratelimit.NewSlidingWindowLimiter(800, time.Minute), nil).
+//	   Build()
+func (b *Builder) WithSoftLimit(soft Limiter, onWarn WarnFunc) *Builder {
+	if onWarn == nil {
+		onWarn = func(c *gin.Context, key string) {
+			slog.Warn("请求超过软限流阈值",
+				slog.String("key", key),
+				slog.String("path", c.Request.URL.Path))
+		}
 	}
+	b.soft = soft
+	b.onWarn = onWarn
+	return b
 }
 
 // WithKeyFunc 设置自定义的限流键生成函数
@@ -81,24 +137,36 @@ func (b *Builder) Build() gin.HandlerFunc {
 		key := b.keyFunc(c)
 
 		// 检查是否允许请求
-		if !b.limiter.Allow(key) {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"code": 429,
-				"msg":  "请求过于频繁，请稍后再试",
-			})
+		var allowed bool
+		var retryAfter time.Duration
+		if rl, ok := b.limiter.(RetryLimiter); ok {
+			allowed, retryAfter = rl.AllowRetry(key)
+		} else {
+			allowed = b.limiter.Allow(key)
+		}
+		if !allowed {
+			b.onReject(c, retryAfter)
+			c.Abort()
 			return
 		}
 
+		// 超过软限流阈值时放行并提示
+		if b.soft != nil && !b.soft.Allow(key) {
+			c.Header(WarningHeader, "soft limit exceeded")
+			b.onWarn(c, key)
+		}
+
 		c.Next()
 	}
 }
 
 // SimpleLimiter 简单的内存限流器（基于固定窗口，并发安全）
 type SimpleLimiter struct {
-	rate     int           // 每个窗口允许的请求数
-	window   time.Duration // 窗口大小
-	counters sync.Map      // 并发安全的计数器 map[string]*counter
-	cleanup  time.Duration // 清理过期计数器的间隔
+	rate     int                    // 每个窗口允许的请求数
+	window   time.Duration          // 窗口大小
+	counters sync.Map               // 并发安全的计数器 map[string]*counter
+	cleanup  time.Duration          // 清理过期计数器的间隔
+	cleaner  *supervisor.Supervisor // 清理协程
 }
 
 type counter struct {
@@ -118,13 +186,19 @@ func NewSimpleLimiter(rate int, window time.Duration) *SimpleLimiter {
 	}
 
 	// 启动清理协程
-	go limiter.cleanupLoop()
+	limiter.cleaner = supervisor.Go("simple-limiter-cleaner", limiter.cleanupLoop)
 
 	return limiter
 }
 
 // Allow 检查是否允许请求（并发安全）
 func (l *SimpleLimiter) Allow(key string) bool {
+	allowed, _ := l.AllowRetry(key)
+	return allowed
+}
+
+// AllowRetry 检查是否允许请求，拒绝时返回距离当前窗口结束的时间
+func (l *SimpleLimiter) AllowRetry(key string) (bool, time.Duration) {
 	now := time.Now()
 
 	// 获取或创建计数器
@@ -142,25 +216,36 @@ func (l *SimpleLimiter) Allow(key string) bool {
 	if now.Sub(c.windowStart) >= l.window {
 		c.count = 1
 		c.windowStart = now
-		return true
+		return true, 0
 	}
 
 	// 检查是否超过限制
 	if c.count >= l.rate {
-		return false
+		return false, c.windowStart.Add(l.window).Sub(now)
 	}
 
 	// 增加计数
 	c.count++
-	return true
+	return true, 0
+}
+
+// Close 停止后台清理协程
+func (l *SimpleLimiter) Close() error {
+	return l.cleaner.Close()
 }
 
 // cleanupLoop 清理过期的计数器
-func (l *SimpleLimiter) cleanupLoop() {
+func (l *SimpleLimiter) cleanupLoop(stop <-chan struct{}) {
 	ticker := time.NewTicker(l.cleanup)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
 		now := time.Now()
 		l.counters.Range(func(key, value interface{}) bool {
 			c := value.(*counter)
@@ -200,10 +285,11 @@ func PathKeyFunc(c *gin.Context) string {
 
 // SlidingWindowLimiter 滑动窗口限流器
 type SlidingWindowLimiter struct {
-	rate     int           // 每个窗口允许的请求数
-	window   time.Duration // 窗口大小
-	counters sync.Map      // map[string]*slidingCounter
-	cleanup  time.Duration // 清理间隔
+	rate     int                    // 每个窗口允许的请求数
+	window   time.Duration          // 窗口大小
+	counters sync.Map               // map[string]*slidingCounter
+	cleanup  time.Duration          // 清理间隔
+	cleaner  *supervisor.Supervisor // 清理协程
 }
 
 type slidingCounter struct {
@@ -221,12 +307,18 @@ func NewSlidingWindowLimiter(rate int, window time.Duration) *SlidingWindowLimit
 		cleanup: window * 2,
 	}
 
-	go limiter.cleanupLoop()
+	limiter.cleaner = supervisor.Go("sliding-window-limiter-cleaner", limiter.cleanupLoop)
 	return limiter
 }
 
 // Allow 检查是否允许请求
 func (l *SlidingWindowLimiter) Allow(key string) bool {
+	allowed, _ := l.AllowRetry(key)
+	return allowed
+}
+
+// AllowRetry 检查是否允许请求，拒绝时返回距离最早一条请求记录过期的时间
+func (l *SlidingWindowLimiter) AllowRetry(key string) (bool, time.Duration) {
 	now := time.Now()
 
 	// 获取或创建计数器
@@ -250,20 +342,35 @@ func (l *SlidingWindowLimiter) Allow(key string) bool {
 
 	// 检查是否超过限制
 	if len(c.requests) >= l.rate {
-		return false
+		if len(c.requests) == 0 {
+			return false, l.window
+		}
+		// 最早的请求过期后腾出一个名额
+		return false, c.requests[len(c.requests)-l.rate].Add(l.window).Sub(now)
 	}
 
 	// 记录本次请求
 	c.requests = append(c.requests, now)
-	return true
+	return true, 0
+}
+
+// Close 停止后台清理协程
+func (l *SlidingWindowLimiter) Close() error {
+	return l.cleaner.Close()
 }
 
 // cleanupLoop 清理过期的计数器
-func (l *SlidingWindowLimiter) cleanupLoop() {
+func (l *SlidingWindowLimiter) cleanupLoop(stop <-chan struct{}) {
 	ticker := time.NewTicker(l.cleanup)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
 		now := time.Now()
 		cutoff := now.Add(-l.window * 2)
 
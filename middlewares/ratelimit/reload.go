@@ -0,0 +1,147 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LimiterFactory 按速率创建限流器，如 NewSlidingWindowLimiter
+type LimiterFactory func(rate int, window time.Duration) Limiter
+
+// ReloadableLimiter 支持运行时调整速率的限流器
+// 调整速率时创建新的限流器整体替换，已有的计数随之清零
+type ReloadableLimiter struct {
+	factory LimiterFactory
+	mu      sync.Mutex // 串行化 Update
+	current atomic.Pointer[reloadState]
+}
+
+// reloadState 当前的限流器及速率
+type reloadState struct {
+	limiter Limiter
+	rate    int
+	window  time.Duration
+}
+
+// rateConfig 速率的 JSON 配置，如 {"rate": 100, "window": "1m"}
+type rateConfig struct {
+	Rate   int    `json:"rate"`
+	Window string `json:"window"`
+}
+
+// NewReloadableLimiter 创建支持运行时调整速率的限流器
+//
+// 示例:
+//
+//	limiter := ratelimit.NewReloadableLimiter(func(rate int, window time.Duration) ratelimit.Limiter {
+//	   return ratelimit.NewSlidingWindowLimiter(rate, window)
+//	}, 100, time.Minute)
+//	router.Use(ratelimit.NewBuilder(limiter).Build())
+func NewReloadableLimiter(factory LimiterFactory, rate int, window time.Duration) *ReloadableLimiter {
+	l := &ReloadableLimiter{factory: factory}
+	l.current.Store(&reloadState{limiter: factory(rate, window), rate: rate, window: window})
+	return l
+}
+
+// Allow 检查是否允许请求
+func (l *ReloadableLimiter) Allow(key string) bool {
+	return l.current.Load().limiter.Allow(key)
+}
+
+// AllowRetry 检查是否允许请求，当前限流器未实现 RetryLimiter 时重试等待时间为 0
+func (l *ReloadableLimiter) AllowRetry(key string) (bool, time.Duration) {
+	limiter := l.current.Load().limiter
+	if rl, ok := limiter.(RetryLimiter); ok {
+		return rl.AllowRetry(key)
+	}
+	return limiter.Allow(key), 0
+}
+
+// Rate 返回当前速率
+func (l *ReloadableLimiter) Rate() (int, time.Duration) {
+	s := l.current.Load()
+	return s.rate, s.window
+}
+
+// Update 调整速率，速率不变时不替换限流器
+func (l *ReloadableLimiter) Update(rate int, window time.Duration) error {
+	if err := validateRate(rate, window); err != nil {
+		return err
+	}
+	l.apply(rate, window)
+	return nil
+}
+
+// Prepare 解析并校验 JSON 配置（如 {"rate": 100, "window": "1m"}），返回应用配置的函数，供 reload.Watcher 使用
+func (l *ReloadableLimiter) Prepare(raw json.RawMessage) (func(), error) {
+	var cfg rateConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("解析限流配置失败: %w", err)
+	}
+	window, err := time.ParseDuration(cfg.Window)
+	if err != nil {
+		return nil, fmt.Errorf("限流窗口 %q 格式不正确: %w", cfg.Window, err)
+	}
+	if err := validateRate(cfg.Rate, window); err != nil {
+		return nil, err
+	}
+	return func() { l.apply(cfg.Rate, window) }, nil
+}
+
+// Close 关闭当前的限流器
+func (l *ReloadableLimiter) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return closeLimiter(l.current.Load().limiter)
+}
+
+// apply 替换限流器并关闭旧的限流器
+func (l *ReloadableLimiter) apply(rate int, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	old := l.current.Load()
+	if old.rate == rate && old.window == window {
+		return
+	}
+	l.current.Store(&reloadState{limiter: l.factory(rate, window), rate: rate, window: window})
+	_ = closeLimiter(old.limiter)
+}
+
+// validateRate 校验速率
+func validateRate(rate int, window time.Duration) error {
+	if rate <= 0 {
+		return errors.New("限流速率必须大于 0")
+	}
+	if window <= 0 {
+		return errors.New("限流窗口必须大于 0")
+	}
+	return nil
+}
+
+// closeLimiter 关闭实现了 io.Closer 的限流器
+func closeLimiter(limiter Limiter) error {
+	if c, ok := limiter.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
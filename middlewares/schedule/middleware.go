@@ -0,0 +1,176 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schedule 按时间段控制路由的开放，用于限时活动、分接口的维护窗口等场景
+package schedule
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/errpage"
+	"github.com/ink-code/gint/middlewares/unavailable"
+)
+
+// 未开放的状态
+const (
+	// StateNotOpen 尚未开放
+	StateNotOpen = "not_open"
+	// StateClosed 已结束
+	StateClosed = "closed"
+)
+
+// Detail 响应中 data 字段的内容
+type Detail struct {
+	State      string     `json:"state"`              // 状态，StateNotOpen 或 StateClosed
+	ServerTime time.Time  `json:"server_time"`        // 服务器当前时间，客户端可据此校准倒计时
+	OpenAt     *time.Time `json:"open_at,omitempty"`  // 下一次开放的时间，尚未开放时有值
+	CloseAt    *time.Time `json:"close_at,omitempty"` // 最近一次结束的时间，已结束时有值
+}
+
+// Payload 未开放响应，与 gint.Result 的结构一致
+//
+//	{"code": 403, "msg": "活动尚未开始", "data": {"state": "not_open", "server_time": "...", "open_at": "..."}}
+type Payload struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data Detail `json:"data"`
+}
+
+// window 时间段，零值表示不限制
+type window struct {
+	start time.Time
+	end   time.Time
+}
+
+// contains 判断时间是否在时间段内（含开始时间，不含结束时间）
+func (w window) contains(t time.Time) bool {
+	return (w.start.IsZero() || !t.Before(w.start)) && (w.end.IsZero() || t.Before(w.end))
+}
+
+// Builder 时间段中间件构建器
+type Builder struct {
+	open        []window
+	maintenance []window
+	notOpenMsg  string
+	closedMsg   string
+	now         func() time.Time
+}
+
+// NewBuilder 创建时间段中间件构建器
+// 未设置开放时间段时路由始终开放，只受维护时间段约束
+func NewBuilder() *Builder {
+	return &Builder{
+		notOpenMsg: "尚未开放，请稍后再试",
+		closedMsg:  "已结束",
+		now:        time.Now,
+	}
+}
+
+// OpenBetween 添加开放时间段 [start, end)，可多次调用，处于任意一个时间段内即开放
+// start 或 end 为零值表示不限制开始或结束时间
+//
+// 示例:
+//
+//	router.POST("/flash-sale/orders",
+//	   schedule.NewBuilder().OpenBetween(saleStart, saleEnd).Build(),
+//	   gint.BS(createOrder))
+func (b *Builder) OpenBetween(start, end time.Time) *Builder {
+	b.open = append(b.open, window{start: start, end: end})
+	return b
+}
+
+// MaintenanceBetween 添加维护时间段 [start, end)，期间响应 503（见 unavailable 包），Retry-After 为距维护结束的时间
+func (b *Builder) MaintenanceBetween(start, end time.Time) *Builder {
+	b.maintenance = append(b.maintenance, window{start: start, end: end})
+	return b
+}
+
+// WithMessages 设置尚未开放和已结束时的提示信息，如 "活动尚未开始"、"活动已结束"
+func (b *Builder) WithMessages(notOpen, closed string) *Builder {
+	b.notOpenMsg = notOpen
+	b.closedMsg = closed
+	return b
+}
+
+// WithClock 设置获取当前时间的函数，默认为 time.Now
+func (b *Builder) WithClock(now func() time.Time) *Builder {
+	b.now = now
+	return b
+}
+
+// Build 构建中间件
+// 不在开放时间段内时响应 403，data 中包含状态、服务器时间和开放/结束时间
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := b.now()
+
+		for _, w := range b.maintenance {
+			if w.contains(now) {
+				var retryAfter time.Duration
+				if !w.end.IsZero() {
+					retryAfter = w.end.Sub(now)
+				}
+				unavailable.ServiceUnavailable(c, unavailable.ReasonMaintenance, retryAfter)
+				return
+			}
+		}
+
+		if len(b.open) == 0 {
+			c.Next()
+			return
+		}
+		for _, w := range b.open {
+			if w.contains(now) {
+				c.Next()
+				return
+			}
+		}
+		p := b.reject(now)
+		c.Abort()
+		if errpage.Render(c, errpage.Page{Status: http.StatusForbidden, Code: p.Code, Msg: p.Msg}) {
+			return
+		}
+		c.JSON(http.StatusForbidden, p)
+	}
+}
+
+// reject 生成未开放响应，存在尚未开始的时间段时为尚未开放，否则为已结束
+func (b *Builder) reject(now time.Time) Payload {
+	var openAt, closeAt time.Time
+	for _, w := range b.open {
+		if now.Before(w.start) {
+			if openAt.IsZero() || w.start.Before(openAt) {
+				openAt = w.start
+			}
+		} else if w.end.After(closeAt) {
+			closeAt = w.end
+		}
+	}
+
+	if !openAt.IsZero() {
+		return Payload{
+			Code: http.StatusForbidden,
+			Msg:  b.notOpenMsg,
+			Data: Detail{State: StateNotOpen, ServerTime: now, OpenAt: &openAt},
+		}
+	}
+	return Payload{
+		Code: http.StatusForbidden,
+		Msg:  b.closedMsg,
+		Data: Detail{State: StateClosed, ServerTime: now, CloseAt: &closeAt},
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slo
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/internal/route"
+)
+
+// Objective SLO 目标
+type Objective struct {
+	Availability float64       // 成功率目标，如 0.999
+	Latency      time.Duration // 延迟阈值，0 表示不考核延迟
+	LatencyRatio float64       // 需要在 Latency 内完成的请求比例，如 0.99
+}
+
+// Report 单个路由在滚动窗口内的 SLO 统计
+type Report struct {
+	Route           string        `json:"route"`             // 路由模板（如 /users/:id）
+	Total           uint64        `json:"total"`             // 窗口内请求数
+	Errors          uint64        `json:"errors"`            // 窗口内失败请求数
+	Slow            uint64        `json:"slow"`              // 窗口内超过延迟阈值的请求数
+	SuccessRatio    float64       `json:"success_ratio"`     // 成功率
+	P50             time.Duration `json:"p50"`               // 延迟 P50（按直方图桶上界估算）
+	P90             time.Duration `json:"p90"`               // 延迟 P90
+	P99             time.Duration `json:"p99"`               // 延迟 P99
+	BurnRate        float64       `json:"burn_rate"`         // 可用性预算消耗速率，1 表示恰好在窗口内耗尽
+	LatencyBurnRate float64       `json:"latency_burn_rate"` // 延迟预算消耗速率
+	BudgetRemaining float64       `json:"budget_remaining"`  // 剩余预算比例，取两项中较小者，耗尽后为负数
+}
+
+// AlertFunc 预算告警回调
+// 剩余预算低于阈值时以 firing=true 调用一次，恢复到阈值以上时以 firing=false 调用一次
+type AlertFunc func(report Report, firing bool)
+
+// ErrorFunc 判断请求是否计为失败
+type ErrorFunc func(c *gin.Context) bool
+
+// latencyBounds 延迟直方图的桶上界
+var latencyBounds = [...]time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// bucket 滚动窗口中的一个时间片
+type bucket struct {
+	slot   int64 // 时间片序号，用于判断是否过期
+	total  uint64
+	errors uint64
+	slow   uint64
+	max    time.Duration                  // 时间片内的最大延迟
+	hist   [len(latencyBounds) + 1]uint64 // 延迟直方图，最后一个桶为超出上界的请求
+}
+
+// tracker 单个路由的滚动窗口
+type tracker struct {
+	mu      sync.Mutex
+	buckets []bucket
+	alerted bool // 是否处于告警状态
+}
+
+// Builder SLO 中间件构建器
+// 统计数据保存在 Builder 中，同一个 Builder 多次 Build 的中间件共享统计
+type Builder struct {
+	objective   Objective
+	window      time.Duration // 滚动窗口长度，即 SLO 的考核周期
+	bucketCount int           // 窗口划分的时间片数量
+	minRequests uint64        // 触发告警所需的最少请求数
+	threshold   float64       // 告警阈值（剩余预算比例）
+	alertFunc   AlertFunc
+	errorFunc   ErrorFunc
+
+	routes sync.Map // 路由统计 map[string]*tracker
+}
+
+// NewBuilder 创建 SLO 中间件构建器
+// 默认滚动窗口为 1 小时（划分为 60 个时间片），5xx 响应计为失败，至少 100 个请求才判断告警
+func NewBuilder(objective Objective) *Builder {
+	return &Builder{
+		objective:   objective,
+		window:      time.Hour,
+		bucketCount: 60,
+		minRequests: 100,
+		errorFunc: func(c *gin.Context) bool {
+			return c.Writer.Status() >= http.StatusInternalServerError
+		},
+	}
+}
+
+// WithWindow 设置滚动窗口长度和时间片数量
+// 窗口长度不大于 0 或时间片数量小于 1 时 panic
+func (b *Builder) WithWindow(window time.Duration, buckets int) *Builder {
+	if window <= 0 || buckets < 1 {
+		panic(fmt.Sprintf("slo: 窗口长度必须大于 0 且至少有 1 个时间片，实际为 %s、%d", window, buckets))
+	}
+	b.window = window
+	b.bucketCount = buckets
+	return b
+}
+
+// WithErrorFunc 自定义失败请求的判断规则
+func (b *Builder) WithErrorFunc(fn ErrorFunc) *Builder {
+	b.errorFunc = fn
+	return b
+}
+
+// WithMinRequests 设置触发告警所需的最少请求数，避免低流量路由误报
+func (b *Builder) WithMinRequests(n uint64) *Builder {
+	b.minRequests = n
+	return b
+}
+
+// WithAlertFunc 设置预算告警回调
+// threshold 为剩余预算比例阈值，如 0.1 表示预算剩余不足 10% 时告警，可用于开启降载等保护措施
+func (b *Builder) WithAlertFunc(threshold float64, fn AlertFunc) *Builder {
+	b.threshold = threshold
+	b.alertFunc = fn
+	return b
+}
+
+// Report 获取单个路由的统计，路由不存在时返回 false
+func (b *Builder) Report(route string) (Report, bool) {
+	val, ok := b.routes.Load(route)
+	if !ok {
+		return Report{}, false
+	}
+	return b.report(route, val.(*tracker), time.Now()), true
+}
+
+// Reports 获取所有路由的统计
+func (b *Builder) Reports() map[string]Report {
+	now := time.Now()
+	result := make(map[string]Report)
+	b.routes.Range(func(key, value any) bool {
+		result[key.(string)] = b.report(key.(string), value.(*tracker), now)
+		return true
+	})
+	return result
+}
+
+// Build 构建中间件
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		duration := time.Since(start)
+
+		route := route.Label(c)
+		t := b.tracker(route)
+
+		now := time.Now()
+		t.mu.Lock()
+		bk := t.bucket(b.slot(now))
+		bk.total++
+		if b.errorFunc(c) {
+			bk.errors++
+		}
+		if b.objective.Latency > 0 && duration > b.objective.Latency {
+			bk.slow++
+		}
+		if duration > bk.max {
+			bk.max = duration
+		}
+		bk.hist[histIndex(duration)]++
+
+		// 判断告警状态是否变化
+		var changed, firing bool
+		if b.alertFunc != nil {
+			agg := t.aggregate(b.slot(now))
+			_, _, remaining := b.burn(agg)
+			firing = agg.total >= b.minRequests && remaining < b.threshold
+			changed = firing != t.alerted
+			t.alerted = firing
+		}
+		t.mu.Unlock()
+
+		if changed {
+			b.alertFunc(b.report(route, t, now), firing)
+		}
+	}
+}
+
+// tracker 获取或创建路由统计
+func (b *Builder) tracker(route string) *tracker {
+	if val, ok := b.routes.Load(route); ok {
+		return val.(*tracker)
+	}
+	val, _ := b.routes.LoadOrStore(route, &tracker{buckets: make([]bucket, b.bucketCount)})
+	return val.(*tracker)
+}
+
+// slot 计算时间所在的时间片序号
+func (b *Builder) slot(now time.Time) int64 {
+	width := int64(b.window) / int64(b.bucketCount)
+	if width <= 0 {
+		width = 1
+	}
+	return now.UnixNano() / width
+}
+
+// report 计算路由的统计报告
+func (b *Builder) report(route string, t *tracker, now time.Time) Report {
+	t.mu.Lock()
+	agg := t.aggregate(b.slot(now))
+	t.mu.Unlock()
+
+	burnRate, latencyBurnRate, remaining := b.burn(agg)
+	r := Report{
+		Route:           route,
+		Total:           agg.total,
+		Errors:          agg.errors,
+		Slow:            agg.slow,
+		SuccessRatio:    1,
+		BurnRate:        burnRate,
+		LatencyBurnRate: latencyBurnRate,
+		BudgetRemaining: remaining,
+	}
+	if agg.total > 0 {
+		r.SuccessRatio = 1 - float64(agg.errors)/float64(agg.total)
+		r.P50 = agg.percentile(0.5)
+		r.P90 = agg.percentile(0.9)
+		r.P99 = agg.percentile(0.99)
+	}
+	return r
+}
+
+// burn 计算可用性和延迟的预算消耗速率以及剩余预算
+func (b *Builder) burn(agg bucket) (float64, float64, float64) {
+	if agg.total == 0 {
+		return 0, 0, 1
+	}
+	availability := burnRate(agg.errors, agg.total, b.objective.Availability)
+	var latency float64
+	if b.objective.Latency > 0 {
+		latency = burnRate(agg.slow, agg.total, b.objective.LatencyRatio)
+	}
+	return availability, latency, 1 - math.Max(availability, latency)
+}
+
+// burnRate 实际失败率与允许失败率之比
+// 目标为 100% 时没有预算，出现失败即视为耗尽（使用 MaxFloat64 而不是 Inf，保证可以序列化为 JSON）
+func burnRate(bad, total uint64, target float64) float64 {
+	allowed := 1 - target
+	if allowed <= 0 {
+		if bad > 0 {
+			return math.MaxFloat64
+		}
+		return 0
+	}
+	return float64(bad) / float64(total) / allowed
+}
+
+// bucket 获取当前时间片，过期的时间片会被重置
+func (t *tracker) bucket(slot int64) *bucket {
+	bk := &t.buckets[slot%int64(len(t.buckets))]
+	if bk.slot != slot {
+		*bk = bucket{slot: slot}
+	}
+	return bk
+}
+
+// aggregate 汇总窗口内未过期的时间片
+func (t *tracker) aggregate(slot int64) bucket {
+	var agg bucket
+	oldest := slot - int64(len(t.buckets))
+	for i := range t.buckets {
+		bk := &t.buckets[i]
+		if bk.slot <= oldest || bk.total == 0 {
+			continue
+		}
+		agg.total += bk.total
+		agg.errors += bk.errors
+		agg.slow += bk.slow
+		agg.max = max(agg.max, bk.max)
+		for j, n := range bk.hist {
+			agg.hist[j] += n
+		}
+	}
+	return agg
+}
+
+// percentile 按直方图估算分位数，返回所在桶的上界，超出上界时返回最大延迟
+func (agg *bucket) percentile(q float64) time.Duration {
+	rank := uint64(math.Ceil(q * float64(agg.total)))
+	var count uint64
+	for i, n := range agg.hist {
+		count += n
+		if count >= rank {
+			if i < len(latencyBounds) {
+				return latencyBounds[i]
+			}
+			break
+		}
+	}
+	return agg.max
+}
+
+// histIndex 计算延迟所在的直方图桶
+func histIndex(d time.Duration) int {
+	for i, bound := range latencyBounds {
+		if d <= bound {
+			return i
+		}
+	}
+	return len(latencyBounds)
+}
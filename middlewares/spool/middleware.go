@@ -0,0 +1,186 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spool 将较大的请求体转存到临时文件，避免并发上传、导入时把整个请求体读入内存
+package spool
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/errpage"
+)
+
+// CtxBodyKey 在 Context 中存储转存后的请求体的 key
+const CtxBodyKey = "gint:spooled_body"
+
+// defaultMaxSize 默认允许的最大请求体，避免单个上传占满磁盘
+const defaultMaxSize = 100 << 20
+
+// errTooLarge 请求体超过最大限制
+var errTooLarge = errors.New("request body too large")
+
+// Body 转存后的请求体，可以多次读取和定位
+// c.Request.Body 直接替换为 *Body，读取请求体的中间件可以通过 io.Seeker 判断，读取后定位回原来的位置，不需要读入内存
+type Body struct {
+	io.ReadSeeker
+	size int64
+	file *os.File // 转存到临时文件时不为 nil
+}
+
+// Size 返回请求体的大小
+func (b *Body) Size() int64 {
+	return b.size
+}
+
+// InMemory 判断请求体是否保存在内存中（未超过阈值）
+func (b *Body) InMemory() bool {
+	return b.file == nil
+}
+
+// Close 实现 io.Closer，不关闭临时文件，临时文件在请求结束后删除
+func (b *Body) Close() error {
+	return nil
+}
+
+// close 关闭并删除临时文件
+func (b *Body) close() {
+	if b.file == nil {
+		return
+	}
+	name := b.file.Name()
+	b.file.Close()
+	if err := os.Remove(name); err != nil {
+		slog.Warn("删除请求体临时文件失败", slog.String("file", name), slog.Any("err", err))
+	}
+}
+
+// Builder 请求体转存中间件构建器
+type Builder struct {
+	threshold int64  // 超过该大小时转存到临时文件
+	maxSize   int64  // 允许的最大请求体，不大于 0 表示不限制
+	dir       string // 临时文件目录，为空时使用系统临时目录
+}
+
+// NewBuilder 创建请求体转存中间件构建器
+// threshold: 不超过该大小的请求体保存在内存中，超过时转存到临时文件
+func NewBuilder(threshold int64) *Builder {
+	return &Builder{threshold: threshold, maxSize: defaultMaxSize}
+}
+
+// WithMaxSize 设置允许的最大请求体，超过时响应 413，默认 100MB；不大于 0 表示不限制（注意磁盘可能被单个上传占满）
+func (b *Builder) WithMaxSize(size int64) *Builder {
+	b.maxSize = size
+	return b
+}
+
+// WithDir 设置临时文件目录，默认使用系统临时目录
+func (b *Builder) WithDir(dir string) *Builder {
+	b.dir = dir
+	return b
+}
+
+// Build 构建中间件
+// 执行后续处理前读取完整的请求体，c.Request.Body 替换为转存后的请求体，参数绑定等不需要修改；
+// 请求结束后删除临时文件
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		body, err := b.spool(c.Request.Body)
+		if err != nil {
+			if errors.Is(err, errTooLarge) {
+				abort(c, http.StatusRequestEntityTooLarge, "请求体不能超过"+strconv.FormatInt(b.maxSize, 10)+"字节")
+				return
+			}
+			slog.Error("转存请求体失败", slog.String("path", c.Request.URL.Path), slog.Any("err", err))
+			abort(c, http.StatusBadRequest, "读取请求体失败")
+			return
+		}
+		defer body.close()
+
+		c.Request.Body = body
+		c.Set(CtxBodyKey, body)
+		c.Next()
+	}
+}
+
+// spool 读取请求体，不超过阈值时保存在内存中，否则写入临时文件
+func (b *Builder) spool(r io.Reader) (*Body, error) {
+	if b.maxSize > 0 {
+		// 多读一个字节用于判断是否超过限制
+		r = io.LimitReader(r, b.maxSize+1)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, r, b.threshold+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if b.maxSize > 0 && n > b.maxSize {
+		return nil, errTooLarge
+	}
+	if n <= b.threshold {
+		return &Body{ReadSeeker: bytes.NewReader(buf.Bytes()), size: n}, nil
+	}
+
+	file, err := os.CreateTemp(b.dir, "gint-body-*")
+	if err != nil {
+		return nil, err
+	}
+	body := &Body{ReadSeeker: file, file: file}
+	size, err := io.Copy(file, io.MultiReader(&buf, r))
+	if err == nil && b.maxSize > 0 && size > b.maxSize {
+		err = errTooLarge
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		body.close()
+		return nil, err
+	}
+	body.size = size
+	return body, nil
+}
+
+// FromContext 获取转存后的请求体
+// 处理函数可以直接使用 io.ReadSeeker 多次读取，如先检测文件格式再从头解析
+func FromContext(c *gin.Context) (*Body, bool) {
+	v, ok := c.Get(CtxBodyKey)
+	if !ok {
+		return nil, false
+	}
+	body, ok := v.(*Body)
+	return body, ok
+}
+
+// abort 输出错误响应并终止请求
+func abort(c *gin.Context, status int, msg string) {
+	c.Abort()
+	if errpage.Render(c, errpage.Page{Status: status, Code: status, Msg: msg}) {
+		return
+	}
+	c.JSON(status, gin.H{"code": status, "msg": msg, "data": nil})
+}
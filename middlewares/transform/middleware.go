@@ -0,0 +1,177 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestFunc 请求转换函数
+// 在绑定参数之前执行，可以改写请求体、请求头等；返回错误时以 400 终止请求
+type RequestFunc func(c *gin.Context) error
+
+// ResponseFunc 响应转换函数
+// 在处理函数执行完成后对完整的响应体进行改写，返回新的响应体
+type ResponseFunc func(c *gin.Context, body []byte) ([]byte, error)
+
+// Builder 请求/响应转换中间件构建器
+// 通常注册在路由组上，使旧版客户端无需单独的 Handler 即可继续使用
+type Builder struct {
+	requestFuncs  []RequestFunc  // 请求转换函数，按注册顺序执行
+	responseFuncs []ResponseFunc // 响应转换函数，按注册顺序执行
+}
+
+// NewBuilder 创建转换中间件构建器
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// WithRequest 添加请求转换函数
+func (b *Builder) WithRequest(fn RequestFunc) *Builder {
+	b.requestFuncs = append(b.requestFuncs, fn)
+	return b
+}
+
+// WithResponse 添加响应转换函数
+// 注意：响应转换需要缓冲完整的响应体，不要用于 SSE 等流式接口
+func (b *Builder) WithResponse(fn ResponseFunc) *Builder {
+	b.responseFuncs = append(b.responseFuncs, fn)
+	return b
+}
+
+// Build 构建中间件
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 执行请求转换
+		for _, fn := range b.requestFuncs {
+			if err := fn(c); err != nil {
+				slog.Debug("请求转换失败",
+					slog.String("path", c.Request.URL.Path),
+					slog.Any("err", err))
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"code": 400,
+					"msg":  "请求格式错误: " + err.Error(),
+					"data": nil,
+				})
+				return
+			}
+		}
+
+		if len(b.responseFuncs) == 0 {
+			c.Next()
+			return
+		}
+
+		// 缓冲响应体，处理完成后统一转换
+		writer := &bufferWriter{
+			ResponseWriter: c.Writer,
+			body:           &bytes.Buffer{},
+		}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		for _, fn := range b.responseFuncs {
+			transformed, err := fn(c, body)
+			if err != nil {
+				slog.Error("响应转换失败",
+					slog.String("path", c.Request.URL.Path),
+					slog.Any("err", err))
+				break
+			}
+			body = transformed
+		}
+
+		if len(body) == 0 {
+			return
+		}
+		c.Writer.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = c.Writer.Write(body)
+	}
+}
+
+// bufferWriter 缓冲响应体的 ResponseWriter
+type bufferWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+// Write 写入缓冲区
+func (w *bufferWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+// WriteString 写入缓冲区
+func (w *bufferWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Written 缓冲期间只要有数据写入即视为已写出，避免 gin 重复渲染
+func (w *bufferWriter) Written() bool {
+	return w.body.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// maxConvertBody ConvertBody 读入内存的最大请求体，超过时响应 400
+const maxConvertBody = 32 << 20
+
+// ConvertBody 创建请求体转换函数
+// 当请求的 Content-Type 包含 from 时，使用 fn 转换请求体并将 Content-Type 改为 to
+// 转换需要把整个请求体读入内存，超过 32MB 的请求体不转换，响应 400
+//
+// 示例（旧版客户端提交 XML，转换为 JSON 后再绑定）:
+//
+//	transform.ConvertBody("xml", "application/json", legacyXMLToJSON)
+func ConvertBody(from, to string, fn func(body []byte) ([]byte, error)) RequestFunc {
+	return func(c *gin.Context) error {
+		if c.Request.Body == nil || !strings.Contains(c.ContentType(), from) {
+			return nil
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxConvertBody+1))
+		if err != nil {
+			return err
+		}
+		if len(body) > maxConvertBody {
+			return fmt.Errorf("请求体超过%d字节，无法转换", maxConvertBody)
+		}
+		converted, err := fn(body)
+		if err != nil {
+			return err
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(converted))
+		c.Request.ContentLength = int64(len(converted))
+		c.Request.Header.Set("Content-Type", to)
+		c.Request.Header.Del("Content-Length")
+		return nil
+	}
+}
+
+// OnlyJSON 包装响应转换函数，仅在响应为 JSON 时执行
+func OnlyJSON(fn ResponseFunc) ResponseFunc {
+	return func(c *gin.Context, body []byte) ([]byte, error) {
+		if !strings.Contains(c.Writer.Header().Get("Content-Type"), "application/json") {
+			return body, nil
+		}
+		return fn(c, body)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+)
+
+// ctxStateKey 在 Context 中存储事务状态的 key
+const ctxStateKey = "gint:tx_state"
+
+// Tx 事务接口，*sql.Tx 直接满足该接口
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// Starter 开启事务的函数
+type Starter func(ctx context.Context) (Tx, error)
+
+// SQL 基于 database/sql 的事务开启函数
+func SQL(db *sql.DB, opts *sql.TxOptions) Starter {
+	return func(ctx context.Context) (Tx, error) {
+		return db.BeginTx(ctx, opts)
+	}
+}
+
+// state 请求范围内的事务状态
+type state struct {
+	tx   Tx
+	done bool
+}
+
+// Builder 事务中间件构建器
+type Builder struct {
+	starter Starter
+}
+
+// NewBuilder 创建事务中间件构建器
+func NewBuilder(starter Starter) *Builder {
+	return &Builder{starter: starter}
+}
+
+// Build 构建中间件
+// 请求开始时开启事务，处理函数通过 ctx.Tx() 获取；gint 包装器在输出响应之前根据处理结果提交或回滚，
+// 其他处理函数在返回后根据状态码（小于 400）提交；发生 panic 时回滚后继续向上抛出
+func (b *Builder) Build() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t, err := b.starter(c.Request.Context())
+		if err != nil {
+			slog.Error("开启事务失败",
+				slog.String("path", c.Request.URL.Path),
+				slog.Any("err", err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "开启事务失败",
+				"data": nil,
+			})
+			return
+		}
+
+		s := &state{tx: t}
+		c.Set(gctx.CtxTxKey, t)
+		c.Set(ctxStateKey, s)
+
+		defer func() {
+			if r := recover(); r != nil {
+				s.finish(false)
+				panic(r)
+			}
+			// 未经过 gint 包装器结束的事务，根据响应状态码提交或回滚
+			if err := s.finish(c.Writer.Status() < http.StatusBadRequest && len(c.Errors) == 0); err != nil {
+				slog.Error("提交事务失败",
+					slog.String("path", c.Request.URL.Path),
+					slog.Any("err", err))
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// Finish 结束请求范围内的事务，commit 为 true 时提交，否则回滚
+// 由 gint 包装器在输出响应之前调用；事务不存在或已结束时不做任何处理
+func Finish(c *gin.Context, commit bool) error {
+	val, ok := c.Get(ctxStateKey)
+	if !ok {
+		return nil
+	}
+	return val.(*state).finish(commit)
+}
+
+// From 获取请求范围内的事务并转换为指定类型，如 tx.From[*sql.Tx](c)
+func From[T any](c *gin.Context) (T, bool) {
+	val, _ := c.Get(gctx.CtxTxKey)
+	t, ok := val.(T)
+	return t, ok
+}
+
+// finish 提交或回滚事务，只执行一次
+func (s *state) finish(commit bool) error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+
+	if commit {
+		if err := s.tx.Commit(); err != nil {
+			return fmt.Errorf("提交事务失败: %w", err)
+		}
+		return nil
+	}
+	if err := s.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		slog.Error("回滚事务失败", slog.Any("err", err))
+	}
+	return nil
+}
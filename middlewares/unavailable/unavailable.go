@@ -0,0 +1,129 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package unavailable 定义维护、限流、熔断、降载等中间件共用的“服务不可用”响应
+// 所有拒绝请求的中间件都输出相同结构的响应，客户端只需要实现一套退避逻辑
+package unavailable
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/errpage"
+)
+
+// Reason 拒绝请求的原因
+type Reason string
+
+const (
+	// ReasonMaintenance 服务维护中
+	ReasonMaintenance Reason = "maintenance"
+	// ReasonRateLimited 请求频率超过限制
+	ReasonRateLimited Reason = "rate_limited"
+	// ReasonOverloaded 服务过载（活跃连接数超限、降载）
+	ReasonOverloaded Reason = "overloaded"
+	// ReasonCircuitOpen 下游故障，熔断器已打开
+	ReasonCircuitOpen Reason = "circuit_open"
+	// ReasonDeadlineExceeded 调用方的超时预算已经耗尽
+	ReasonDeadlineExceeded Reason = "deadline_exceeded"
+)
+
+// Detail 响应中 data 字段的内容
+type Detail struct {
+	Reason     Reason `json:"reason"`                // 拒绝原因
+	RetryAfter int    `json:"retry_after,omitempty"` // 建议的重试等待秒数，0 表示未知
+	DocURL     string `json:"doc_url,omitempty"`     // 说明文档地址
+}
+
+// Payload 服务不可用响应，与 gint.Result 的结构一致
+//
+//	{"code": 429, "msg": "请求过于频繁，请稍后再试", "data": {"reason": "rate_limited", "retry_after": 3}}
+type Payload struct {
+	Code int    `json:"code"` // 与 HTTP 状态码相同
+	Msg  string `json:"msg"`  // 提示信息
+	Data Detail `json:"data"` // 详细信息
+}
+
+// docURL 全局的说明文档地址
+var docURL atomic.Pointer[string]
+
+// SetDocURL 设置响应中的说明文档地址，如介绍退避策略的开发者文档
+func SetDocURL(url string) {
+	docURL.Store(&url)
+}
+
+// messages 各原因的默认提示信息
+var messages = map[Reason]string{
+	ReasonMaintenance:      "服务维护中，请稍后再试",
+	ReasonRateLimited:      "请求过于频繁，请稍后再试",
+	ReasonOverloaded:       "服务繁忙，请稍后再试",
+	ReasonCircuitOpen:      "服务暂时不可用，请稍后再试",
+	ReasonDeadlineExceeded: "请求已超时",
+}
+
+// New 创建服务不可用响应，msg 为空时使用原因对应的默认提示
+func New(status int, reason Reason, msg string, retryAfter time.Duration) Payload {
+	if msg == "" {
+		msg = messages[reason]
+	}
+	p := Payload{
+		Code: status,
+		Msg:  msg,
+		Data: Detail{
+			Reason:     reason,
+			RetryAfter: seconds(retryAfter),
+		},
+	}
+	if url := docURL.Load(); url != nil {
+		p.Data.DocURL = *url
+	}
+	return p
+}
+
+// Abort 输出服务不可用响应并终止请求
+// retryAfter 大于 0 时同时设置 Retry-After 响应头（向上取整到秒）
+func Abort(c *gin.Context, status int, reason Reason, msg string, retryAfter time.Duration) {
+	p := New(status, reason, msg, retryAfter)
+	if p.Data.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(p.Data.RetryAfter))
+	}
+	c.Abort()
+	if errpage.Render(c, errpage.Page{Status: status, Code: p.Code, Msg: p.Msg}) {
+		return
+	}
+	c.JSON(status, p)
+}
+
+// TooManyRequests 以 429 终止请求
+func TooManyRequests(c *gin.Context, reason Reason, retryAfter time.Duration) {
+	Abort(c, http.StatusTooManyRequests, reason, "", retryAfter)
+}
+
+// ServiceUnavailable 以 503 终止请求
+func ServiceUnavailable(c *gin.Context, reason Reason, retryAfter time.Duration) {
+	Abort(c, http.StatusServiceUnavailable, reason, "", retryAfter)
+}
+
+// seconds 将等待时间向上取整到秒
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,310 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 上传文件字段支持的类型
+var (
+	fileHeaderType      = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeaderSliceType = reflect.TypeFor[[]*multipart.FileHeader]()
+)
+
+// mapFiles 将 multipart 请求中的文件绑定到 form 标签对应的 *multipart.FileHeader 或 []*multipart.FileHeader 字段
+// 需要在 ParseMultipartForm 之后调用
+func mapFiles(req *http.Request, obj any) error {
+	if req.MultipartForm == nil || len(req.MultipartForm.File) == 0 {
+		return nil
+	}
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	mapFileFields(v, req.MultipartForm.File)
+	return nil
+}
+
+// mapFileFields 遍历结构体字段（包括嵌入字段）设置文件
+func mapFileFields(v reflect.Value, files map[string][]*multipart.FileHeader) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			mapFileFields(v.Field(i), files)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		headers := files[name]
+		if len(headers) == 0 {
+			continue
+		}
+		switch field.Type {
+		case fileHeaderType:
+			v.Field(i).Set(reflect.ValueOf(headers[0]))
+		case fileHeaderSliceType:
+			v.Field(i).Set(reflect.ValueOf(headers))
+		}
+	}
+}
+
+// fileLimit 上传文件字段的大小限制，由 maxsize 标签解析
+type fileLimit struct {
+	index []int  // 字段在结构体中的位置，用于 FieldByIndex
+	form  string // form 标签中的名称，即 multipart 中的 part 名称
+	label string // 错误信息中的字段名
+	tag   string // maxsize 标签的原始值
+	limit int64  // 单个文件的最大字节数
+}
+
+// err 返回文件超出限制的错误
+func (l fileLimit) err() *FileSizeError {
+	return &FileSizeError{Field: l.label, Limit: l.tag}
+}
+
+// FileSizeError 上传的文件超过字段 maxsize 标签的限制
+type FileSizeError struct {
+	Field string // 字段名
+	Limit string // maxsize 标签的值，如 5MB
+}
+
+func (e *FileSizeError) Error() string {
+	return fmt.Sprintf("%s的文件大小不能超过%s", e.Field, e.Limit)
+}
+
+// fileLimitCache 按请求参数类型缓存解析后的 maxsize 标签（reflect.Type -> []fileLimit）
+var fileLimitCache sync.Map
+
+// fileLimitsOf 返回请求参数类型中上传文件字段的大小限制
+// maxsize 标签无效时 panic；包装器创建时通过 checkRules 调用，配置错误在启动时就会暴露
+func fileLimitsOf(t reflect.Type) []fileLimit {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if v, ok := fileLimitCache.Load(t); ok {
+		return v.([]fileLimit)
+	}
+	var limits []fileLimit
+	collectFileLimits(t, nil, &limits)
+	fileLimitCache.Store(t, limits)
+	return limits
+}
+
+// collectFileLimits 遍历结构体字段（包括嵌入字段）解析 maxsize 标签
+func collectFileLimits(t reflect.Type, index []int, limits *[]fileLimit) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldIndex := append(slices.Clone(index), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectFileLimits(field.Type, fieldIndex, limits)
+			continue
+		}
+
+		tag := field.Tag.Get("maxsize")
+		if tag == "" || (field.Type != fileHeaderType && field.Type != fileHeaderSliceType) {
+			continue
+		}
+		limit, err := parseSize(tag)
+		if err != nil {
+			panic(fmt.Sprintf("gint: 字段 %s.%s 的 maxsize 标签无效: %v", t, field.Name, err))
+		}
+		form, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		*limits = append(*limits, fileLimit{
+			index: fieldIndex,
+			form:  form,
+			label: fieldName(field),
+			tag:   tag,
+			limit: limit,
+		})
+	}
+}
+
+// validateFiles 校验已绑定的上传文件字段是否超过 maxsize 标签的限制
+// 返回所有超出大小限制的错误信息
+func validateFiles(obj any) []string {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs []string
+	for _, l := range fileLimitsOf(v.Type()) {
+		var headers []*multipart.FileHeader
+		switch fv := v.FieldByIndex(l.index).Interface().(type) {
+		case *multipart.FileHeader:
+			if fv != nil {
+				headers = append(headers, fv)
+			}
+		case []*multipart.FileHeader:
+			headers = fv
+		}
+		for _, fh := range headers {
+			if fh.Size > l.limit {
+				errs = append(errs, l.err().Error())
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// guardFileSizes 在解析 multipart 请求体的同时检查带 maxsize 标签的文件，不等到整个请求体解析完成
+// 请求体被读取时同步交给后台协程中的 multipart.Reader 统计每个文件的大小，
+// 超出限制后请求体的读取返回 *FileSizeError，解析随之中止，超大的文件不会被完整写入临时文件
+// 返回的 stop 在绑定完成后调用：恢复原来的请求体、等待后台协程结束，并返回检查到的超限错误
+func guardFileSizes(req *http.Request, limits []fileLimit) (stop func() error) {
+	noop := func() error { return nil }
+	if len(limits) == 0 || req.Body == nil || req.Body == http.NoBody {
+		return noop
+	}
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return noop
+	}
+
+	byName := make(map[string]fileLimit, len(limits))
+	for _, l := range limits {
+		if cur, ok := byName[l.form]; !ok || l.limit < cur.limit {
+			byName[l.form] = l
+		}
+	}
+
+	pr, pw := io.Pipe()
+	body := &guardedBody{ReadCloser: req.Body, pw: pw, done: make(chan struct{})}
+	go body.scan(multipart.NewReader(pr, params["boundary"]), pr, byName)
+
+	orig := req.Body
+	req.Body = body
+	return func() error {
+		req.Body = orig
+		pw.Close()
+		<-body.done
+		return body.err
+	}
+}
+
+// guardedBody 读取时把数据同步写入管道，供 scan 统计文件大小
+type guardedBody struct {
+	io.ReadCloser
+	pw   *io.PipeWriter
+	done chan struct{}
+	err  error // 超限的文件，scan 结束（done 关闭）后才可以读取
+}
+
+// Read 实现 io.Reader，文件超限后返回 *FileSizeError
+func (b *guardedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if _, werr := b.pw.Write(p[:n]); werr != nil {
+			return 0, werr
+		}
+	}
+	if err == io.EOF {
+		b.pw.Close()
+	}
+	return n, err
+}
+
+// scan 逐个读取 part，文件超过限制时以 *FileSizeError 关闭管道
+func (b *guardedBody) scan(mr *multipart.Reader, pr *io.PipeReader, limits map[string]fileLimit) {
+	defer close(b.done)
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		l, ok := limits[part.FormName()]
+		if !ok || part.FileName() == "" {
+			continue
+		}
+		// 多读一个字节用于判断是否超过限制
+		if n, _ := io.Copy(io.Discard, io.LimitReader(part, l.limit+1)); n > l.limit {
+			b.err = l.err()
+			pr.CloseWithError(b.err)
+			return
+		}
+	}
+	// 结束边界之后的数据或格式错误的请求体由解析方处理，这里只需读完，避免阻塞写入
+	_, _ = io.Copy(io.Discard, pr)
+}
+
+// parseSize 解析文件大小，支持 B、KB、MB、GB 单位（不区分大小写），无单位时为字节；不大于 0 时返回错误
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	factor := int64(1)
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, factor = strings.TrimSpace(num), u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n <= 0 {
+		return 0, errors.New("大小必须大于 0")
+	}
+	if n > math.MaxInt64/factor {
+		return 0, errors.New("大小超出范围")
+	}
+	return n * factor, nil
+}
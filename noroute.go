@@ -0,0 +1,53 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NoRoute 未匹配到路由时返回统一格式的 404 响应
+//
+// 示例:
+//
+//	engine.NoRoute(gint.NoRoute())
+func NoRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeError(c, http.StatusNotFound, Result{
+			Code:    http.StatusNotFound,
+			Msg:     "接口不存在",
+			TraceID: traceID(c),
+		})
+	}
+}
+
+// NoMethod 路由存在但请求方法不被允许时返回统一格式的 405 响应
+// 需要同时开启 engine.HandleMethodNotAllowed，否则 gin 按未匹配路由处理
+//
+// 示例:
+//
+//	engine.HandleMethodNotAllowed = true
+//	engine.NoMethod(gint.NoMethod())
+func NoMethod() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeError(c, http.StatusMethodNotAllowed, Result{
+			Code:    http.StatusMethodNotAllowed,
+			Msg:     "请求方法不被允许",
+			TraceID: traceID(c),
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import "time"
+
+// Option 包装器配置选项
+// 所有包装器（W/B/S/BS）都接受可变数量的 Option
+//
+// 示例:
+//
+//	router.GET("/users", gint.B(listUsers, gint.WithPageHeaders()))
+type Option func(*options)
+
+// options 包装器配置
+type options struct {
+	pageHeaders   bool               // 是否输出分页响应头
+	bindSources   []BindSource       // 显式指定的绑定来源，为空时自动推断
+	successStatus int                // 成功响应的 HTTP 状态码，0 表示 200
+	idempotency   *idempotencyConfig // 幂等配置，为 nil 时不开启
+	cache         *cacheConfig       // 响应缓存配置，为 nil 时不开启
+	name          string             // 处理函数在指标中的名称
+	errorBody     *errorBodyConfig   // 错误请求体日志配置，为 nil 时不记录
+	sizeLimit     *sizeLimit         // 响应体大小限制，为 nil 时使用全局设置
+	abortOnCancel bool               // 客户端断开后是否尽早停止处理
+
+	bindTranslator BindErrorTranslator // 绑定错误翻译函数，为 nil 时使用全局设置
+
+	heartbeat    time.Duration // Stream 的心跳间隔
+	heartbeatSet bool          // 是否显式设置了心跳间隔
+}
+
+// newOptions 合并配置选项，fn 为包装的处理函数
+func newOptions(fn any, opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.name == "" {
+		o.name = funcName(fn)
+	}
+	return o
+}
+
+// WithPageHeaders 在响应 PageData 时额外输出分页响应头
+// 包括 RFC 5988 的 Link（first/prev/next/last）和 X-Total-Count
+// 适用于依赖响应头分页的客户端（如部分后台管理框架）
+func WithPageHeaders() Option {
+	return func(o *options) {
+		o.pageHeaders = true
+	}
+}
+
+// WithStatus 设置成功响应的 HTTP 状态码
+// 如创建资源返回 201、异步受理返回 202；使用 204 时不输出响应体
+//
+// 示例:
+//
+//	router.POST("/users", gint.B(createUser, gint.WithStatus(http.StatusCreated)))
+func WithStatus(status int) Option {
+	return func(o *options) {
+		o.successStatus = status
+	}
+}
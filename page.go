@@ -0,0 +1,161 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+)
+
+// pageable 嵌入了 PageRequest 的请求参数
+type pageable interface {
+	pageRequest() *PageRequest
+}
+
+// pageRequest 返回分页参数本身，嵌入 PageRequest 的请求参数通过它满足 pageable
+func (p *PageRequest) pageRequest() *PageRequest {
+	return p
+}
+
+// Page 分页查询包装器
+// 绑定参数后自动调用 PageRequest.Validate 设置默认值，并将返回的 PageData 包装为成功响应；
+// PageData 未设置 Page、Size 时使用请求中的值。Req 必须是 PageRequest 或以值方式嵌入了 PageRequest 的结构体，
+// 嵌入 *PageRequest 时未绑定的指针为 nil，创建时 panic
+//
+// 示例:
+//
+//	type ListUsersReq struct {
+//	   gint.PageRequest
+//	   Keyword string `form:"keyword"`
+//	}
+//
+//	router.GET("/users", gint.Page(func(ctx *gctx.Context, req ListUsersReq) (gint.PageData[User], error) {
+//	   users, total, err := repo.List(ctx, req.Keyword, req.Offset(), req.Size)
+//	   return gint.PageData[User]{List: users, Total: total}, err
+//	}, gint.WithPageHeaders()))
+func Page[Req, T any](fn func(ctx *gctx.Context, req Req) (PageData[T], error), opts ...Option) gin.HandlerFunc {
+	if _, ok := any(new(Req)).(pageable); !ok {
+		panic(fmt.Sprintf("gint: Page 的请求参数 %T 必须嵌入 gint.PageRequest", *new(Req)))
+	}
+	if embedsPagePointer(reflect.TypeFor[Req]()) {
+		panic(fmt.Sprintf("gint: Page 的请求参数 %T 需要以值方式嵌入 gint.PageRequest，不能嵌入指针", *new(Req)))
+	}
+	checkRules[Req]()
+
+	o := newOptions(fn, opts)
+	return func(c *gin.Context) {
+		ctx := newContext(c, o)
+
+		// 绑定参数、设置分页默认值、执行业务逻辑并响应
+		handle(c, o, func() (Result, error) {
+			req, ok := bind[Req](c, o)
+			if !ok {
+				return Result{}, errBindFailed
+			}
+			pr := any(&req).(pageable).pageRequest()
+			pr.Validate()
+
+			data, err := fn(ctx, req)
+			if err != nil {
+				return Result{Code: CodeError}, err
+			}
+			if data.Page == 0 {
+				data.Page = pr.Page
+			}
+			if data.Size == 0 {
+				data.Size = pr.Size
+			}
+			if data.List == nil {
+				data.List = []T{}
+			}
+			return Success("", data), nil
+		})
+	}
+}
+
+// embedsPagePointer 判断 t 是否经由指针嵌入 PageRequest（包括嵌套的嵌入结构体）
+func embedsPagePointer(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	field, ok := t.FieldByName("PageRequest")
+	if !ok {
+		return false
+	}
+	for _, i := range field.Index {
+		sf := t.Field(i)
+		if sf.Type.Kind() == reflect.Pointer {
+			return true
+		}
+		t = sf.Type
+	}
+	return false
+}
+
+// pager 分页数据的元信息，由 PageData 实现
+type pager interface {
+	pageMeta() (total int64, page, size int)
+}
+
+// pageMeta 返回分页元信息
+func (p PageData[T]) pageMeta() (total int64, page, size int) {
+	return p.Total, p.Page, p.Size
+}
+
+// writePageHeaders 输出分页响应头
+// Link 中的链接基于当前请求地址，仅替换 page 和 size 查询参数
+func writePageHeaders(c *gin.Context, p pager) {
+	total, page, size := p.pageMeta()
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	if size < 1 {
+		return
+	}
+	if page < 1 {
+		page = 1
+	}
+	last := int((total + int64(size) - 1) / int64(size))
+	if last < 1 {
+		last = 1
+	}
+
+	links := make([]string, 0, 4)
+	links = append(links, pageLink(c, 1, size, "first"))
+	if page > 1 {
+		links = append(links, pageLink(c, min(page-1, last), size, "prev"))
+	}
+	if page < last {
+		links = append(links, pageLink(c, page+1, size, "next"))
+	}
+	links = append(links, pageLink(c, last, size, "last"))
+
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// pageLink 生成单个 Link 条目
+func pageLink(c *gin.Context, page, size int, rel string) string {
+	u := *c.Request.URL
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("size", strconv.Itoa(size))
+	u.RawQuery = query.Encode()
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/session"
+)
+
+// PermissionChecker 权限校验器
+type PermissionChecker interface {
+	// Check 判断会话是否满足权限要求，kind 为 role 或 perm，value 为要求的角色或权限
+	Check(ctx *gctx.Context, sess session.Session, kind, value string) (bool, error)
+}
+
+// PermissionCheckerFunc 函数形式的权限校验器
+type PermissionCheckerFunc func(ctx *gctx.Context, sess session.Session, kind, value string) (bool, error)
+
+// Check 调用函数本身
+func (f PermissionCheckerFunc) Check(ctx *gctx.Context, sess session.Session, kind, value string) (bool, error) {
+	return f(ctx, sess, kind, value)
+}
+
+// ClaimsPermissionChecker 默认的权限校验器
+// 从 Claims.Data["roles"]、Data["permissions"]（逗号分隔）中匹配，与字段级授权的默认规则一致
+type ClaimsPermissionChecker struct{}
+
+// Check 判断 Claims 中是否包含要求的角色或权限
+func (ClaimsPermissionChecker) Check(ctx *gctx.Context, sess session.Session, kind, value string) (bool, error) {
+	return claimsGrant(sess.Claims(), kind, value), nil
+}
+
+// permissionChecker 全局权限校验器
+var permissionChecker atomic.Pointer[PermissionChecker]
+
+// SetPermissionChecker 设置 SP/BSP 使用的权限校验器，传入 nil 恢复默认的 ClaimsPermissionChecker
+// 角色和权限存储在数据库或权限服务中时，实现 PermissionChecker 接入
+func SetPermissionChecker(checker PermissionChecker) {
+	if checker == nil {
+		permissionChecker.Store(nil)
+		return
+	}
+	permissionChecker.Store(&checker)
+}
+
+// getPermissionChecker 获取权限校验器
+func getPermissionChecker() PermissionChecker {
+	if checker := permissionChecker.Load(); checker != nil {
+		return *checker
+	}
+	return ClaimsPermissionChecker{}
+}
+
+// SP (Session + Permission) 带权限校验的 Session 包装器
+// 获取 Session 后按 perm 校验权限，不满足时响应 403，满足时与 S 相同
+// perm 的格式与 auth 标签一致，如 "role=admin"、"perm=order:write"、"role=admin|finance,perm=order:write"（满足任意一个即可）；
+// 不含 "=" 时视为权限，如 "order:write"
+//
+// 示例:
+//
+//	router.DELETE("/orders/:id", gint.SP("perm=order:delete", deleteOrder))
+func SP(perm string, fn func(ctx *gctx.Context, sess session.Session) (Result, error), opts ...Option) gin.HandlerFunc {
+	conds := parsePermission(perm)
+	o := newOptions(fn, opts)
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := newContext(c, o)
+
+		sess, ok := authorize(ctx, o, start, conds)
+		if !ok {
+			return
+		}
+
+		// 执行业务逻辑并响应
+		handle(c, o, func() (Result, error) {
+			return fn(ctx, sess)
+		}, slog.String("user_id", sess.Claims().UserId))
+	}
+}
+
+// BSP (Bind + Session + Permission) 带参数绑定和权限校验的 Session 包装器
+// 先校验权限再绑定参数，无权访问的请求不会触发参数校验；perm 的格式见 SP
+func BSP[Req any](perm string, fn func(ctx *gctx.Context, req Req, sess session.Session) (Result, error), opts ...Option) gin.HandlerFunc {
+	conds := parsePermission(perm)
+	checkRules[Req]()
+	o := newOptions(fn, opts)
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := newContext(c, o)
+
+		sess, ok := authorize(ctx, o, start, conds)
+		if !ok {
+			return
+		}
+
+		// 绑定参数、执行业务逻辑并响应
+		userAttr := slog.String("user_id", sess.Claims().UserId)
+		handle(c, o, func() (Result, error) {
+			req, ok := bind[Req](c, o, userAttr)
+			if !ok {
+				return Result{}, errBindFailed
+			}
+			return fn(ctx, req, sess)
+		}, userAttr)
+	}
+}
+
+// parsePermission 解析权限要求，不含 "=" 的部分视为 perm
+func parsePermission(perm string) []authCondition {
+	parts := strings.Split(perm, ",")
+	for i, part := range parts {
+		if !strings.Contains(part, "=") {
+			parts[i] = "perm=" + strings.TrimSpace(part)
+		}
+	}
+	return parseAuthTag(strings.Join(parts, ","))
+}
+
+// authorize 获取 Session 并校验权限，失败时直接输出响应并返回 ok=false
+// 未登录响应 401，账号被禁用或无权访问响应 403，校验器出错响应 500
+func authorize(ctx *gctx.Context, o *options, start time.Time, conds []authCondition) (session.Session, bool) {
+	c := ctx.Context
+	sess, err := session.Get(ctx)
+	if err != nil {
+		rejectSession(c, o, start, "获取 Session 失败", err)
+		return nil, false
+	}
+
+	checker := getPermissionChecker()
+	for _, cond := range conds {
+		for _, value := range cond.values {
+			granted, err := checker.Check(ctx, sess, cond.kind, value)
+			if err != nil {
+				slog.Error("校验权限失败", withTrace(c, []any{
+					slog.String("path", c.Request.URL.Path),
+					slog.String("user_id", sess.Claims().UserId),
+					slog.Any("err", err)})...)
+				recordError(c, err, CodeError, http.StatusInternalServerError, false)
+				abortError(c, http.StatusInternalServerError, Result{
+					Code:    CodeError,
+					Msg:     "校验权限失败",
+					TraceID: traceID(c),
+				})
+				observe(c, o, start, CodeError, err)
+				return nil, false
+			}
+			if granted {
+				return sess, true
+			}
+		}
+	}
+
+	slog.Debug("无权访问", withTrace(c, []any{
+		slog.String("path", c.Request.URL.Path),
+		slog.String("user_id", sess.Claims().UserId)})...)
+	recordError(c, errors.New("无权访问"), http.StatusForbidden, http.StatusForbidden, true)
+	abortError(c, http.StatusForbidden, Result{
+		Code:    http.StatusForbidden,
+		Msg:     "无权访问",
+		TraceID: traceID(c),
+	})
+	observe(c, o, start, http.StatusForbidden, nil)
+	return nil, false
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ink-code/gint/middlewares/accesslog"
+	"github.com/ink-code/gint/middlewares/activelimit"
+	"github.com/ink-code/gint/middlewares/diagnostics"
+	"github.com/ink-code/gint/middlewares/ratelimit"
+)
+
+// 预设中的中间件名称，用于 Use 替换或 Without 移除
+const (
+	PresetRecovery    = "recovery"    // panic 恢复
+	PresetAccessLog   = "accesslog"   // 访问日志
+	PresetDiagnostics = "diagnostics" // 资源诊断采样，结果记录在访问日志中
+	PresetShedding    = "shedding"    // 活跃连接数超限时拒绝请求
+	PresetRateLimit   = "ratelimit"   // 按 IP 限流
+)
+
+// Preset 按环境预设的中间件组合
+// 中间件在 Handlers 或 Apply 时才创建，被替换或移除的中间件不会启动后台协程
+//
+// 示例:
+//
+//	preset := gint.PresetProd().
+//	   Use(gint.PresetShedding, func() gin.HandlerFunc { return activelimit.NewBuilder(5000).Build() }).
+//	   Without(gint.PresetRateLimit)
+type Preset struct {
+	name        string
+	mode        string // gin 运行模式
+	middlewares []presetMiddleware
+	closers     []io.Closer // 中间件创建的需要在关闭时释放的资源，如限流器
+}
+
+// presetMiddleware 预设中的中间件
+type presetMiddleware struct {
+	name  string
+	build func() gin.HandlerFunc
+}
+
+// PresetDev 开发环境预设：访问日志记录完整的请求体、响应体和未匹配路由的路径，不限流、不拒绝请求
+func PresetDev() *Preset {
+	p := &Preset{name: "dev", mode: gin.DebugMode}
+	p.Use(PresetRecovery, gin.Recovery)
+	p.Use(PresetAccessLog, func() gin.HandlerFunc {
+		return accesslog.NewBuilder(logAccess()).
+			WithReqBody(true).
+			WithRespBody(true).
+			WithRawUnmatched().
+			Build()
+	})
+	return p
+}
+
+// PresetStaging 预发环境预设：与生产环境相同，诊断采样率提高到 10%，便于发布前定位问题
+func PresetStaging() *Preset {
+	return newReleasePreset("staging", 0.1)
+}
+
+// PresetProd 生产环境预设：访问日志不记录请求体和响应体、查询参数脱敏，1% 的请求采样资源诊断数据，
+// 活跃连接数超过 1000 时拒绝请求，单个 IP 每分钟最多 600 个请求
+func PresetProd() *Preset {
+	return newReleasePreset("prod", 0.01)
+}
+
+// newReleasePreset 创建预发和生产环境共用的预设
+func newReleasePreset(name string, sampleRate float64) *Preset {
+	p := &Preset{name: name, mode: gin.ReleaseMode}
+	p.Use(PresetRecovery, gin.Recovery)
+	p.Use(PresetAccessLog, func() gin.HandlerFunc {
+		return accesslog.NewBuilder(redactedAccessLog(logAccess())).Build()
+	})
+	p.Use(PresetDiagnostics, func() gin.HandlerFunc {
+		return diagnostics.NewBuilder(nil).WithSampleRate(sampleRate).Build()
+	})
+	p.Use(PresetShedding, func() gin.HandlerFunc {
+		return activelimit.NewBuilder(1000).Build()
+	})
+	p.Use(PresetRateLimit, func() gin.HandlerFunc {
+		limiter := ratelimit.NewSlidingWindowLimiter(600, time.Minute)
+		p.closers = append(p.closers, limiter)
+		return ratelimit.NewBuilder(limiter).Build()
+	})
+	return p
+}
+
+// Name 返回预设名称（dev、staging、prod）
+func (p *Preset) Name() string {
+	return p.name
+}
+
+// Use 替换同名的中间件，不存在时追加到末尾
+// build 在 Handlers 或 Apply 时调用
+func (p *Preset) Use(name string, build func() gin.HandlerFunc) *Preset {
+	mw := presetMiddleware{name: name, build: build}
+	if i := slices.IndexFunc(p.middlewares, func(m presetMiddleware) bool { return m.name == name }); i >= 0 {
+		p.middlewares[i] = mw
+	} else {
+		p.middlewares = append(p.middlewares, mw)
+	}
+	return p
+}
+
+// Without 移除指定名称的中间件
+func (p *Preset) Without(names ...string) *Preset {
+	p.middlewares = slices.DeleteFunc(p.middlewares, func(m presetMiddleware) bool {
+		return slices.Contains(names, m.name)
+	})
+	return p
+}
+
+// Names 返回预设中的中间件名称，按执行顺序排列
+func (p *Preset) Names() []string {
+	names := make([]string, len(p.middlewares))
+	for i, m := range p.middlewares {
+		names[i] = m.name
+	}
+	return names
+}
+
+// Handlers 创建预设中的中间件，按执行顺序排列
+func (p *Preset) Handlers() []gin.HandlerFunc {
+	handlers := make([]gin.HandlerFunc, 0, len(p.middlewares))
+	for _, m := range p.middlewares {
+		handlers = append(handlers, m.build())
+	}
+	return handlers
+}
+
+// Apply 将预设中的中间件注册到 engine，需要在注册路由之前调用
+func (p *Preset) Apply(engine *gin.Engine) {
+	engine.Use(p.Handlers()...)
+}
+
+// Close 释放中间件创建的资源，如限流器的清理协程
+func (p *Preset) Close() error {
+	var errs []error
+	for _, c := range p.closers {
+		errs = append(errs, c.Close())
+	}
+	p.closers = nil
+	return errors.Join(errs...)
+}
+
+// logAccess 通过 slog 记录访问日志
+func logAccess() accesslog.LogFunc {
+	return func(l *accesslog.AccessLog) {
+		attrs := []any{
+			slog.String("method", l.Method),
+			slog.String("route", l.Route),
+			slog.String("path", l.Path),
+			slog.String("query", l.Query),
+			slog.String("ip", l.IP),
+			slog.Int("status", l.Status),
+			slog.Int64("duration", l.Duration),
+		}
+		if l.UserID != "" {
+			attrs = append(attrs, slog.String("user_id", l.UserID))
+		}
+		if l.ReqBody != "" {
+			attrs = append(attrs, slog.String("req_body", l.ReqBody))
+		}
+		if l.RespBody != "" {
+			attrs = append(attrs, slog.String("resp_body", l.RespBody))
+		}
+		if l.Error != "" {
+			attrs = append(attrs, slog.String("error", l.Error))
+		}
+		if l.Diagnostics != nil {
+			attrs = append(attrs, slog.Any("diagnostics", l.Diagnostics))
+		}
+		slog.Info("访问日志", attrs...)
+	}
+}
+
+// redactedAccessLog 脱敏查询参数中的敏感字段（password、token 等）后再记录
+func redactedAccessLog(next accesslog.LogFunc) accesslog.LogFunc {
+	cfg := &errorBodyConfig{redact: make(map[string]struct{}, len(defaultRedactFields))}
+	for _, f := range defaultRedactFields {
+		cfg.redact[f] = struct{}{}
+	}
+	return func(l *accesslog.AccessLog) {
+		l.Query = cfg.redactQuery(l.Query)
+		next(l)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ink-code/gint/reload"
+)
+
+var _ reload.Source = (*Source)(nil)
+
+// Source 从 Redis 字符串键读取配置文档，多实例共用一份配置
+// 运维修改键的值后，各实例在下一次加载时生效
+type Source struct {
+	client redis.UniversalClient
+	key    string
+}
+
+// NewSource 创建 Redis 配置来源
+func NewSource(client redis.UniversalClient, key string) *Source {
+	return &Source{client: client, key: key}
+}
+
+// Load 读取配置文档
+func (s *Source) Load(ctx context.Context) ([]byte, error) {
+	return s.client.Get(ctx, s.key).Bytes()
+}
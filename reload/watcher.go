@@ -0,0 +1,201 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reload 定期从文件或 Redis 读取配置，在运行时更新 CORS、限流、IP 名单等中间件，修改配置不需要重新部署
+package reload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ink-code/gint/internal/supervisor"
+)
+
+// Source 配置来源
+type Source interface {
+	// Load 读取完整的配置文档
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// Target 可以在运行时更新配置的对象
+// cors.Reloadable、ratelimit.ReloadableLimiter、ipfilter.Builder 均实现了该接口
+type Target interface {
+	// Prepare 解析并校验配置，返回应用配置的函数；应用配置不能失败
+	Prepare(raw json.RawMessage) (apply func(), err error)
+}
+
+// fileSource 文件配置来源
+type fileSource string
+
+// Load 读取文件
+func (f fileSource) Load(context.Context) ([]byte, error) {
+	return os.ReadFile(string(f))
+}
+
+// FileSource 创建文件配置来源，如挂载到容器中的 ConfigMap
+func FileSource(path string) Source {
+	return fileSource(path)
+}
+
+// target 注册的更新对象
+type target struct {
+	name string
+	t    Target
+}
+
+// Watcher 配置监听器
+// 配置文档为 JSON 对象，每个顶层字段对应一个注册的对象：
+//
+//	{
+//	  "cors": {"allow_origins": ["https://example.com"]},
+//	  "ratelimit": {"rate": 100, "window": "1m"},
+//	  "ipfilter": {"allow": ["10.0.0.0/8"], "deny": ["10.0.0.5"]}
+//	}
+//
+// 所有对象的配置都校验通过后才一起应用，任意一项有误时全部保留原配置
+type Watcher struct {
+	source   Source
+	interval time.Duration
+	targets  []target
+
+	mu      sync.Mutex
+	last    []byte // 最近一次成功应用的配置文档
+	lastErr error  // 最近一次加载的错误
+	watcher *supervisor.Supervisor
+}
+
+// defaultInterval 未设置读取间隔时的默认值
+const defaultInterval = 30 * time.Second
+
+// NewWatcher 创建配置监听器，每隔 interval 读取一次配置，interval 不大于 0 时使用 30 秒
+func NewWatcher(source Source, interval time.Duration) *Watcher {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return &Watcher{source: source, interval: interval}
+}
+
+// Register 注册更新对象，name 为配置文档中的字段名
+// 配置文档中没有该字段时，对象保持当前配置
+func (w *Watcher) Register(name string, t Target) *Watcher {
+	w.targets = append(w.targets, target{name: name, t: t})
+	return w
+}
+
+// Start 立即加载一次配置，然后启动后台协程定期加载
+// 首次加载失败时返回错误，不启动后台协程，便于在启动时发现配置问题
+func (w *Watcher) Start(ctx context.Context) error {
+	if err := w.Reload(ctx); err != nil {
+		return err
+	}
+	w.watcher = supervisor.Go("reload-watcher", w.loop)
+	return nil
+}
+
+// Close 停止后台协程
+func (w *Watcher) Close() error {
+	if w.watcher == nil {
+		return nil
+	}
+	return w.watcher.Close()
+}
+
+// Reload 读取并应用配置，配置文档与上次相同时不做任何操作
+// 读取、解析或校验失败时返回错误，所有对象保留原配置
+func (w *Watcher) Reload(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.reload(ctx)
+	w.lastErr = err
+	return err
+}
+
+// LastError 返回最近一次加载的错误，成功时为 nil
+func (w *Watcher) LastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// reload 读取并应用配置
+func (w *Watcher) reload(ctx context.Context) error {
+	data, err := w.source.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("读取配置失败: %w", err)
+	}
+	if w.last != nil && bytes.Equal(data, w.last) {
+		return nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("解析配置失败: %w", err)
+	}
+
+	// 先校验所有配置，全部通过后再应用
+	var applies []func()
+	var errs []error
+	var names []string
+	for _, t := range w.targets {
+		raw, ok := doc[t.name]
+		if !ok {
+			continue
+		}
+		apply, err := t.t.Prepare(raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.name, err))
+			continue
+		}
+		applies = append(applies, apply)
+		names = append(names, t.name)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("配置校验失败，保留原配置: %w", errors.Join(errs...))
+	}
+
+	for _, apply := range applies {
+		apply()
+	}
+	w.last = data
+	slog.Info("配置已更新", slog.Any("targets", names))
+	return nil
+}
+
+// loop 定期加载配置
+func (w *Watcher) loop(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), w.interval)
+		if err := w.Reload(ctx); err != nil {
+			slog.Error("更新配置失败", slog.Any("err", err))
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SizePolicy 响应体超过大小限制时的处理方式
+type SizePolicy int
+
+const (
+	// SizeWarn 照常输出，记录一条警告日志
+	SizeWarn SizePolicy = iota
+	// SizeTruncate 截断列表数据（切片或 PageData 的 List）直到不超过限制，并在响应中设置 truncated=true
+	// 数据不是列表或清空列表后仍超过限制时按 SizeReject 处理
+	SizeTruncate
+	// SizeReject 不输出数据，以 HTTP 500（或 CodeResponseTooLarge 配置的状态码）和业务码 CodeResponseTooLarge 响应
+	// 拒绝的响应以 ErrResponseTooLarge 记录为请求错误
+	SizeReject
+)
+
+// sizeLimit 响应体大小限制
+type sizeLimit struct {
+	limit  int
+	policy SizePolicy
+}
+
+var responseSizeLimit atomic.Pointer[sizeLimit]
+
+// SetResponseSizeLimit 设置所有包装器 JSON 响应体的大小上限（字节），limit <= 0 时取消限制
+// 单个路由可以通过 WithResponseSizeLimit 覆盖
+// 注意：应该在程序启动时调用
+//
+// 示例:
+//
+//	gint.SetResponseSizeLimit(10<<20, gint.SizeWarn)
+func SetResponseSizeLimit(limit int, policy SizePolicy) {
+	if limit <= 0 {
+		responseSizeLimit.Store(nil)
+		return
+	}
+	responseSizeLimit.Store(&sizeLimit{limit: limit, policy: policy})
+}
+
+// WithResponseSizeLimit 限制该路由 JSON 响应体的大小（字节），优先级高于 SetResponseSizeLimit，limit <= 0 时不限制
+// 只对包装器输出的 JSON 生效，Raw、FileResult 和 Stream 不受限制
+//
+// 示例:
+//
+//	router.GET("/orders", gint.B(listOrders, gint.WithResponseSizeLimit(2<<20, gint.SizeTruncate)))
+func WithResponseSizeLimit(limit int, policy SizePolicy) Option {
+	return func(o *options) {
+		o.sizeLimit = &sizeLimit{limit: limit, policy: policy}
+	}
+}
+
+// responseLimit 返回生效的响应体大小限制，没有限制时返回 nil
+func (o *options) responseLimit() *sizeLimit {
+	l := o.sizeLimit
+	if l == nil {
+		l = responseSizeLimit.Load()
+	}
+	if l == nil || l.limit <= 0 {
+		return nil
+	}
+	return l
+}
+
+// listTruncator 可截断的列表数据，由 PageData 实现
+type listTruncator interface {
+	listLen() int
+	truncateList(n int) any
+}
+
+// listLen 返回列表长度
+func (p PageData[T]) listLen() int {
+	return len(p.List)
+}
+
+// truncateList 返回只保留前 n 条数据的副本，Total 等分页信息不变
+func (p PageData[T]) truncateList(n int) any {
+	p.List = p.List[:n]
+	return p
+}
+
+// truncatable 返回列表长度和截断函数，data 不是列表时 ok 为 false
+func truncatable(data any) (n int, truncate func(n int) any, ok bool) {
+	if t, ok := data.(listTruncator); ok {
+		return t.listLen(), t.truncateList, true
+	}
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return 0, nil, false
+	}
+	return v.Len(), func(n int) any { return v.Slice(0, n).Interface() }, true
+}
+
+// renderJSON 输出成功响应的 JSON，配置了响应体大小限制时按策略处理超限的响应
+func renderJSON(c *gin.Context, o *options, status int, res Result) {
+	limit := o.responseLimit()
+	if limit == nil {
+		res.Data = filterFields(c, res.Data)
+		c.JSON(status, res)
+		return
+	}
+
+	data := res.Data
+	res.Data = filterFields(c, data)
+	body, err := json.Marshal(res)
+	if err != nil {
+		// 交给 gin 输出，与未限制时的错误处理保持一致
+		c.JSON(status, res)
+		return
+	}
+	if len(body) <= limit.limit {
+		c.Data(status, "application/json; charset=utf-8", body)
+		return
+	}
+
+	attrs := withTrace(c, []any{
+		slog.String("path", c.Request.URL.Path),
+		slog.Int("size", len(body)),
+		slog.Int("limit", limit.limit)})
+
+	switch limit.policy {
+	case SizeWarn:
+		slog.Warn("响应体超过大小限制", attrs...)
+		c.Data(status, "application/json; charset=utf-8", body)
+		return
+	case SizeTruncate:
+		if body, ok := truncateResult(c, res, data, limit.limit); ok {
+			slog.Warn("响应体超过大小限制，已截断列表", attrs...)
+			c.Data(status, "application/json; charset=utf-8", body)
+			return
+		}
+	}
+
+	slog.Error("响应体超过大小限制，拒绝输出", attrs...)
+	rejected := http.StatusInternalServerError
+	if mapped, ok := httpStatusForCode(CodeResponseTooLarge); ok {
+		rejected = mapped
+	}
+	recordError(c, ErrResponseTooLarge, CodeResponseTooLarge, rejected, false)
+	writeError(c, rejected, Result{
+		Code:    CodeResponseTooLarge,
+		Msg:     GetCodeMessage(CodeResponseTooLarge),
+		TraceID: res.TraceID,
+	})
+}
+
+// truncateResult 二分查找不超过 limit 的最长列表，返回截断后的响应体
+// data 不是列表或清空列表后仍超过限制时 ok 为 false
+func truncateResult(c *gin.Context, res Result, data any, limit int) (body []byte, ok bool) {
+	n, truncate, ok := truncatable(data)
+	if !ok {
+		return nil, false
+	}
+
+	res.Truncated = true
+	encode := func(keep int) []byte {
+		res.Data = filterFields(c, truncate(keep))
+		b, err := json.Marshal(res)
+		if err != nil {
+			return nil
+		}
+		return b
+	}
+
+	// 找到第一个超过限制的长度，保留的条数为它减一
+	keep := sort.Search(n+1, func(i int) bool {
+		b := encode(i)
+		return b == nil || len(b) > limit
+	}) - 1
+	if keep < 0 {
+		return nil, false
+	}
+	body = encode(keep)
+	return body, body != nil
+}
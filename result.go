@@ -0,0 +1,169 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ink-code/gint/gctx"
+)
+
+// FileResult 文件响应
+// 作为 Result.Data 返回时，包装器会直接输出文件内容而不是 JSON
+//
+// 示例:
+//
+//	return gint.Result{Data: gint.FileResult{Path: "./reports/2025.xlsx", Filename: "年度报表.xlsx"}}, nil
+type FileResult struct {
+	Path        string    // 文件路径，与 Reader 二选一
+	Reader      io.Reader // 文件内容，实现 io.ReadSeeker 时支持 Range 请求；实现 io.Closer 时输出后自动关闭
+	Filename    string    // 下载文件名，为空时使用 Path 的文件名
+	ContentType string    // 内容类型，为空时根据文件名推断
+	Size        int64     // 内容大小，Reader 不可 Seek 时用于设置 Content-Length，0 表示未知
+	ModTime     time.Time // 修改时间，用于缓存协商
+	Inline      bool      // true 时浏览器内联展示（如图片、PDF 预览），否则作为附件下载
+}
+
+// Raw 原始响应
+// 作为 Result.Data 返回时，包装器原样输出 Body，不包装为 JSON 信封
+// 适用于 Webhook 校验、纯文本健康检查、第三方回调等要求固定响应格式的场景
+//
+// 示例:
+//
+//	return gint.Result{Data: gint.Raw{ContentType: "text/plain", Body: []byte("success")}}, nil
+type Raw struct {
+	ContentType string // 内容类型，为空时使用 application/octet-stream
+	Body        []byte // 响应体
+	Status      int    // HTTP 状态码，0 表示使用包装器的成功状态码
+}
+
+// renderRaw 输出原始响应
+func renderRaw(c *gin.Context, raw Raw, status int) {
+	if raw.Status != 0 {
+		status = raw.Status
+	}
+	contentType := raw.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Data(status, contentType, raw.Body)
+}
+
+// Redirect 重定向响应
+// 作为 Result.Data 返回时，包装器发起重定向，适用于 OAuth 回调等场景
+// 以 "/" 开头的 Location 会自动加上 basepath 中间件设置的基础路径
+//
+// 示例:
+//
+//	return gint.Result{Data: gint.Redirect{Location: "/login?expired=1"}}, nil
+type Redirect struct {
+	Status   int    // HTTP 状态码（300-308 或 201），0 或其他状态码按 302 处理
+	Location string // 重定向地址
+}
+
+// renderRedirect 输出重定向响应
+func renderRedirect(c *gin.Context, r Redirect) {
+	status := r.Status
+	if status != 0 && (status < http.StatusMultipleChoices || status > http.StatusPermanentRedirect) && status != http.StatusCreated {
+		// gin 对其他状态码会 panic
+		slog.Warn("重定向状态码无效，按 302 处理",
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status))
+		status = 0
+	}
+	if status == 0 {
+		status = http.StatusFound
+	}
+	c.Redirect(status, gctx.JoinBasePath(c.GetString(gctx.CtxBasePathKey), r.Location))
+}
+
+// renderFile 输出文件响应
+func renderFile(c *gin.Context, f FileResult) {
+	if closer, ok := f.Reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	filename := f.Filename
+	if filename == "" && f.Path != "" {
+		filename = filepath.Base(f.Path)
+	}
+
+	contentType := f.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+
+	disposition := "attachment"
+	if f.Inline {
+		disposition = "inline"
+	}
+	if filename != "" {
+		// FormatMediaType 会对非 ASCII 文件名使用 RFC 2231 编码
+		disposition = mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+	}
+	c.Header("Content-Disposition", disposition)
+
+	// 按路径输出
+	if f.Reader == nil {
+		file, err := os.Open(f.Path)
+		if err != nil {
+			slog.Error("打开文件失败", slog.String("path", f.Path), slog.Any("err", err))
+			recordError(c, err, http.StatusNotFound, http.StatusNotFound, false)
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err == nil && info.IsDir() {
+			err = fmt.Errorf("%s 是目录", f.Path)
+		}
+		if err != nil {
+			recordError(c, err, http.StatusNotFound, http.StatusNotFound, false)
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(c.Writer, c.Request, filename, info.ModTime(), file)
+		return
+	}
+
+	// 可 Seek 的内容支持 Range 和缓存协商
+	if rs, ok := f.Reader.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, filename, f.ModTime, rs)
+		return
+	}
+
+	// 流式输出
+	size := f.Size
+	if size <= 0 {
+		size = -1
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, f.Reader, nil)
+}
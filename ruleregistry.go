@@ -0,0 +1,155 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RuleFactory 根据参数创建校验规则
+// param 为 NamedRule 的参数或 binding 标签中 = 之后的部分（如 merchant_id=cn 中的 cn），没有时为空字符串
+type RuleFactory func(param string) ValidationRule
+
+// maxCachedRules 缓存的规则数上限，参数在运行时生成（如 NamedRule 的参数来自请求）时避免缓存无限增长
+const maxCachedRules = 1024
+
+var (
+	ruleMu        sync.RWMutex
+	ruleRegistry  = make(map[string]RuleFactory)
+	ruleCache     sync.Map // 名称和参数 -> ValidationRule，同一参数的规则只创建一次
+	ruleCacheSize atomic.Int64
+)
+
+// builtinValidator 用于判断标签是否为 go-playground/validator 内置的标签
+var builtinValidator = sync.OnceValue(validator.New)
+
+// builtinTag 判断 name 是否为 go-playground/validator 内置的标签（如 email、uuid、ip）
+// 未定义的标签在校验时 panic 并提示 Undefined validation function，其他 panic（如缺少参数）说明标签已定义
+func builtinTag(name string) (builtin bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			builtin = !strings.Contains(fmt.Sprint(r), "Undefined validation function")
+		}
+	}()
+	builtinValidator().Var(nil, name)
+	return true
+}
+
+// RegisterRule 注册命名的校验规则，供其他模块按名称引用，不需要导入定义规则的包
+// 注册后可以通过 NamedRule 使用，也可以直接写在 binding 标签中，错误提示与 ValidatorBuilder 中一致
+// 注意：应该在程序启动时注册；名称为空、重复注册或与 validator 内置的标签（如 email、uuid、ip）同名会 panic，
+// 避免不同团队的规则互相覆盖，或替换进程中所有 binding 标签的内置校验
+//
+// 示例:
+//
+//	func init() {
+//	   gint.RegisterRule("merchant_id", func(param string) gint.ValidationRule {
+//	      return gint.WithMessage(gint.Pattern(`^M\d{10}$`), "不是有效的商户号")
+//	   })
+//	}
+//
+//	// 其他模块中
+//	vb.Field("商户号", req.MerchantID).AddRule(gint.NamedRule("merchant_id"))
+//
+//	type PayReq struct {
+//	   MerchantID string `json:"merchant_id" binding:"required,merchant_id"`
+//	}
+func RegisterRule(name string, factory RuleFactory) {
+	if name == "" || factory == nil {
+		panic("gint: 校验规则的名称和工厂函数不能为空")
+	}
+
+	if builtinTag(name) {
+		panic(fmt.Sprintf("gint: 校验规则 %s 与 validator 内置的标签同名", name))
+	}
+
+	ruleMu.Lock()
+	defer ruleMu.Unlock()
+	if _, ok := ruleRegistry[name]; ok {
+		panic(fmt.Sprintf("gint: 校验规则 %s 已注册", name))
+	}
+	ruleRegistry[name] = factory
+
+	// 同时注册为 binding 标签，替换了 binding.Validator 时需要自行注册
+	if binding.Validator == nil {
+		return
+	}
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := v.RegisterValidation(name, func(fl validator.FieldLevel) bool {
+			rule, ok := LookupRule(name, fl.Param())
+			return !ok || rule.Validate(fl.Field().Interface()) == nil
+		}); err != nil {
+			panic(fmt.Sprintf("gint: 注册 binding 标签 %s 失败: %v", name, err))
+		}
+	}
+}
+
+// LookupRule 按名称查找已注册的校验规则，未注册时返回 false
+func LookupRule(name, param string) (ValidationRule, bool) {
+	key := name + "=" + param
+	if rule, ok := ruleCache.Load(key); ok {
+		return rule.(ValidationRule), true
+	}
+
+	ruleMu.RLock()
+	factory, ok := ruleRegistry[name]
+	ruleMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	rule := &RegisteredRule{name: name, rule: factory(param)}
+	if ruleCacheSize.Load() >= maxCachedRules {
+		return rule, true
+	}
+	actual, loaded := ruleCache.LoadOrStore(key, rule)
+	if !loaded {
+		ruleCacheSize.Add(1)
+	}
+	return actual.(ValidationRule), true
+}
+
+// NamedRule 按名称获取已注册的校验规则，param 为可选的参数
+// 规则未注册时 panic，通常是忘记导入注册规则的包
+func NamedRule(name string, param ...string) ValidationRule {
+	var p string
+	if len(param) > 0 {
+		p = param[0]
+	}
+	rule, ok := LookupRule(name, p)
+	if !ok {
+		panic(fmt.Sprintf("gint: 校验规则 %s 未注册", name))
+	}
+	return rule
+}
+
+// RegisteredRule 通过 RegisterRule 注册的规则，规则名和错误码取注册的名称（如 merchant_id、VAL_MERCHANT_ID）
+type RegisteredRule struct {
+	name string
+	rule ValidationRule
+}
+
+func (r *RegisteredRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	return &RegisteredRule{name: r.name, rule: resolveRule(r.rule, lookup)}
+}
+
+func (r *RegisteredRule) Validate(value any) error {
+	return r.rule.Validate(value)
+}
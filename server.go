@@ -0,0 +1,331 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server 支持优雅关闭的 HTTP 服务
+// 收到 SIGINT/SIGTERM 后停止接收新请求，等待处理中的请求完成，依次执行关闭钩子，并输出结构化的关闭报告
+//
+// 示例:
+//
+//	srv := gint.NewServer(":8080", engine).
+//	   OnShutdown("task-pool", func(ctx context.Context) error { return pool.Close() }).
+//	   ReportGauge("task_queue_depth", func() int64 { return int64(pool.QueueLen()) })
+//	if err := srv.Run(); err != nil {
+//	   log.Fatal(err)
+//	}
+type Server struct {
+	httpServer      *http.Server
+	handler         http.Handler // engine 及 Wrap 添加的包装
+	shutdownTimeout time.Duration
+	hooks           []shutdownHook
+	gauges          []gauge
+	inflight        atomic.Int64 // 处理中的请求数
+	shutdownOnce    sync.Once
+	report          *ShutdownReport
+	shutdownErr     error
+
+	livePath   string                    // 存活检查路径
+	readyPath  string                    // 就绪检查路径
+	drainPath  string                    // 触发排空的管理接口路径
+	drainToken string                    // 触发排空需要的 token
+	drainGrace time.Duration             // 排空时间
+	drainStart atomic.Pointer[time.Time] // 开始排空的时间，nil 表示未开始
+	drainWait  time.Duration             // 收到信号后等待排空的时间
+	checks     []readinessCheck
+
+	grpcHandler http.Handler // 同一端口上的 gRPC 服务，见 WithGRPC
+	grpcHealth  bool         // 是否提供 gRPC 健康检查
+
+	container *Container // 接入生命周期的依赖注入容器，见 WithContainer
+}
+
+// shutdownHook 关闭钩子
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// gauge 关闭时读取的指标
+type gauge struct {
+	name string
+	fn   func() int64
+}
+
+// ShutdownReport 关闭报告
+type ShutdownReport struct {
+	Duration   time.Duration    // 关闭总耗时（不含排空时间）
+	DrainWait  time.Duration    // 收到信号后等待排空的时间，已通过 preStop 排空时为 0
+	InFlight   int64            // 开始关闭时处理中的请求数
+	Drained    int64            // 关闭期间处理完成的请求数
+	Unfinished int64            // 超时后仍未完成的请求数
+	Hooks      []HookReport     // 关闭钩子的执行情况
+	Gauges     map[string]int64 // 关闭完成时的指标，如队列中剩余的任务数
+}
+
+// HookReport 单个关闭钩子的执行情况
+type HookReport struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Clean 判断关闭是否干净：所有请求都已完成且所有钩子都执行成功
+func (r *ShutdownReport) Clean() bool {
+	if r.Unfinished > 0 {
+		return false
+	}
+	for _, h := range r.Hooks {
+		if h.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// NewServer 创建 HTTP 服务
+func NewServer(addr string, engine *gin.Engine) *Server {
+	s := &Server{shutdownTimeout: 30 * time.Second}
+	s.httpServer = &http.Server{
+		Addr:    addr,
+		Handler: s.track(),
+	}
+	s.handler = engine
+	return s
+}
+
+// NewServerWithPreset 使用环境预设创建 HTTP 服务
+// 先按预设设置 gin 运行模式并注册中间件，再调用 routes 注册路由；预设创建的资源在关闭时释放
+//
+// 示例:
+//
+//	srv := gint.NewServerWithPreset(":8080", gint.PresetProd(), func(engine *gin.Engine) {
+//	   engine.GET("/users/:id", gint.BS(getUser))
+//	})
+func NewServerWithPreset(addr string, preset *Preset, routes func(engine *gin.Engine)) *Server {
+	gin.SetMode(preset.mode)
+	engine := gin.New()
+	preset.Apply(engine)
+	routes(engine)
+	return NewServer(addr, engine).OnShutdown("preset", func(context.Context) error {
+		return preset.Close()
+	})
+}
+
+// WithShutdownTimeout 设置关闭的超时时间（等待请求完成和执行钩子共用），默认 30 秒
+func (s *Server) WithShutdownTimeout(timeout time.Duration) *Server {
+	s.shutdownTimeout = timeout
+	return s
+}
+
+// OnShutdown 添加关闭钩子，在处理中的请求完成后按添加顺序执行
+// 用于关闭任务池、刷新日志、断开数据库连接等
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) *Server {
+	s.hooks = append(s.hooks, shutdownHook{name: name, fn: fn})
+	return s
+}
+
+// WithContainer 将依赖注入容器接入服务的生命周期
+// Run 在开始监听前调用 Container.Start，启动失败时停止已构造的依赖并返回错误；
+// 关闭时在所有关闭钩子之后调用 Container.Stop，执行情况记录在关闭报告中（名称为 container）
+//
+// 示例:
+//
+//	gint.NewServer(":8080", engine).WithContainer(gint.DefaultContainer()).Run()
+func (s *Server) WithContainer(c *Container) *Server {
+	s.container = c
+	return s
+}
+
+// ReportGauge 添加在关闭报告中输出的指标，在所有钩子执行完成后读取
+// 如异步日志队列、任务队列中剩余的数量，用于确认关闭时没有丢弃数据
+func (s *Server) ReportGauge(name string, fn func() int64) *Server {
+	s.gauges = append(s.gauges, gauge{name: name, fn: fn})
+	return s
+}
+
+// HTTPServer 返回底层的 http.Server，用于设置超时、TLS 等
+func (s *Server) HTTPServer() *http.Server {
+	return s.httpServer
+}
+
+// Run 启动服务，收到 SIGINT/SIGTERM 后优雅关闭
+// 正常关闭时返回 nil，启动失败或关闭出错时返回错误；监听失败时也会执行关闭钩子并停止依赖注入容器
+func (s *Server) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if s.container != nil {
+		if err := s.container.Start(ctx); err != nil {
+			if stopErr := s.container.Stop(context.Background()); stopErr != nil {
+				slog.Error("停止依赖失败", slog.Any("err", stopErr))
+			}
+			return err
+		}
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("服务启动", slog.String("addr", s.httpServer.Addr))
+		errCh <- s.httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			// 监听失败（如端口被占用）时同样执行关闭钩子并停止已启动的依赖
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+			defer cancel()
+			if _, shutdownErr := s.Shutdown(shutdownCtx); shutdownErr != nil {
+				slog.Error("关闭服务失败", slog.Any("err", shutdownErr))
+			}
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	slog.Info("收到退出信号，开始关闭服务")
+	drainStart := time.Now()
+	s.Drain(context.Background())
+	s.drainWait = time.Since(drainStart)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	_, err := s.Shutdown(shutdownCtx)
+	return err
+}
+
+// Shutdown 优雅关闭服务并输出关闭报告，多次调用只执行一次
+// 返回的错误为等待请求超时或第一个失败的钩子的错误
+func (s *Server) Shutdown(ctx context.Context) (*ShutdownReport, error) {
+	s.shutdownOnce.Do(func() {
+		s.report, s.shutdownErr = s.shutdown(ctx)
+		s.report.log()
+	})
+	return s.report, s.shutdownErr
+}
+
+// shutdown 执行关闭流程
+func (s *Server) shutdown(ctx context.Context) (*ShutdownReport, error) {
+	start := time.Now()
+	report := &ShutdownReport{InFlight: s.inflight.Load(), DrainWait: s.drainWait}
+
+	// 停止接收新请求并等待处理中的请求完成
+	firstErr := s.httpServer.Shutdown(ctx)
+	report.Unfinished = max(s.inflight.Load(), 0)
+	report.Drained = max(report.InFlight-report.Unfinished, 0)
+
+	// 依次执行关闭钩子，依赖注入容器最后停止，钩子中仍可以使用容器构造的依赖
+	hooks := s.hooks
+	if s.container != nil {
+		hooks = append(hooks[:len(hooks):len(hooks)], shutdownHook{name: "container", fn: s.container.Stop})
+	}
+	for _, h := range hooks {
+		hookStart := time.Now()
+		err := h.fn(ctx)
+		report.Hooks = append(report.Hooks, HookReport{
+			Name:     h.name,
+			Duration: time.Since(hookStart),
+			Err:      err,
+		})
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if len(s.gauges) > 0 {
+		report.Gauges = make(map[string]int64, len(s.gauges))
+		for _, g := range s.gauges {
+			report.Gauges[g.name] = g.fn()
+		}
+	}
+
+	report.Duration = time.Since(start)
+	return report, firstErr
+}
+
+// Wrap 在路由匹配之前包装 engine，如 normalize、basepath.StripPrefix，后添加的包装先执行
+// 健康检查不经过这些包装；应该在 Run 之前调用
+//
+// 示例:
+//
+//	srv.Wrap(normalize.NewBuilder().WithTrailingSlash().Wrap)
+func (s *Server) Wrap(wrappers ...func(http.Handler) http.Handler) *Server {
+	for _, wrap := range wrappers {
+		s.handler = wrap(s.handler)
+	}
+	return s
+}
+
+// track 统计处理中的请求数，健康检查和排空请求不计入
+func (s *Server) track() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.serveHealth(w, r) {
+			return
+		}
+		if s.grpcHandler != nil && isGRPC(r) {
+			s.serveGRPC(w, r)
+			return
+		}
+		s.inflight.Add(1)
+		defer s.inflight.Add(-1)
+		s.handler.ServeHTTP(w, r)
+	})
+}
+
+// log 输出关闭报告，关闭不干净时使用 Warn 级别
+func (r *ShutdownReport) log() {
+	hooks := make([]any, 0, len(r.Hooks))
+	for _, h := range r.Hooks {
+		attrs := []any{slog.Duration("duration", h.Duration)}
+		if h.Err != nil {
+			attrs = append(attrs, slog.Any("err", h.Err))
+		}
+		hooks = append(hooks, slog.Group(h.Name, attrs...))
+	}
+	gauges := make([]any, 0, len(r.Gauges))
+	for name, v := range r.Gauges {
+		gauges = append(gauges, slog.Int64(name, v))
+	}
+
+	attrs := []any{
+		slog.Duration("duration", r.Duration),
+		slog.Duration("drain_wait", r.DrainWait),
+		slog.Int64("in_flight", r.InFlight),
+		slog.Int64("drained", r.Drained),
+		slog.Int64("unfinished", r.Unfinished),
+		slog.Group("hooks", hooks...),
+		slog.Group("gauges", gauges...),
+	}
+	if r.Clean() {
+		slog.Info("服务已关闭", attrs...)
+	} else {
+		slog.Warn("服务已关闭，部分请求或钩子未正常完成", attrs...)
+	}
+}
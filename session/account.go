@@ -0,0 +1,161 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ink-code/gint/internal/supervisor"
+)
+
+// ErrAccountDisabled 账号已被禁用（封禁、注销等）
+// AccountStatusChecker 返回的错误包装了该错误时，包装器响应 403
+var ErrAccountDisabled = errors.New("账号已被禁用")
+
+// AccountStatusChecker 账号状态检查函数
+// 账号不可用时返回包装了 ErrAccountDisabled 的错误，如 fmt.Errorf("%w: 违规封禁", session.ErrAccountDisabled)；
+// 返回其他错误（如数据库不可用）时本次请求按校验失败处理，结果不会被缓存
+type AccountStatusChecker func(ctx context.Context, userId string) error
+
+// accountChecker 全局账号状态检查器
+var accountChecker atomic.Pointer[accountStatusCache]
+
+// SetAccountStatusChecker 设置账号状态检查函数，传入 nil 关闭检查
+// Get、GetClaims 获取会话后调用，检查结果按用户缓存 ttl，封禁后最迟 ttl 内生效，无需等待 Token 过期
+//
+// 示例:
+//
+//	session.SetAccountStatusChecker(func(ctx context.Context, userId string) error {
+//	   if banned, _ := userRepo.IsBanned(ctx, userId); banned {
+//	      return session.ErrAccountDisabled
+//	   }
+//	   return nil
+//	}, 30*time.Second)
+func SetAccountStatusChecker(fn AccountStatusChecker, ttl time.Duration) {
+	var cache *accountStatusCache
+	if fn != nil {
+		cache = newAccountStatusCache(fn, ttl)
+	}
+	if old := accountChecker.Swap(cache); old != nil {
+		old.Close()
+	}
+}
+
+// InvalidateAccountStatus 清除用户的账号状态缓存，封禁用户后调用可立即生效
+func InvalidateAccountStatus(userId string) {
+	if cache := accountChecker.Load(); cache != nil {
+		cache.delete(userId)
+	}
+}
+
+// checkAccount 检查账号状态，未设置检查函数时直接通过
+func checkAccount(ctx context.Context, userId string) error {
+	cache := accountChecker.Load()
+	if cache == nil {
+		return nil
+	}
+	return cache.check(ctx, userId)
+}
+
+// accountStatusCache 带缓存的账号状态检查器
+type accountStatusCache struct {
+	fn      AccountStatusChecker
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]accountStatus
+	cleaner *supervisor.Supervisor
+}
+
+// accountStatus 缓存的检查结果
+type accountStatus struct {
+	err      error
+	expireAt time.Time
+}
+
+func newAccountStatusCache(fn AccountStatusChecker, ttl time.Duration) *accountStatusCache {
+	c := &accountStatusCache{
+		fn:      fn,
+		ttl:     ttl,
+		entries: make(map[string]accountStatus),
+	}
+	if ttl > 0 {
+		c.cleaner = supervisor.Go("account-status-cleaner", c.cleanupLoop)
+	}
+	return c
+}
+
+// check 检查账号状态，优先使用未过期的缓存
+func (c *accountStatusCache) check(ctx context.Context, userId string) error {
+	if c.ttl <= 0 {
+		return c.fn(ctx, userId)
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[userId]
+	c.mu.RUnlock()
+	if ok && time.Now().Before(entry.expireAt) {
+		return entry.err
+	}
+
+	err := c.fn(ctx, userId)
+	if err == nil || errors.Is(err, ErrAccountDisabled) {
+		c.mu.Lock()
+		c.entries[userId] = accountStatus{err: err, expireAt: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// delete 清除用户的缓存
+func (c *accountStatusCache) delete(userId string) {
+	c.mu.Lock()
+	delete(c.entries, userId)
+	c.mu.Unlock()
+}
+
+// Close 停止后台清理协程
+func (c *accountStatusCache) Close() error {
+	if c.cleaner == nil {
+		return nil
+	}
+	return c.cleaner.Close()
+}
+
+// cleanupLoop 定期清理过期的缓存
+func (c *accountStatusCache) cleanupLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(max(c.ttl, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		now := time.Now()
+		c.mu.Lock()
+		for userId, entry := range c.entries {
+			if now.After(entry.expireAt) {
+				delete(c.entries, userId)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
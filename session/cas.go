@@ -0,0 +1,48 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCASUnsupported Session 实现不支持 CompareAndSet
+var ErrCASUnsupported = errors.New("session 不支持 CompareAndSet")
+
+// CASSession 支持比较并设置的 Session
+// 同一用户的多个标签页并发修改会话数据（如选座、分步表单）时，避免后写入的请求静默覆盖先写入的数据
+type CASSession interface {
+	// CompareAndSet 当 key 的当前值等于 expected 时设置为 val 并返回 true，否则不修改并返回 false
+	// expected 为 nil 表示 key 不存在时才设置；值按 Provider 的 Codec 序列化后比较
+	CompareAndSet(ctx context.Context, key string, expected, val any) (bool, error)
+}
+
+// CompareAndSet 对 Session 执行比较并设置，Session 未实现 CASSession 时返回 ErrCASUnsupported
+//
+// 示例:
+//
+//	step, _ := sess.Get(ctx, "wizard_step")
+//	ok, err := session.CompareAndSet(ctx, sess, "wizard_step", step, nextStep)
+//	if err == nil && !ok {
+//	   return gint.Result{Code: 409, Msg: "页面已在其他标签页中修改，请刷新后重试"}, nil
+//	}
+func CompareAndSet(ctx context.Context, sess Session, key string, expected, val any) (bool, error) {
+	cas, ok := sess.(CASSession)
+	if !ok {
+		return false, ErrCASUnsupported
+	}
+	return cas.CompareAndSet(ctx, key, expected, val)
+}
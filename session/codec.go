@@ -0,0 +1,80 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec 会话数据的序列化方式
+// 会话存储在 Redis 等外部存储中时，Set 写入的值经 Marshal 序列化，Get 读取时经 Unmarshal 还原
+type Codec interface {
+	// Marshal 序列化会话数据
+	Marshal(val any) ([]byte, error)
+
+	// Unmarshal 反序列化会话数据
+	Unmarshal(data []byte) (any, error)
+}
+
+// JSONCodec JSON 序列化，与 encoding/json 的默认行为一致，数字还原为 float64
+// 超过 2^53 的整数（如雪花算法生成的 ID）会丢失精度，此时应使用 NumberJSONCodec
+var JSONCodec Codec = jsonCodec{}
+
+// NumberJSONCodec 保留数字精度的 JSON 序列化，数字（包括嵌套在 map、切片中的数字）还原为 json.Number
+//
+// 示例:
+//
+//	val, _ := sess.Get(ctx, "tenant_id")
+//	tenantId, err := val.(json.Number).Int64()
+var NumberJSONCodec Codec = jsonCodec{useNumber: true}
+
+// jsonCodec JSON 序列化实现
+type jsonCodec struct {
+	useNumber bool
+}
+
+func (c jsonCodec) Marshal(val any) ([]byte, error) {
+	return json.Marshal(val)
+}
+
+func (c jsonCodec) Unmarshal(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if c.useNumber {
+		decoder.UseNumber()
+	}
+	var result any
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Canonical 按 codec 将值序列化为规范形式：先序列化再反序列化，再次序列化
+// 结构体、超过 2^53 的整数等值与读取后得到的值序列化结果可能不同，规范化后两者一致；
+// Provider 写入会话数据时统一使用规范形式，CompareAndSet 才能与 Set 写入的值比较
+func Canonical(codec Codec, val any) ([]byte, error) {
+	data, err := codec.Marshal(val)
+	if err == nil {
+		if val, err = codec.Unmarshal(data); err == nil {
+			data, err = codec.Marshal(val)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("序列化数据失败: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codec 提供会话数据的其他序列化方式，通过 Provider 的 WithCodec 使用
+package codec
+
+import (
+	"reflect"
+
+	ugorji "github.com/ugorji/go/codec"
+
+	"github.com/ink-code/gint/session"
+)
+
+var _ session.Codec = (*msgpackCodec)(nil)
+
+// MsgPack MessagePack 序列化
+// 体积比 JSON 小、编解码更快，整数还原为 int64/uint64 不丢失精度，map 还原为 map[string]any，
+// 适合存储权限树等较大的结构
+//
+// 示例:
+//
+//	provider := redis.NewProvider(client, jwtKey, accessExpire, refreshExpire, carrier).
+//	   WithCodec(codec.MsgPack)
+var MsgPack session.Codec = newMsgpackCodec()
+
+// msgpackCodec MessagePack 序列化实现
+type msgpackCodec struct {
+	handle *ugorji.MsgpackHandle
+}
+
+func newMsgpackCodec() *msgpackCodec {
+	h := &ugorji.MsgpackHandle{}
+	h.WriteExt = true
+	h.Canonical = true // map 按键排序，序列化结果稳定，CompareAndSet 才能正确比较
+	h.RawToString = true
+	h.MapType = reflect.TypeFor[map[string]any]()
+	return &msgpackCodec{handle: h}
+}
+
+func (c *msgpackCodec) Marshal(val any) ([]byte, error) {
+	var b []byte
+	if err := ugorji.NewEncoderBytes(&b, c.handle).Encode(val); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *msgpackCodec) Unmarshal(data []byte) (any, error) {
+	var result any
+	if err := ugorji.NewDecoderBytes(data, c.handle).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
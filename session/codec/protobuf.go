@@ -0,0 +1,122 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/ink-code/gint/session"
+)
+
+var _ session.Codec = protobufCodec{}
+
+// Protobuf Protocol Buffers 序列化
+// 值需要是 proto.Message，以 google.protobuf.Any 存储，读取时还原为原始的消息类型（需要已注册，即导入了生成的代码）；
+// 字符串、整数、浮点数和布尔值（如 Provider 写入的 user_id、created_at）以包装类型存储，读取时还原为 string、int64、float64、bool
+//
+// 示例:
+//
+//	sess.Set(ctx, "permissions", &pb.PermissionTree{...})
+//
+//	val, _ := sess.Get(ctx, "permissions")
+//	tree := val.(*pb.PermissionTree)
+var Protobuf session.Codec = protobufCodec{}
+
+// protobufCodec Protocol Buffers 序列化实现
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(val any) ([]byte, error) {
+	msg, err := toMessage(val)
+	if err != nil {
+		return nil, err
+	}
+	// 外层和 Any 中的消息都使用确定性序列化，map 字段的顺序固定，相同的值得到相同的字节（session.Canonical 依赖这一点）
+	opts := proto.MarshalOptions{Deterministic: true}
+	wrapped := &anypb.Any{}
+	if err := anypb.MarshalFrom(wrapped, msg, opts); err != nil {
+		return nil, err
+	}
+	return opts.Marshal(wrapped)
+}
+
+func (protobufCodec) Unmarshal(data []byte) (any, error) {
+	var wrapped anypb.Any
+	if err := proto.Unmarshal(data, &wrapped); err != nil {
+		return nil, err
+	}
+	msg, err := wrapped.UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := msg.(type) {
+	case *wrapperspb.StringValue:
+		return v.GetValue(), nil
+	case *wrapperspb.Int64Value:
+		return v.GetValue(), nil
+	case *wrapperspb.UInt64Value:
+		return v.GetValue(), nil
+	case *wrapperspb.DoubleValue:
+		return v.GetValue(), nil
+	case *wrapperspb.BoolValue:
+		return v.GetValue(), nil
+	case *wrapperspb.BytesValue:
+		return v.GetValue(), nil
+	}
+	return msg, nil
+}
+
+// toMessage 将值转换为 proto.Message，基础类型使用包装类型
+func toMessage(val any) (proto.Message, error) {
+	switch v := val.(type) {
+	case proto.Message:
+		return v, nil
+	case string:
+		return wrapperspb.String(v), nil
+	case bool:
+		return wrapperspb.Bool(v), nil
+	case int:
+		return wrapperspb.Int64(int64(v)), nil
+	case int8:
+		return wrapperspb.Int64(int64(v)), nil
+	case int16:
+		return wrapperspb.Int64(int64(v)), nil
+	case int32:
+		return wrapperspb.Int64(int64(v)), nil
+	case int64:
+		return wrapperspb.Int64(v), nil
+	case uint:
+		return wrapperspb.UInt64(uint64(v)), nil
+	case uint8:
+		return wrapperspb.UInt64(uint64(v)), nil
+	case uint16:
+		return wrapperspb.UInt64(uint64(v)), nil
+	case uint32:
+		return wrapperspb.UInt64(uint64(v)), nil
+	case uint64:
+		return wrapperspb.UInt64(v), nil
+	case float32:
+		return wrapperspb.Double(float64(v)), nil
+	case float64:
+		return wrapperspb.Double(v), nil
+	case []byte:
+		return wrapperspb.Bytes(v), nil
+	}
+	return nil, fmt.Errorf("protobuf codec 不支持类型 %T，请使用 proto.Message", val)
+}
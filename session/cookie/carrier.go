@@ -46,6 +46,7 @@ func WithDomain(domain string) Option {
 }
 
 // WithPath 设置 Cookie 路径
+// 配置了 basepath 中间件时，路径会自动加上基础路径
 func WithPath(path string) Option {
 	return func(c *Carrier) {
 		c.path = path
@@ -101,7 +102,7 @@ func (c *Carrier) Inject(ctx *gctx.Context, token string) {
 		c.cookieName,
 		token,
 		c.maxAge,
-		c.path,
+		ctx.URL(c.path),
 		c.domain,
 		c.secure,
 		c.httpOnly,
@@ -119,7 +120,7 @@ func (c *Carrier) Clear(ctx *gctx.Context) {
 		c.cookieName,
 		"",
 		-1, // 设置为负数表示删除
-		c.path,
+		ctx.URL(c.path),
 		c.domain,
 		c.secure,
 		c.httpOnly,
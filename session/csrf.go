@@ -0,0 +1,131 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+
+	"github.com/ink-code/gint/gctx"
+)
+
+// CSRFKey CSRF Token 在 Session 数据中的键
+const CSRFKey = "gint:csrf"
+
+// ErrCSRFTokenInvalid 请求携带的 CSRF Token 为空或与会话中的不一致
+var ErrCSRFTokenInvalid = errors.New("CSRF Token 无效")
+
+// CSRFToken 返回当前会话的 CSRF Token，会话中还没有时生成一个并保存
+// Token 随 Session 存储，同一会话内保持不变；登录时 NewSession 创建新会话，旧会话的 Token 随之失效
+//
+// 示例:
+//
+//	r.GET("/profile/edit", func(c *gin.Context) {
+//	   token, err := session.CSRFToken(&gctx.Context{Context: c})
+//	   if err != nil {
+//	      c.Redirect(http.StatusFound, "/login")
+//	      return
+//	   }
+//	   c.HTML(http.StatusOK, "profile_edit.html", gin.H{"csrf": token})
+//	})
+func CSRFToken(ctx *gctx.Context) (string, error) {
+	sess, err := Get(ctx)
+	if err != nil {
+		return "", err
+	}
+	return SessionCSRFToken(ctx, sess)
+}
+
+// SessionCSRFToken 返回指定 Session 的 CSRF Token，没有时生成并保存
+// Session 实现了 CASSession 时，并发的首次请求只会保存一个 Token，其余请求读取已保存的值
+func SessionCSRFToken(ctx context.Context, sess Session) (string, error) {
+	if token := storedCSRFToken(ctx, sess); token != "" {
+		return token, nil
+	}
+	token, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	ok, err := CompareAndSet(ctx, sess, CSRFKey, nil, token)
+	switch {
+	case errors.Is(err, ErrCASUnsupported):
+		err = sess.Set(ctx, CSRFKey, token)
+	case err == nil && !ok:
+		// 其他请求已先保存了 Token
+		if stored := storedCSRFToken(ctx, sess); stored != "" {
+			return stored, nil
+		}
+		err = sess.Set(ctx, CSRFKey, token)
+	}
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RotateCSRFToken 为当前会话生成新的 CSRF Token 并返回，旧 Token 立即失效
+// 适用于提升权限（如二次验证、切换身份）等需要在同一会话内更换 Token 的场景
+func RotateCSRFToken(ctx *gctx.Context) (string, error) {
+	sess, err := Get(ctx)
+	if err != nil {
+		return "", err
+	}
+	return RotateSessionCSRFToken(ctx, sess)
+}
+
+// RotateSessionCSRFToken 为指定 Session 生成新的 CSRF Token 并返回，旧 Token 立即失效
+// Provider 的 Attach 为外部主体创建会话时调用：外部会话的 SSID 固定，登录时不会像 NewSession 那样得到新会话
+func RotateSessionCSRFToken(ctx context.Context, sess Session) (string, error) {
+	token, err := newCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	if err := sess.Set(ctx, CSRFKey, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// VerifyCSRF 校验 token 是否与 Session 中保存的 CSRF Token 一致，不一致时返回 ErrCSRFTokenInvalid
+// 会话中还没有 Token 时同样视为不一致
+func VerifyCSRF(ctx context.Context, sess Session, token string) error {
+	stored := storedCSRFToken(ctx, sess)
+	if token == "" || stored == "" || subtle.ConstantTimeCompare([]byte(token), []byte(stored)) != 1 {
+		return ErrCSRFTokenInvalid
+	}
+	return nil
+}
+
+// storedCSRFToken 读取 Session 中保存的 CSRF Token，不存在或读取失败时返回空字符串
+func storedCSRFToken(ctx context.Context, sess Session) string {
+	val, err := sess.Get(ctx, CSRFKey)
+	if err != nil {
+		return ""
+	}
+	token, _ := val.(string)
+	return token
+}
+
+// newCSRFToken 生成 32 字节随机数的 base64url 编码
+func newCSRFToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,354 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ink-code/gint/session"
+)
+
+var _ session.ClaimsResolver = (*JWKSResolver)(nil)
+
+// JWKSResolver 基于 JWKS 验签的外部令牌解析器
+// 适用于 Keycloak、Auth0 等提供标准 JWKS 端点的身份提供方
+type JWKSResolver struct {
+	jwksURL         string
+	client          *http.Client
+	refreshInterval time.Duration // 公钥定期刷新间隔
+	minRefresh      time.Duration // 两次获取 JWKS 的最小间隔，获取失败同样计入
+	issuer          string
+	audience        string
+	subjectClaim    string
+	dataClaims      []string
+
+	mu          sync.RWMutex
+	keys        map[string]any // kid -> 公钥
+	fetchedAt   time.Time      // 最近一次成功获取的时间
+	attemptedAt time.Time      // 最近一次尝试获取的时间，失败时同样更新
+	lastErr     error          // 最近一次获取的错误
+	inflight    *refreshCall   // 正在进行的获取，并发的请求共享结果
+}
+
+// refreshCall 一次 JWKS 获取
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// JWKSOption JWKS 解析器配置选项
+type JWKSOption func(*JWKSResolver)
+
+// WithIssuer 校验令牌的签发者（iss）
+func WithIssuer(issuer string) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.issuer = issuer
+	}
+}
+
+// WithAudience 校验令牌的受众（aud）
+func WithAudience(audience string) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.audience = audience
+	}
+}
+
+// WithSubjectClaim 设置作为主体标识的声明，默认为 sub
+func WithSubjectClaim(claim string) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.subjectClaim = claim
+	}
+}
+
+// WithDataClaims 设置需要放入会话 Claims.Data 的声明
+// 数组类型的声明会以逗号拼接
+func WithDataClaims(claims ...string) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.dataClaims = claims
+	}
+}
+
+// WithRefreshInterval 设置公钥定期刷新间隔，默认 1 小时
+func WithRefreshInterval(interval time.Duration) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.refreshInterval = interval
+	}
+}
+
+// WithHTTPClient 设置获取 JWKS 使用的 HTTP 客户端
+func WithHTTPClient(client *http.Client) JWKSOption {
+	return func(r *JWKSResolver) {
+		r.client = client
+	}
+}
+
+// NewJWKSResolver 创建 JWKS 解析器
+// jwksURL: JWKS 地址，如 "https://auth.example.com/realms/demo/protocol/openid-connect/certs"
+func NewJWKSResolver(jwksURL string, opts ...JWKSOption) *JWKSResolver {
+	r := &JWKSResolver{
+		jwksURL:         jwksURL,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		refreshInterval: time.Hour,
+		minRefresh:      time.Minute,
+		subjectClaim:    "sub",
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve 校验令牌并解析身份信息
+func (r *JWKSResolver) Resolve(ctx context.Context, token string) (*session.ExternalIdentity, error) {
+	token = strings.TrimSpace(strings.TrimPrefix(token, "Bearer "))
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+	}
+	if r.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(r.issuer))
+	}
+	if r.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(r.audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return r.key(ctx, kid)
+	}, parserOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("校验外部令牌失败: %w", err)
+	}
+	// 不接受永不过期的外部令牌
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("外部令牌缺少过期时间")
+	}
+
+	subject, _ := claims[r.subjectClaim].(string)
+	identity := &session.ExternalIdentity{
+		Subject: subject,
+		Data:    make(map[string]string, len(r.dataClaims)),
+	}
+	for _, name := range r.dataClaims {
+		if val, ok := claimString(claims[name]); ok {
+			identity.Data[name] = val
+		}
+	}
+	return identity, nil
+}
+
+// key 按 kid 查找公钥，未找到时刷新 JWKS 后重试
+func (r *JWKSResolver) key(ctx context.Context, kid string) (any, error) {
+	r.mu.RLock()
+	key, ok := r.keys[kid]
+	stale := time.Since(r.fetchedAt) > r.refreshInterval
+	r.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if err := r.refreshShared(ctx); err != nil {
+		if ok {
+			// 刷新失败时继续使用旧公钥
+			return key, nil
+		}
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if key, ok := r.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未知的密钥 ID: %s", kid)
+}
+
+// refreshShared 刷新 JWKS，并发的请求共享同一次获取
+// 距离上次尝试（无论成功与否）不足 minRefresh 时不再获取，直接返回上次的错误，
+// 防止身份提供方故障或伪造 kid 的请求使每个请求都阻塞在获取上并打爆身份提供方
+func (r *JWKSResolver) refreshShared(ctx context.Context) error {
+	r.mu.Lock()
+	if call := r.inflight; call != nil {
+		r.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if time.Since(r.attemptedAt) < r.minRefresh {
+		err := r.lastErr
+		r.mu.Unlock()
+		return err
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	r.inflight = call
+	r.attemptedAt = time.Now()
+	r.mu.Unlock()
+
+	// 其他请求在等待结果，发起请求的客户端断开时不取消获取
+	call.err = r.refresh(context.WithoutCancel(ctx))
+
+	r.mu.Lock()
+	r.inflight = nil
+	r.lastErr = call.err
+	r.mu.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// jwk JSON Web Key
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// refresh 拉取并解析 JWKS
+func (r *JWKSResolver) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.jwksURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("获取 JWKS 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("获取 JWKS 失败: HTTP %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("解析 JWKS 失败: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(k)
+		if err != nil {
+			// 跳过无法识别的公钥，不影响其他公钥
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	r.mu.Lock()
+	r.keys = keys
+	r.fetchedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// parseJWK 将 JWK 转换为公钥
+func parseJWK(k jwk) (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("不支持的曲线: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("不支持的密钥类型: %s", k.Kty)
+	}
+}
+
+// decodeBigInt 解码 base64url 编码的大整数
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, errors.New("缺少密钥参数")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// claimString 将声明值转换为字符串
+func claimString(val any) (string, bool) {
+	switch v := val.(type) {
+	case string:
+		return v, true
+	case float64:
+		return fmt.Sprint(v), true
+	case bool:
+		return fmt.Sprint(v), true
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := claimString(item); ok {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ","), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/session"
+)
+
+var (
+	_ session.Provider       = (*Provider)(nil)
+	_ session.ClaimsVerifier = (*Provider)(nil)
+)
+
+// ctxIdentityKey 在 Context 中存储外部身份的 key
+const ctxIdentityKey = "gint:external_identity"
+
+// Provider 外部身份适配 Provider
+// 优先使用内部 Provider 校验 gint 自身签发的 Token，失败时再交给 ClaimsResolver 校验外部令牌，
+// 并为外部主体挂载一个 gint 会话，使业务代码在迁移期间可以统一使用 session API
+type Provider struct {
+	inner    session.Provider
+	attacher session.Attacher
+	resolver session.ClaimsResolver
+	carrier  session.TokenCarrier
+}
+
+// NewProvider 创建外部身份适配 Provider
+// inner: 内部 Provider，必须实现 session.Attacher（redis、memory Provider 均已实现）
+// resolver: 外部令牌解析器，如 NewJWKSResolver
+// carrier: 外部令牌的载体，通常为 Authorization Header
+func NewProvider(inner session.Provider, resolver session.ClaimsResolver, carrier session.TokenCarrier) (*Provider, error) {
+	attacher, ok := inner.(session.Attacher)
+	if !ok {
+		return nil, errors.New("内部 Provider 未实现 session.Attacher")
+	}
+	return &Provider{
+		inner:    inner,
+		attacher: attacher,
+		resolver: resolver,
+		carrier:  carrier,
+	}, nil
+}
+
+// NewSession 创建 gint 自身的会话（委托给内部 Provider）
+func (p *Provider) NewSession(ctx *gctx.Context, userId string, jwtData map[string]string, sessData map[string]any) (session.Session, error) {
+	return p.inner.NewSession(ctx, userId, jwtData, sessData)
+}
+
+// Get 获取会话
+// 先尝试 gint Token，失败后尝试外部令牌
+func (p *Provider) Get(ctx *gctx.Context) (session.Session, error) {
+	if val, exists := ctx.Get(session.CtxSessionKey); exists {
+		if sess, ok := val.(session.Session); ok {
+			return sess, nil
+		}
+	}
+
+	sess, innerErr := p.inner.Get(ctx)
+	if innerErr == nil {
+		return sess, nil
+	}
+
+	identity, err := p.resolve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("验证 Token 失败: %w", errors.Join(innerErr, err))
+	}
+
+	sess, err = p.attacher.Attach(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.Set(session.CtxSessionKey, sess)
+	return sess, nil
+}
+
+// Claims 只校验 Token 并返回 Claims
+// 内部 Provider 支持 ClaimsVerifier 时先校验 gint Token，失败后校验外部令牌，均不访问会话存储
+func (p *Provider) Claims(ctx *gctx.Context) (*session.Claims, error) {
+	var innerErr error
+	if verifier, ok := p.inner.(session.ClaimsVerifier); ok {
+		claims, err := verifier.Claims(ctx)
+		if err == nil {
+			return claims, nil
+		}
+		innerErr = err
+	}
+
+	identity, err := p.resolve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("验证 Token 失败: %w", errors.Join(innerErr, err))
+	}
+	return &session.Claims{
+		UserId: identity.Subject,
+		SSID:   session.ExternalSSID(identity.Subject),
+		Data:   identity.Data,
+	}, nil
+}
+
+// Destroy 销毁会话
+// 外部令牌只能销毁 gint 侧挂载的会话，外部令牌本身的吊销由身份提供方负责
+func (p *Provider) Destroy(ctx *gctx.Context) error {
+	if err := p.inner.Destroy(ctx); err == nil {
+		return nil
+	}
+
+	identity, err := p.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return p.attacher.Detach(ctx, identity.Subject)
+}
+
+// RenewToken 刷新 gint 自身的 Token（外部令牌由身份提供方刷新）
+func (p *Provider) RenewToken(ctx *gctx.Context) error {
+	return p.inner.RenewToken(ctx)
+}
+
+// Identity 获取当前请求的外部身份，请求使用 gint Token 时返回 false
+func Identity(ctx *gctx.Context) (*session.ExternalIdentity, bool) {
+	val, exists := ctx.Get(ctxIdentityKey)
+	if !exists {
+		return nil, false
+	}
+	identity, ok := val.(*session.ExternalIdentity)
+	return identity, ok
+}
+
+// resolve 解析当前请求的外部令牌，结果在请求内缓存
+func (p *Provider) resolve(ctx *gctx.Context) (*session.ExternalIdentity, error) {
+	if identity, ok := Identity(ctx); ok {
+		return identity, nil
+	}
+
+	token := p.carrier.Extract(ctx)
+	if token == "" {
+		return nil, errors.New("未找到 Token")
+	}
+
+	identity, err := p.resolver.Resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if identity.Subject == "" {
+		return nil, errors.New("外部令牌缺少主体标识")
+	}
+
+	ctx.Set(ctxIdentityKey, identity)
+	return identity, nil
+}
@@ -16,24 +16,35 @@ package memory
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/ink-code/gint/gctx"
 	"github.com/ink-code/gint/internal/jwt"
+	"github.com/ink-code/gint/internal/supervisor"
 	"github.com/ink-code/gint/session"
 
 	"github.com/google/uuid"
 )
 
+var (
+	_ session.Provider       = (*Provider)(nil)
+	_ session.Attacher       = (*Provider)(nil)
+	_ session.ClaimsVerifier = (*Provider)(nil)
+)
+
 // Provider 内存 Session Provider
 // 注意：仅用于开发测试，生产环境请使用 Redis
 type Provider struct {
 	jwtManager jwt.Manager
 	expiration time.Duration
+	idle       time.Duration // 空闲超时，0 表示不限制
+	codec      session.Codec // 会话数据的序列化方式，为 nil 时直接存储原始值
 	carrier    session.TokenCarrier
 	sessions   map[string]*Session // sessionID -> Session
 	mu         sync.RWMutex
+	cleaner    *supervisor.Supervisor // 清理过期 Session 的后台协程
 }
 
 // NewProvider 创建内存 Session Provider
@@ -50,8 +61,42 @@ func NewProvider(jwtKey string, accessExpire, refreshExpire time.Duration, carri
 	}
 
 	// 启动定期清理过期 Session 的协程
-	go p.cleanExpiredSessions()
+	p.cleaner = supervisor.Go("memory-session-cleaner", p.cleanExpiredSessions)
+
+	return p
+}
+
+// WithIdleTimeout 设置空闲超时
+// Session 超过 idle 未被访问（Get）时被销毁，与 Refresh Token 的有效期相互独立；刷新 Token 不计为活动
+func (p *Provider) WithIdleTimeout(idle time.Duration) *Provider {
+	p.idle = idle
+	return p
+}
 
+// WithLegacyKeys 更换签名密钥或算法时，在迁移窗口内继续接受旧密钥签发的 Token（只用于验签），新 Token 使用新密钥签发
+// 使用旧密钥验签成功时调用 onUse（可以为 nil），可用于上报指标，确认旧 Token 已不再出现后移除旧密钥
+// 旧 Refresh Token 刷新后得到新密钥签发的 Token 对，用户无需重新登录
+//
+// 示例:
+//
+//	provider := memory.NewProvider(newKey, 30*time.Minute, 7*24*time.Hour, carrier).
+//	   WithLegacyKeys(func(name string) {
+//	      legacyTokenCounter.WithLabelValues(name).Inc()
+//	   }, session.LegacyKey{Name: "2024", Key: oldKey, Until: time.Now().Add(7 * 24 * time.Hour)})
+func (p *Provider) WithLegacyKeys(onUse func(name string), keys ...session.LegacyKey) *Provider {
+	p.jwtManager = p.jwtManager.WithLegacyKeys(onUse, keys...)
+	return p
+}
+
+// LegacyTokenUsage 返回各旧密钥验签成功的次数，键为 LegacyKey.Name
+func (p *Provider) LegacyTokenUsage() map[string]uint64 {
+	return p.jwtManager.LegacyUsage()
+}
+
+// WithCodec 设置会话数据的序列化方式，默认直接存储原始值
+// 设置为与 Redis Provider 相同的 Codec 后，开发测试时读取到的数据类型与生产环境一致（如数字还原为 float64 或 json.Number）
+func (p *Provider) WithCodec(codec session.Codec) *Provider {
+	p.codec = codec
 	return p
 }
 
@@ -75,11 +120,17 @@ func (p *Provider) NewSession(ctx *gctx.Context, userId string, jwtData map[stri
 	}
 
 	// 创建 Session
+	data, err := encodeData(p.codec, sessData)
+	if err != nil {
+		return nil, err
+	}
 	sess := &Session{
 		id:         sessionId,
 		claims:     &claims,
-		data:       sessData,
+		data:       data,
 		expireTime: time.Now().Add(p.expiration),
+		lastActive: time.Now(),
+		codec:      p.codec,
 	}
 
 	// 存储到内存
@@ -131,13 +182,32 @@ func (p *Provider) Get(ctx *gctx.Context) (session.Session, error) {
 		return nil, ErrSessionExpired
 	}
 
+	// 超过空闲时间未访问
+	if p.idleExpired(sess, now) {
+		sess.mu.Unlock()
+		p.mu.Lock()
+		delete(p.sessions, claims.SSID)
+		p.mu.Unlock()
+		return nil, session.ErrSessionIdle
+	}
+
 	// 自动续期
 	sess.expireTime = now.Add(p.expiration)
+	sess.lastActive = now
 	sess.mu.Unlock()
 
 	return sess, nil
 }
 
+// Claims 只校验 Token 并返回 Claims，不检查内存中的 Session
+func (p *Provider) Claims(ctx *gctx.Context) (*session.Claims, error) {
+	token := p.carrier.Extract(ctx)
+	if token == "" {
+		return nil, errors.New("token not found")
+	}
+	return p.jwtManager.VerifyToken(token)
+}
+
 // Destroy 销毁 Session
 func (p *Provider) Destroy(ctx *gctx.Context) error {
 	// 提取 Token
@@ -187,9 +257,16 @@ func (p *Provider) RenewToken(ctx *gctx.Context) error {
 	}
 
 	// 检查是否过期
-	if time.Now().After(sess.expireTime) {
+	now := time.Now()
+	sess.mu.RLock()
+	expired, idle := now.After(sess.expireTime), p.idleExpired(sess, now)
+	sess.mu.RUnlock()
+	if expired {
 		return errors.New("会话已过期")
 	}
+	if idle {
+		return session.ErrSessionIdle
+	}
 
 	// 生成新的 Token 对
 	tokenPair, err := p.jwtManager.GenerateTokenPair(*claims)
@@ -211,12 +288,72 @@ func (p *Provider) RenewToken(ctx *gctx.Context) error {
 	return nil
 }
 
+// Attach 获取或创建外部主体对应的 Session
+func (p *Provider) Attach(ctx *gctx.Context, identity *session.ExternalIdentity) (session.Session, error) {
+	ssid := session.ExternalSSID(identity.Subject)
+	now := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// 已存在且未过期时续期并更新 Claims
+	if sess, ok := p.sessions[ssid]; ok {
+		sess.mu.Lock()
+		if now.Before(sess.expireTime) && !p.idleExpired(sess, now) {
+			sess.expireTime = now.Add(p.expiration)
+			sess.lastActive = now
+			sess.claims = &jwt.Claims{UserId: identity.Subject, SSID: ssid, Data: identity.Data}
+			sess.mu.Unlock()
+			return sess, nil
+		}
+		sess.mu.Unlock()
+	}
+
+	data, err := encodeData(p.codec, map[string]any{"user_id": identity.Subject})
+	if err != nil {
+		return nil, err
+	}
+	sess := &Session{
+		id:         ssid,
+		claims:     &jwt.Claims{UserId: identity.Subject, SSID: ssid, Data: identity.Data},
+		data:       data,
+		expireTime: now.Add(p.expiration),
+		lastActive: now,
+		codec:      p.codec,
+	}
+	// SSID 固定，重新登录时与 NewSession 一样更换 CSRF Token
+	if _, err := session.RotateSessionCSRFToken(ctx, sess); err != nil {
+		return nil, fmt.Errorf("更换 CSRF Token 失败: %w", err)
+	}
+	p.sessions[ssid] = sess
+	return sess, nil
+}
+
+// Detach 销毁外部主体对应的 Session
+func (p *Provider) Detach(ctx *gctx.Context, subject string) error {
+	p.mu.Lock()
+	delete(p.sessions, session.ExternalSSID(subject))
+	p.mu.Unlock()
+	return nil
+}
+
+// Close 停止后台清理协程
+func (p *Provider) Close() error {
+	return p.cleaner.Close()
+}
+
 // cleanExpiredSessions 定期清理过期的 Session
-func (p *Provider) cleanExpiredSessions() {
+func (p *Provider) cleanExpiredSessions(stop <-chan struct{}) {
 	ticker := time.NewTicker(time.Minute * 5) // 每 5 分钟清理一次
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
 		now := time.Now()
 
 		// 先收集过期的 Session ID（避免在持有锁时检查每个 Session）
@@ -224,8 +361,7 @@ func (p *Provider) cleanExpiredSessions() {
 
 		p.mu.RLock()
 		for id, sess := range p.sessions {
-			// 不加锁快速检查（过期时间只会延后，不会提前）
-			if now.After(sess.expireTime) {
+			if p.expired(sess, now) {
 				expiredIDs = append(expiredIDs, id)
 			}
 		}
@@ -237,7 +373,7 @@ func (p *Provider) cleanExpiredSessions() {
 			for _, id := range expiredIDs {
 				// 再次检查，因为可能在这期间被续期了
 				if sess, ok := p.sessions[id]; ok {
-					if now.After(sess.expireTime) {
+					if p.expired(sess, now) {
 						delete(p.sessions, id)
 					}
 				}
@@ -246,3 +382,16 @@ func (p *Provider) cleanExpiredSessions() {
 		}
 	}
 }
+
+// expired 判断 Session 是否已过期或超过空闲时间，供清理协程使用
+// 过期时间和最后访问时间由 Get、Touch 等在 sess.mu 下修改，这里同样需要加锁读取
+func (p *Provider) expired(sess *Session, now time.Time) bool {
+	sess.mu.RLock()
+	defer sess.mu.RUnlock()
+	return now.After(sess.expireTime) || p.idleExpired(sess, now)
+}
+
+// idleExpired 判断 Session 是否超过空闲时间，调用方需要持有 sess.mu
+func (p *Provider) idleExpired(sess *Session, now time.Time) bool {
+	return p.idle > 0 && now.Sub(sess.lastActive) > p.idle
+}
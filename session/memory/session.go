@@ -15,12 +15,15 @@
 package memory
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/ink-code/gint/internal/jwt"
+	"github.com/ink-code/gint/session"
 )
 
 var (
@@ -28,12 +31,16 @@ var (
 	ErrSessionExpired  = errors.New("session expired")
 )
 
+var _ session.CASSession = (*Session)(nil)
+
 // Session 内存 Session 实现
 type Session struct {
 	id         string
 	claims     *jwt.Claims
 	data       map[string]any
 	expireTime time.Time
+	lastActive time.Time     // 最后一次通过 Get 访问的时间，用于空闲超时
+	codec      session.Codec // 会话数据的序列化方式，为 nil 时直接存储原始值
 	mu         sync.RWMutex
 }
 
@@ -57,6 +64,9 @@ func (s *Session) Get(ctx context.Context, key string) (any, error) {
 		return nil, errors.New("key not found")
 	}
 
+	if s.codec != nil {
+		return s.codec.Unmarshal(val.([]byte))
+	}
 	return val, nil
 }
 
@@ -70,10 +80,60 @@ func (s *Session) Set(ctx context.Context, key string, val any) error {
 		return ErrSessionExpired
 	}
 
+	if s.codec != nil {
+		data, err := session.Canonical(s.codec, val)
+		if err != nil {
+			return err
+		}
+		val = data
+	}
 	s.data[key] = val
 	return nil
 }
 
+// CompareAndSet 当前值等于 expected 时设置为 val
+// 未设置 Codec 时使用 reflect.DeepEqual 比较原始值，否则比较规范形式的序列化结果（见 session.Canonical）
+func (s *Session) CompareAndSet(ctx context.Context, key string, expected, val any) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 检查是否过期
+	if time.Now().After(s.expireTime) {
+		return false, ErrSessionExpired
+	}
+
+	cur, exists := s.data[key]
+	if expected == nil {
+		if exists {
+			return false, nil
+		}
+	} else if !exists {
+		return false, nil
+	} else if s.codec == nil {
+		if !reflect.DeepEqual(cur, expected) {
+			return false, nil
+		}
+	} else {
+		want, err := session.Canonical(s.codec, expected)
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(cur.([]byte), want) {
+			return false, nil
+		}
+	}
+
+	if s.codec != nil {
+		data, err := session.Canonical(s.codec, val)
+		if err != nil {
+			return false, err
+		}
+		val = data
+	}
+	s.data[key] = val
+	return true, nil
+}
+
 // Del 删除 Session 数据
 func (s *Session) Del(ctx context.Context, key string) error {
 	s.mu.Lock()
@@ -110,3 +170,23 @@ func (s *Session) Refresh(ctx context.Context) error {
 	// 延长过期时间（由 Provider 控制具体时长）
 	return nil
 }
+
+// encodeData 使用 codec 序列化会话数据，codec 为 nil 时原样返回
+func encodeData(codec session.Codec, data map[string]any) (map[string]any, error) {
+	if codec == nil {
+		if data == nil {
+			// 创建时未传入数据，后续 Set 需要可写的 map
+			data = make(map[string]any)
+		}
+		return data, nil
+	}
+	encoded := make(map[string]any, len(data))
+	for key, val := range data {
+		b, err := session.Canonical(codec, val)
+		if err != nil {
+			return nil, err
+		}
+		encoded[key] = b
+	}
+	return encoded, nil
+}
@@ -19,7 +19,10 @@
 package redis
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,7 +33,11 @@ import (
 	"github.com/ink-code/gint/session"
 )
 
-var _ session.Provider = (*Provider)(nil)
+var (
+	_ session.Provider       = (*Provider)(nil)
+	_ session.Attacher       = (*Provider)(nil)
+	_ session.ClaimsVerifier = (*Provider)(nil)
+)
 
 // Provider Redis Session 提供者
 type Provider struct {
@@ -38,8 +45,33 @@ type Provider struct {
 	jwtManager   jwt.Manager
 	tokenCarrier session.TokenCarrier
 	expiration   time.Duration
+	idle         time.Duration // 空闲超时，0 表示不限制
+	codec        session.Codec // 会话数据的序列化方式
+	maxSessions  int           // 会话总数上限，0 表示不限制
+	maxPerUser   int           // 单个用户的会话数上限，0 表示使用默认值（见 perUserLimit）
 }
 
+// lastActiveField 会话数据中记录最后活动时间（Unix 秒）的字段
+const lastActiveField = "last_active"
+
+// touchScript 检查会话的空闲时间并更新最后活动时间
+// 返回 -1 表示会话不存在，-2 表示超过空闲时间（会话已删除），0 表示正常
+var touchScript = redis.NewScript(`
+local last = redis.call('HGET', KEYS[1], ARGV[4])
+if not last then
+	if redis.call('EXISTS', KEYS[1]) == 0 then
+		return -1
+	end
+elseif tonumber(ARGV[1]) - tonumber(last) > tonumber(ARGV[2]) then
+	redis.call('DEL', KEYS[1])
+	return -2
+end
+if ARGV[3] == '1' then
+	redis.call('HSET', KEYS[1], ARGV[4], ARGV[1])
+end
+return 0
+`)
+
 // NewProvider 创建 Redis Session 提供者
 // client: Redis 客户端
 // jwtKey: JWT 签名密钥
@@ -52,9 +84,46 @@ func NewProvider(client redis.Cmdable, jwtKey string, accessExpire, refreshExpir
 		jwtManager:   jwt.NewManager(jwt.NewOptions(jwtKey, accessExpire, refreshExpire)),
 		tokenCarrier: tokenCarrier,
 		expiration:   refreshExpire, // Session 过期时间使用 Refresh Token 的过期时间
+		codec:        session.JSONCodec,
 	}
 }
 
+// WithIdleTimeout 设置空闲超时
+// 会话超过 idle 未被访问（Get）时被销毁，与 Refresh Token 的有效期相互独立；刷新 Token 不计为活动
+// 适用于管理后台等要求“30 分钟无操作自动退出”的场景
+func (p *Provider) WithIdleTimeout(idle time.Duration) *Provider {
+	p.idle = idle
+	return p
+}
+
+// WithLegacyKeys 更换签名密钥或算法时，在迁移窗口内继续接受旧密钥签发的 Token（只用于验签），新 Token 使用新密钥签发
+// 使用旧密钥验签成功时调用 onUse（可以为 nil），可用于上报指标，确认旧 Token 已不再出现后移除旧密钥
+// 旧 Refresh Token 刷新后得到新密钥签发的 Token 对，用户无需重新登录
+//
+// 示例:
+//
+//	provider := redis.NewProvider(client, newKey, 30*time.Minute, 7*24*time.Hour, carrier).
+//	   WithLegacyKeys(func(name string) {
+//	      legacyTokenCounter.WithLabelValues(name).Inc()
+//	   }, session.LegacyKey{Name: "2024", Key: oldKey, Until: time.Now().Add(7 * 24 * time.Hour)})
+func (p *Provider) WithLegacyKeys(onUse func(name string), keys ...session.LegacyKey) *Provider {
+	p.jwtManager = p.jwtManager.WithLegacyKeys(onUse, keys...)
+	return p
+}
+
+// LegacyTokenUsage 返回各旧密钥验签成功的次数，键为 LegacyKey.Name
+func (p *Provider) LegacyTokenUsage() map[string]uint64 {
+	return p.jwtManager.LegacyUsage()
+}
+
+// WithCodec 设置会话数据的序列化方式，默认为 session.JSONCodec
+// 会话中存储 int64 ID 等大整数时使用 session.NumberJSONCodec，避免还原为 float64 后丢失精度
+// 更换序列化方式后，已有会话中的数据需要能被新的方式解析
+func (p *Provider) WithCodec(codec session.Codec) *Provider {
+	p.codec = codec
+	return p
+}
+
 // NewSession 创建新会话
 func (p *Provider) NewSession(ctx *gctx.Context, userId string, jwtData map[string]string, sessData map[string]any) (session.Session, error) {
 	// 生成 Session ID
@@ -80,7 +149,7 @@ func (p *Provider) NewSession(ctx *gctx.Context, userId string, jwtData map[stri
 	ctx.Context.Header("X-Refresh-Token", tokenPair.RefreshToken)
 
 	// 创建 Session
-	sess := newSession(ssid, p.expiration, p.client, &claims)
+	sess := newSession(ssid, p.expiration, p.client, &claims, p.codec)
 
 	// 初始化 Session 数据
 	if sessData == nil {
@@ -88,10 +157,15 @@ func (p *Provider) NewSession(ctx *gctx.Context, userId string, jwtData map[stri
 	}
 	sessData["user_id"] = userId
 	sessData["created_at"] = time.Now().Unix()
+	sessData[lastActiveField] = time.Now().Unix()
 
 	if err := sess.init(ctx, sessData); err != nil {
 		return nil, fmt.Errorf("初始化会话失败: %w", err)
 	}
+	if err := p.track(ctx, userId, ssid); err != nil {
+		p.discard(ctx, ssid)
+		return nil, err
+	}
 
 	return sess, nil
 }
@@ -118,15 +192,11 @@ func (p *Provider) Get(ctx *gctx.Context) (session.Session, error) {
 	}
 
 	// 创建 Session
-	sess := newSession(claims.SSID, p.expiration, p.client, claims)
+	sess := newSession(claims.SSID, p.expiration, p.client, claims, p.codec)
 
-	// 验证 Session 是否存在
-	exists, err := p.client.Exists(ctx, sessionKey(claims.SSID)).Result()
-	if err != nil {
-		return nil, fmt.Errorf("检查会话失败: %w", err)
-	}
-	if exists == 0 {
-		return nil, fmt.Errorf("会话不存在或已过期")
+	// 验证 Session 是否存在且未超过空闲时间
+	if err := p.checkActive(ctx, claims.SSID, true); err != nil {
+		return nil, err
 	}
 
 	// 将 Session 存储到上下文中
@@ -135,6 +205,21 @@ func (p *Provider) Get(ctx *gctx.Context) (session.Session, error) {
 	return sess, nil
 }
 
+// Claims 只校验 Token 并返回 Claims，不检查 Redis 中的会话
+// 注意：会话被销毁后，Access Token 在过期前仍然可以通过校验
+func (p *Provider) Claims(ctx *gctx.Context) (*session.Claims, error) {
+	token := p.tokenCarrier.Extract(ctx)
+	if token == "" {
+		return nil, fmt.Errorf("未找到 Token")
+	}
+
+	claims, err := p.jwtManager.VerifyToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("验证 Token 失败: %w", err)
+	}
+	return claims, nil
+}
+
 // Destroy 销毁会话
 func (p *Provider) Destroy(ctx *gctx.Context) error {
 	// 获取会话
@@ -147,7 +232,10 @@ func (p *Provider) Destroy(ctx *gctx.Context) error {
 	p.tokenCarrier.Clear(ctx)
 
 	// 销毁 Session
-	return sess.Destroy(ctx)
+	if err := sess.Destroy(ctx); err != nil {
+		return err
+	}
+	return p.untrack(ctx, sess.Claims().UserId, sess.Claims().SSID)
 }
 
 // RenewToken 刷新 Token（使用 Refresh Token 获取新的 Access Token）
@@ -164,13 +252,9 @@ func (p *Provider) RenewToken(ctx *gctx.Context) error {
 		return fmt.Errorf("验证 Refresh Token 失败: %w", err)
 	}
 
-	// 验证 Session 是否存在
-	exists, err := p.client.Exists(ctx, sessionKey(claims.SSID)).Result()
-	if err != nil {
-		return fmt.Errorf("检查会话失败: %w", err)
-	}
-	if exists == 0 {
-		return fmt.Errorf("会话不存在或已过期")
+	// 验证 Session 是否存在且未超过空闲时间（刷新 Token 不计为活动）
+	if err := p.checkActive(ctx, claims.SSID, false); err != nil {
+		return err
 	}
 
 	// 生成新的 Token 对
@@ -188,3 +272,96 @@ func (p *Provider) RenewToken(ctx *gctx.Context) error {
 	// 刷新 Redis 中的过期时间
 	return p.client.Expire(ctx, sessionKey(claims.SSID), p.expiration).Err()
 }
+
+// Attach 获取或创建外部主体对应的会话
+func (p *Provider) Attach(ctx *gctx.Context, identity *session.ExternalIdentity) (session.Session, error) {
+	ssid := session.ExternalSSID(identity.Subject)
+	claims := &jwt.Claims{
+		UserId: identity.Subject,
+		SSID:   ssid,
+		Data:   identity.Data,
+	}
+	sess := newSession(ssid, p.expiration, p.client, claims, p.codec)
+
+	// 会话不存在或超过空闲时间时重新创建（身份由外部 Token 保证）
+	err := p.checkActive(ctx, ssid, true)
+	if err != nil && !errors.Is(err, errSessionNotFound) && !errors.Is(err, session.ErrSessionIdle) {
+		return nil, err
+	}
+
+	if err != nil {
+		// 首次访问，创建会话
+		if err := sess.init(ctx, map[string]any{
+			"user_id":       identity.Subject,
+			"created_at":    time.Now().Unix(),
+			lastActiveField: time.Now().Unix(),
+			"external":      true,
+		}); err != nil {
+			return nil, fmt.Errorf("初始化会话失败: %w", err)
+		}
+		if err := p.track(ctx, identity.Subject, ssid); err != nil {
+			p.discard(ctx, ssid)
+			return nil, err
+		}
+		// SSID 固定，会话 key 可能仍保留着上次登录的 CSRF Token，重新登录时更换
+		if _, err := session.RotateSessionCSRFToken(ctx, sess); err != nil {
+			return nil, fmt.Errorf("更换 CSRF Token 失败: %w", err)
+		}
+	} else if err := sess.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("刷新会话失败: %w", err)
+	}
+
+	return sess, nil
+}
+
+// Detach 销毁外部主体对应的会话
+func (p *Provider) Detach(ctx *gctx.Context, subject string) error {
+	ssid := session.ExternalSSID(subject)
+	if err := p.client.Del(ctx, sessionKey(ssid)).Err(); err != nil {
+		return err
+	}
+	return p.untrack(ctx, subject, ssid)
+}
+
+// discard 删除记录失败的新会话，避免留下不受会话数上限约束的会话
+func (p *Provider) discard(ctx context.Context, ssid string) {
+	if err := p.client.Del(context.WithoutCancel(ctx), sessionKey(ssid)).Err(); err != nil {
+		slog.Error("删除会话失败", slog.String("ssid", ssid), slog.Any("err", err))
+	}
+}
+
+// errSessionNotFound 会话不存在或已过期
+var errSessionNotFound = errors.New("会话不存在或已过期")
+
+// checkActive 检查会话是否存在且未超过空闲时间，touch 为 true 时更新最后活动时间
+// 未设置空闲超时时只检查会话是否存在
+func (p *Provider) checkActive(ctx context.Context, ssid string, touch bool) error {
+	key := sessionKey(ssid)
+	if p.idle <= 0 {
+		exists, err := p.client.Exists(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("检查会话失败: %w", err)
+		}
+		if exists == 0 {
+			return errSessionNotFound
+		}
+		return nil
+	}
+
+	flag := "0"
+	if touch {
+		flag = "1"
+	}
+	ret, err := touchScript.Run(ctx, p.client, []string{key},
+		time.Now().Unix(), int64(p.idle.Seconds()), flag, lastActiveField).Int()
+	if err != nil {
+		return fmt.Errorf("检查会话失败: %w", err)
+	}
+	switch ret {
+	case -1:
+		return errSessionNotFound
+	case -2:
+		return session.ErrSessionIdle
+	}
+	return nil
+}
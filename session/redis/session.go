@@ -20,7 +20,6 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -30,7 +29,30 @@ import (
 	"github.com/ink-code/gint/session"
 )
 
-var _ session.Session = (*Session)(nil)
+var (
+	_ session.Session    = (*Session)(nil)
+	_ session.CASSession = (*Session)(nil)
+)
+
+// casScript 比较并设置会话数据
+// ARGV: 字段名、期望值、期望值是否为不存在（1/0）、新值、过期时间（秒）
+// 返回 -1 表示会话不存在，0 表示当前值与期望值不同，1 表示设置成功
+var casScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if ARGV[3] == '1' then
+	if cur then
+		return 0
+	end
+elseif cur ~= ARGV[2] then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[4])
+redis.call('EXPIRE', KEYS[1], ARGV[5])
+return 1
+`)
 
 // Session Redis 会话实现
 type Session struct {
@@ -38,14 +60,15 @@ type Session struct {
 	key        string        // Redis key
 	claims     *jwt.Claims   // JWT 声明
 	expiration time.Duration // 过期时间
+	codec      session.Codec // 会话数据的序列化方式
 }
 
 // Set 设置会话数据
 func (s *Session) Set(ctx context.Context, key string, val any) error {
-	// 将值序列化为 JSON
-	data, err := json.Marshal(val)
+	// 序列化为规范形式，与 CompareAndSet 比较的形式一致
+	data, err := session.Canonical(s.codec, val)
 	if err != nil {
-		return fmt.Errorf("序列化数据失败: %w", err)
+		return err
 	}
 
 	// 存储到 Redis
@@ -68,8 +91,8 @@ func (s *Session) Get(ctx context.Context, key string) (any, error) {
 		return nil, fmt.Errorf("获取数据失败: %w", err)
 	}
 
-	var result any
-	if err := json.Unmarshal([]byte(data), &result); err != nil {
+	result, err := s.codec.Unmarshal([]byte(data))
+	if err != nil {
 		// 如果反序列化失败，直接返回字符串
 		return data, nil
 	}
@@ -77,6 +100,36 @@ func (s *Session) Get(ctx context.Context, key string) (any, error) {
 	return result, nil
 }
 
+// CompareAndSet 当前值等于 expected 时设置为 val，通过 Lua 脚本保证原子性
+// expected 和 val 都按规范形式序列化（见 session.Canonical），因此 expected 可以直接使用 Get 读取到的值
+func (s *Session) CompareAndSet(ctx context.Context, key string, expected, val any) (bool, error) {
+	data, err := session.Canonical(s.codec, val)
+	if err != nil {
+		return false, err
+	}
+
+	var (
+		expectedData []byte
+		absent       = "1"
+	)
+	if expected != nil {
+		if expectedData, err = session.Canonical(s.codec, expected); err != nil {
+			return false, err
+		}
+		absent = "0"
+	}
+
+	ret, err := casScript.Run(ctx, s.client, []string{s.key},
+		key, expectedData, absent, data, int64(s.expiration.Seconds())).Int()
+	if err != nil {
+		return false, fmt.Errorf("存储数据失败: %w", err)
+	}
+	if ret == -1 {
+		return false, errSessionNotFound
+	}
+	return ret == 1, nil
+}
+
 // Del 删除会话数据
 func (s *Session) Del(ctx context.Context, key string) error {
 	return s.client.HDel(ctx, s.key, key).Err()
@@ -107,11 +160,16 @@ func (s *Session) init(ctx context.Context, data map[string]any) error {
 	pipe := s.client.Pipeline()
 
 	for key, val := range data {
-		jsonData, err := json.Marshal(val)
+		// 最后活动时间由 Lua 脚本读取和更新，始终以数字字符串存储，不经过 codec
+		if key == lastActiveField {
+			pipe.HSet(ctx, s.key, key, val)
+			continue
+		}
+		encoded, err := session.Canonical(s.codec, val)
 		if err != nil {
-			return fmt.Errorf("序列化数据失败: %w", err)
+			return err
 		}
-		pipe.HSet(ctx, s.key, key, jsonData)
+		pipe.HSet(ctx, s.key, key, encoded)
 	}
 
 	// 设置过期时间
@@ -123,16 +181,17 @@ func (s *Session) init(ctx context.Context, data map[string]any) error {
 }
 
 // newSession 创建新的 Redis Session
-func newSession(ssid string, expiration time.Duration, client redis.Cmdable, claims *jwt.Claims) *Session {
+func newSession(ssid string, expiration time.Duration, client redis.Cmdable, claims *jwt.Claims, codec session.Codec) *Session {
 	return &Session{
 		client:     client,
 		key:        sessionKey(ssid),
 		claims:     claims,
 		expiration: expiration,
+		codec:      codec,
 	}
 }
 
 // sessionKey 生成 Session 的 Redis key
 func sessionKey(ssid string) string {
-	return "gint:session:" + ssid
+	return sessionKeyPrefix + ssid
 }
@@ -0,0 +1,237 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// sessionKeyPrefix 会话 key 的前缀，见 sessionKey
+const sessionKeyPrefix = "gint:session:"
+
+// sessionIndexKey 开启会话数上限后记录会话创建时间的有序集合
+const sessionIndexKey = "gint:session_index"
+
+// scanBatch 每次 SCAN 的 COUNT
+const scanBatch = 1000
+
+// Usage 会话存储的占用情况
+type Usage struct {
+	Sessions   int64            // 会话总数
+	Sampled    int              // 抽样统计内存的会话数
+	AvgBytes   int64            // 抽样会话的平均内存占用（字节）
+	MaxBytes   int64            // 抽样中最大的单个会话（字节）
+	TotalBytes int64            // 估算的总内存占用，AvgBytes * Sessions
+	ByPrefix   map[string]int64 // 按 SSID 前缀统计的会话数，见 UsageOptions.Prefixes
+}
+
+// UsageOptions 统计选项
+type UsageOptions struct {
+	// SampleSize 抽样执行 MEMORY USAGE 的会话数，<= 0 时为 100
+	SampleSize int
+	// Prefixes 需要单独计数的 SSID 前缀，如 "ext:" 统计外部身份的会话（见 session.ExternalSSID）
+	Prefixes []string
+}
+
+// Usage 统计会话数和内存占用，用于在 Redis 达到 maxmemory 之前发现容量问题
+// 通过 SCAN 遍历所有会话 key，只对前 SampleSize 个执行 MEMORY USAGE，结果是估算值
+// 注意：会遍历整个 keyspace，会话较多时耗时较长，应在后台定期执行而不是放在请求路径上；
+// 集群模式下 SCAN 只覆盖单个节点，需要对每个主节点分别统计
+//
+// 示例:
+//
+//	usage, err := provider.Usage(ctx, redis.UsageOptions{Prefixes: []string{"ext:"}})
+//	slog.Info("会话占用", slog.Int64("sessions", usage.Sessions), slog.Int64("bytes", usage.TotalBytes))
+func (p *Provider) Usage(ctx context.Context, opts UsageOptions) (Usage, error) {
+	sampleSize := opts.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = 100
+	}
+
+	usage := Usage{ByPrefix: make(map[string]int64, len(opts.Prefixes))}
+	for _, prefix := range opts.Prefixes {
+		usage.ByPrefix[prefix] = 0
+	}
+
+	var sampledBytes int64
+	iter := p.client.Scan(ctx, 0, sessionKeyPrefix+"*", scanBatch).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		usage.Sessions++
+
+		ssid := strings.TrimPrefix(key, sessionKeyPrefix)
+		for _, prefix := range opts.Prefixes {
+			if strings.HasPrefix(ssid, prefix) {
+				usage.ByPrefix[prefix]++
+			}
+		}
+
+		if usage.Sampled >= sampleSize {
+			continue
+		}
+		n, err := p.client.MemoryUsage(ctx, key).Result()
+		if err == redis.Nil {
+			// 遍历期间过期的会话
+			continue
+		}
+		if err != nil {
+			return Usage{}, fmt.Errorf("统计会话内存失败: %w", err)
+		}
+		usage.Sampled++
+		sampledBytes += n
+		usage.MaxBytes = max(usage.MaxBytes, n)
+	}
+	if err := iter.Err(); err != nil {
+		return Usage{}, fmt.Errorf("遍历会话失败: %w", err)
+	}
+
+	if usage.Sampled > 0 {
+		usage.AvgBytes = sampledBytes / int64(usage.Sampled)
+		usage.TotalBytes = usage.AvgBytes * usage.Sessions
+	}
+	return usage, nil
+}
+
+// CountSessions 统计 SSID 以 prefix 开头的会话数，prefix 为空时统计全部会话
+// 与 Usage 一样通过 SCAN 遍历，不适合放在请求路径上
+func (p *Provider) CountSessions(ctx context.Context, prefix string) (int64, error) {
+	var count int64
+	iter := p.client.Scan(ctx, 0, sessionKeyPrefix+prefix+"*", scanBatch).Iterator()
+	for iter.Next(ctx) {
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		return 0, fmt.Errorf("遍历会话失败: %w", err)
+	}
+	return count, nil
+}
+
+// userIndexKeyPrefix 开启会话数上限后记录每个用户会话创建时间的有序集合的前缀
+const userIndexKeyPrefix = "gint:user_sessions:"
+
+// defaultMaxSessionsPerUser 开启会话总数上限但未设置单个用户上限时，每个用户的会话数上限
+const defaultMaxSessionsPerUser = 10
+
+// trackScript 记录新会话，先淘汰该用户超出上限的会话，再在总数超过上限时淘汰最早创建的会话
+// KEYS: 总索引 key、用户索引 key；
+// ARGV: 当前时间（Unix 秒）、SSID、会话有效期（秒）、会话总数上限（0 不限制）、会话 key 前缀、单个用户会话数上限（0 不限制）
+// 返回 {淘汰的该用户会话数, 因总数超限淘汰的会话数}
+var trackScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[3])
+local userEvicted, evicted = 0, 0
+if tonumber(ARGV[6]) > 0 then
+	redis.call('ZADD', KEYS[2], now, ARGV[2])
+	redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now - ttl)
+	redis.call('EXPIRE', KEYS[2], ttl)
+	-- 移除已被销毁或已过期的会话，避免占用用户的名额
+	for _, ssid in ipairs(redis.call('ZRANGE', KEYS[2], 0, -1)) do
+		if ssid ~= ARGV[2] and redis.call('EXISTS', ARGV[5] .. ssid) == 0 then
+			redis.call('ZREM', KEYS[2], ssid)
+		end
+	end
+	local excess = redis.call('ZCARD', KEYS[2]) - tonumber(ARGV[6])
+	if excess > 0 then
+		local popped = redis.call('ZPOPMIN', KEYS[2], excess)
+		for i = 1, #popped, 2 do
+			redis.call('DEL', ARGV[5] .. popped[i])
+			redis.call('ZREM', KEYS[1], popped[i])
+		end
+		userEvicted = excess
+	end
+end
+if tonumber(ARGV[4]) > 0 then
+	redis.call('ZADD', KEYS[1], now, ARGV[2])
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - ttl)
+	local excess = redis.call('ZCARD', KEYS[1]) - tonumber(ARGV[4])
+	if excess > 0 then
+		local popped = redis.call('ZPOPMIN', KEYS[1], excess)
+		for i = 1, #popped, 2 do
+			redis.call('DEL', ARGV[5] .. popped[i])
+		end
+		evicted = excess
+	end
+end
+return {userEvicted, evicted}
+`)
+
+// WithMaxSessions 限制会话总数，创建会话后超过 max 时淘汰最早创建的会话，被淘汰的用户需要重新登录
+// 会话的创建时间记录在有序集合 gint:session_index 中，过期的记录在创建会话时清理
+// 注意：淘汰不区分用户，能够反复登录的人（如持有大量账号或可以自助注册）可以借此把其他用户挤下线；
+// 因此同时限制单个用户的会话数，未通过 WithMaxSessionsPerUser 设置时为 10，总数上限应远大于活跃用户数，只作为容量兜底
+// 注意：脚本会删除未在 KEYS 中声明的会话 key，Redis 集群模式下不能使用
+func (p *Provider) WithMaxSessions(max int) *Provider {
+	p.maxSessions = max
+	return p
+}
+
+// WithMaxSessionsPerUser 限制单个用户的会话数，创建会话后超过 max 时淘汰该用户最早创建的会话
+// 每个用户的会话记录在有序集合 gint:user_sessions:<用户 ID> 中；可以单独使用，也可以与 WithMaxSessions 一起使用
+// 注意：脚本会删除未在 KEYS 中声明的会话 key，Redis 集群模式下不能使用
+func (p *Provider) WithMaxSessionsPerUser(max int) *Provider {
+	p.maxPerUser = max
+	return p
+}
+
+// perUserLimit 返回单个用户的会话数上限，0 表示不限制
+func (p *Provider) perUserLimit() int {
+	if p.maxPerUser == 0 && p.maxSessions > 0 {
+		return defaultMaxSessionsPerUser
+	}
+	return max(p.maxPerUser, 0)
+}
+
+// track 开启会话数上限时记录新会话，并淘汰超出上限的会话
+func (p *Provider) track(ctx context.Context, userId, ssid string) error {
+	perUser := p.perUserLimit()
+	if p.maxSessions <= 0 && perUser == 0 {
+		return nil
+	}
+	ret, err := trackScript.Run(ctx, p.client, []string{sessionIndexKey, userIndexKeyPrefix + userId},
+		time.Now().Unix(), ssid, int64(p.expiration.Seconds()), max(p.maxSessions, 0), sessionKeyPrefix, perUser).Int64Slice()
+	if err != nil {
+		return fmt.Errorf("记录会话失败: %w", err)
+	}
+	if ret[0] > 0 {
+		slog.Info("用户会话数超过上限，已淘汰该用户最早创建的会话",
+			slog.String("user_id", userId),
+			slog.Int64("evicted", ret[0]),
+			slog.Int("max", perUser))
+	}
+	if ret[1] > 0 {
+		slog.Warn("会话数超过上限，已淘汰最早创建的会话",
+			slog.Int64("evicted", ret[1]),
+			slog.Int("max", p.maxSessions))
+	}
+	return nil
+}
+
+// untrack 会话销毁时从索引中移除
+func (p *Provider) untrack(ctx context.Context, userId, ssid string) error {
+	if p.maxSessions <= 0 && p.perUserLimit() == 0 {
+		return nil
+	}
+	pipe := p.client.Pipeline()
+	pipe.ZRem(ctx, sessionIndexKey, ssid)
+	pipe.ZRem(ctx, userIndexKeyPrefix+userId, ssid)
+	_, err := pipe.Exec(ctx)
+	return err
+}
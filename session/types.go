@@ -20,6 +20,7 @@ package session
 
 import (
 	"context"
+	"errors"
 	"sync/atomic"
 
 	"github.com/ink-code/gint/gctx"
@@ -29,8 +30,21 @@ import (
 const (
 	// CtxSessionKey 在 Context 中存储 Session 的 key
 	CtxSessionKey = "gint:session"
+
+	// CtxClaimsKey 在 Context 中存储已校验 Claims 的 key
+	CtxClaimsKey = "gint:claims"
 )
 
+// ErrSessionIdle 会话超过空闲时间未被访问，已被销毁
+var ErrSessionIdle = errors.New("会话长时间未活动，已失效")
+
+// Claims JWT 声明数据
+// 导出 internal/jwt 中的类型，以便业务代码在函数签名中引用
+type Claims = jwt.Claims
+
+// LegacyKey 更换签名密钥或算法期间只用于验签的旧密钥，见 Provider 的 WithLegacyKeys
+type LegacyKey = jwt.LegacyKey
+
 // Session 会话接口
 // 混合了 JWT 的设计，轻量数据存储在 JWT 中，完整数据存储在 Redis 中
 type Session interface {
@@ -74,6 +88,43 @@ type Provider interface {
 	RenewToken(ctx *gctx.Context) error
 }
 
+// ClaimsVerifier 支持只校验 Token 的 Provider
+// 仅验证 JWT 签名和有效期并返回 Claims，不访问会话存储，适用于高频只读接口
+type ClaimsVerifier interface {
+	// Claims 校验当前请求的 Token 并返回 Claims
+	Claims(ctx *gctx.Context) (*Claims, error)
+}
+
+// ExternalIdentity 外部身份提供方（如 Keycloak、Auth0）签发的令牌中解析出的身份
+type ExternalIdentity struct {
+	// Subject 外部主体标识（通常为 sub 声明），用作 gint 会话的用户 ID
+	Subject string
+	// Data 需要放入会话 Claims 的轻量数据（如角色、租户）
+	Data map[string]string
+}
+
+// ClaimsResolver 外部令牌解析器
+// 负责校验外部身份提供方签发的令牌（如通过 JWKS 验签）并解析出身份信息
+type ClaimsResolver interface {
+	// Resolve 校验令牌并返回身份信息，令牌无效时返回错误
+	Resolve(ctx context.Context, token string) (*ExternalIdentity, error)
+}
+
+// Attacher 支持按外部主体挂载会话的 Provider
+// 会话以外部主体标识为键，首次访问时创建，之后复用；不会签发 gint 的 Token
+type Attacher interface {
+	// Attach 获取或创建外部主体对应的会话
+	Attach(ctx *gctx.Context, identity *ExternalIdentity) (Session, error)
+
+	// Detach 销毁外部主体对应的会话
+	Detach(ctx *gctx.Context, subject string) error
+}
+
+// ExternalSSID 生成外部主体对应的 Session ID
+func ExternalSSID(subject string) string {
+	return "ext:" + subject
+}
+
 // TokenCarrier Token 载体接口
 // 定义了如何在请求中携带和提取 Token
 type TokenCarrier interface {
@@ -105,11 +156,61 @@ func getDefaultProvider() Provider {
 }
 
 // Get 使用默认 Provider 获取 Session
+// 设置了 AccountStatusChecker 时同时检查账号状态
 func Get(ctx *gctx.Context) (Session, error) {
-	return getDefaultProvider().Get(ctx)
+	sess, err := getDefaultProvider().Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAccount(ctx.Request.Context(), sess.Claims().UserId); err != nil {
+		return nil, err
+	}
+	return sess, nil
 }
 
 // NewSession 使用默认 Provider 创建 Session
 func NewSession(ctx *gctx.Context, userId string, jwtData map[string]string, sessData map[string]any) (Session, error) {
 	return getDefaultProvider().NewSession(ctx, userId, jwtData, sessData)
 }
+
+// GetClaims 使用默认 Provider 校验 Token 并获取 Claims
+// Provider 实现了 ClaimsVerifier 时不访问会话存储，否则退化为 Get 获取完整会话
+// 结果会缓存在 Context 中；设置了 AccountStatusChecker 时同时检查账号状态
+func GetClaims(ctx *gctx.Context) (*Claims, error) {
+	if val, exists := ctx.Get(CtxClaimsKey); exists {
+		if claims, ok := val.(*Claims); ok {
+			return claims, nil
+		}
+	}
+
+	var claims *Claims
+	if verifier, ok := getDefaultProvider().(ClaimsVerifier); ok {
+		var err error
+		if claims, err = verifier.Claims(ctx); err != nil {
+			return nil, err
+		}
+		if err := checkAccount(ctx.Request.Context(), claims.UserId); err != nil {
+			return nil, err
+		}
+	} else {
+		sess, err := Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		claims = sess.Claims()
+	}
+
+	ctx.Set(CtxClaimsKey, claims)
+	return claims, nil
+}
+
+// UserId 返回当前请求的用户 ID，用于按用户隔离缓存、幂等键等数据
+// 优先使用 Session 中的用户；未配置 Provider 或未登录时使用 gctx.Context.SetUserId 设置的 ID，都没有时返回空字符串
+func UserId(ctx *gctx.Context) string {
+	if defaultProvider.Load() != nil {
+		if claims, err := GetClaims(ctx); err == nil {
+			return claims.UserId
+		}
+	}
+	return ctx.UserId()
+}
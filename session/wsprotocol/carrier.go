@@ -0,0 +1,131 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wsprotocol 通过 Sec-WebSocket-Protocol 请求头携带 Token 的载体
+// 浏览器的 WebSocket API 不能设置自定义请求头，常见做法是把 Token 作为子协议传递：
+//
+//	new WebSocket(url, ["access_token", token])
+//
+// 服务端在握手响应中必须回显客户端提供的某个子协议，否则浏览器会关闭连接
+package wsprotocol
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/session"
+)
+
+var _ session.TokenCarrier = (*Carrier)(nil)
+
+// HeaderProtocol WebSocket 子协议请求头
+const HeaderProtocol = "Sec-WebSocket-Protocol"
+
+// Carrier 基于 Sec-WebSocket-Protocol 的 Token 载体
+// 子协议列表中标记协议的下一项为 Token；请求中没有该标记时使用 fallback 提取，
+// 因此同一个 Provider 可以同时服务普通 HTTP 接口和 WebSocket 握手
+type Carrier struct {
+	protocol string               // 标记协议名称，同时作为握手响应中回显的子协议
+	fallback session.TokenCarrier // 普通请求使用的载体，为 nil 时只支持 WebSocket 握手
+}
+
+// NewCarrier 创建 WebSocket 子协议 Token 载体，标记协议名称为 "access_token"
+// fallback 为普通 HTTP 请求使用的载体（如 header.NewCarrier()），Inject、Clear 也交给 fallback 处理
+//
+// 示例:
+//
+//	carrier := wsprotocol.NewCarrier(header.NewCarrier())
+//	provider := redis.NewProvider(client, jwtKey, accessExpire, refreshExpire, carrier)
+func NewCarrier(fallback session.TokenCarrier) *Carrier {
+	return NewCarrierWithProtocol("access_token", fallback)
+}
+
+// NewCarrierWithProtocol 创建自定义标记协议名称的 Token 载体
+func NewCarrierWithProtocol(protocol string, fallback session.TokenCarrier) *Carrier {
+	return &Carrier{
+		protocol: protocol,
+		fallback: fallback,
+	}
+}
+
+// Inject 交给 fallback 注入 Token
+// WebSocket 握手无法向浏览器下发新的 Token，登录、刷新 Token 等接口应通过普通 HTTP 请求完成
+func (c *Carrier) Inject(ctx *gctx.Context, token string) {
+	if c.fallback != nil {
+		c.fallback.Inject(ctx, token)
+	}
+}
+
+// Extract 从子协议列表中提取 Token，没有标记协议时使用 fallback 提取
+func (c *Carrier) Extract(ctx *gctx.Context) string {
+	if token, ok := c.extract(ctx.Request); ok {
+		return token
+	}
+	if c.fallback != nil {
+		return c.fallback.Extract(ctx)
+	}
+	return ""
+}
+
+// Clear 交给 fallback 清除 Token
+func (c *Carrier) Clear(ctx *gctx.Context) {
+	if c.fallback != nil {
+		c.fallback.Clear(ctx)
+	}
+}
+
+// Protocol 握手响应中需要回显的子协议
+// 用于 WebSocket 库的子协议配置，如 coder/websocket 的 AcceptOptions.Subprotocols
+func (c *Carrier) Protocol() string {
+	return c.protocol
+}
+
+// ResponseHeader 握手响应头，请求通过子协议携带了 Token 时包含回显的 Sec-WebSocket-Protocol，否则返回 nil
+// 传给 gorilla/websocket 的 Upgrader.Upgrade，Token 本身不会出现在响应中
+//
+// 示例:
+//
+//	router.GET("/ws", gint.S(func(ctx *gctx.Context, sess session.Session) (gint.Result, error) {
+//	   conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, carrier.ResponseHeader(ctx))
+//	   if err != nil {
+//	      return gint.Result{}, gint.ErrNoResponse
+//	   }
+//	   go serve(conn, sess)
+//	   return gint.Result{}, gint.ErrNoResponse
+//	}))
+func (c *Carrier) ResponseHeader(ctx *gctx.Context) http.Header {
+	if _, ok := c.extract(ctx.Request); !ok {
+		return nil
+	}
+	h := make(http.Header)
+	h.Set(HeaderProtocol, c.protocol)
+	return h
+}
+
+// extract 从子协议列表中提取标记协议后的 Token
+func (c *Carrier) extract(r *http.Request) (string, bool) {
+	var protocols []string
+	for _, value := range r.Header.Values(HeaderProtocol) {
+		for _, p := range strings.Split(value, ",") {
+			protocols = append(protocols, strings.TrimSpace(p))
+		}
+	}
+	for i, p := range protocols {
+		if p == c.protocol && i+1 < len(protocols) {
+			return protocols[i+1], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ink-code/gint/gctx"
+)
+
+// defaultHeartbeat 默认的心跳间隔
+const defaultHeartbeat = 15 * time.Second
+
+// Event Server-Sent Events 事件
+type Event struct {
+	ID    string        // 事件 ID，客户端重连时通过 Last-Event-ID 带回
+	Event string        // 事件类型，为空时客户端按 message 处理
+	Data  any           // 事件数据，string/[]byte 原样发送，其余类型序列化为 JSON
+	Retry time.Duration // 建议客户端的重连间隔，0 表示不设置
+
+	// Origin 发布事件的请求的追踪 ID，为空时由 Hub.Publish 根据 ctx 填充（需要开启 SetTraceIDFunc）
+	// 只用于服务端的投递日志，不发送给客户端
+	Origin string
+}
+
+// SendFunc 发送事件的函数，客户端断开后返回错误
+type SendFunc func(ev Event) error
+
+// WithHeartbeat 设置 Stream 的心跳间隔，0 或负数表示关闭心跳
+// 心跳以 SSE 注释行发送，用于保持连接并尽早发现客户端断开
+func WithHeartbeat(interval time.Duration) Option {
+	return func(o *options) {
+		o.heartbeat = interval
+		o.heartbeatSet = true
+	}
+}
+
+// Stream SSE 流式响应包装器
+// 自动设置 SSE 响应头、定期发送心跳、在客户端断开时让 send 返回错误，
+// 处理函数返回非取消类错误时会向客户端发送 error 事件后结束响应
+//
+// 示例:
+//
+//	router.GET("/progress", gint.Stream(func(ctx *gctx.Context, send gint.SendFunc) error {
+//	   for i := 0; i <= 100; i += 10 {
+//	      if err := send(gint.Event{Event: "progress", Data: i}); err != nil {
+//	         return err
+//	      }
+//	      time.Sleep(time.Second)
+//	   }
+//	   return nil
+//	}))
+func Stream(fn func(ctx *gctx.Context, send SendFunc) error, opts ...Option) gin.HandlerFunc {
+	o := newOptions(fn, opts)
+	heartbeat := defaultHeartbeat
+	if o.heartbeatSet {
+		heartbeat = o.heartbeat
+	}
+
+	return func(c *gin.Context) {
+		ctx := newContext(c, o)
+		reqCtx := c.Request.Context()
+
+		// 设置 SSE 响应头
+		c.Writer.Header().Set("Content-Type", "text/event-stream")
+		c.Writer.Header().Set("Cache-Control", "no-cache")
+		c.Writer.Header().Set("Connection", "keep-alive")
+		c.Writer.Header().Set("X-Accel-Buffering", "no")
+		c.Writer.WriteHeaderNow()
+		c.Writer.Flush()
+
+		// 心跳与业务事件可能并发写入，需要加锁
+		var mu sync.Mutex
+		write := func(data []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if err := reqCtx.Err(); err != nil {
+				return err
+			}
+			if _, err := c.Writer.Write(data); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+			return nil
+		}
+
+		// 定期发送心跳
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		defer func() {
+			close(done)
+			wg.Wait()
+		}()
+		if heartbeat > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(heartbeat)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ticker.C:
+						if write([]byte(": ping\n\n")) != nil {
+							return
+						}
+					case <-done:
+						return
+					case <-reqCtx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		send := func(ev Event) error {
+			data, err := encodeEvent(ev)
+			if err != nil {
+				return err
+			}
+			return write(data)
+		}
+
+		start := time.Now()
+		err := fn(ctx, send)
+		observe(c, o, start, 0, err)
+		if err == nil || errors.Is(err, context.Canceled) || reqCtx.Err() != nil {
+			slog.Debug("流式响应结束", withTrace(c, []any{
+				slog.String("path", c.Request.URL.Path),
+				slog.Any("err", err)})...)
+			return
+		}
+
+		// 处理函数出错，通知客户端后结束
+		slog.Error("流式响应失败", withTrace(c, []any{
+			slog.String("path", c.Request.URL.Path),
+			slog.Any("err", err)})...)
+		_ = send(Event{Event: "error", Data: err.Error()})
+	}
+}
+
+// encodeEvent 将事件编码为 SSE 格式
+// Origin 是发布方请求的追踪 ID，只用于服务端日志，不发送给客户端
+func encodeEvent(ev Event) ([]byte, error) {
+	payload, err := eventPayload(ev.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if ev.ID != "" {
+		buf.WriteString("id: " + singleLine(ev.ID) + "\n")
+	}
+	if ev.Event != "" {
+		buf.WriteString("event: " + singleLine(ev.Event) + "\n")
+	}
+	if ev.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+	// 多行数据需要拆分为多个 data 字段；EventSource 把 \r、\r\n 同样视为换行，先统一为 \n，避免数据中注入 event、id 等字段
+	payload = strings.ReplaceAll(payload, "\r\n", "\n")
+	payload = strings.ReplaceAll(payload, "\r", "\n")
+	for _, line := range strings.Split(payload, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// eventPayload 将事件数据转换为文本，string/[]byte 原样返回，其余类型序列化为 JSON
+func eventPayload(data any) (string, error) {
+	switch data := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return data, nil
+	case []byte:
+		return string(data), nil
+	default:
+		b, err := json.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("序列化事件数据失败: %w", err)
+		}
+		return string(b), nil
+	}
+}
+
+// singleLine 去除换行符，防止注入额外的 SSE 字段
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
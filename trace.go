@@ -0,0 +1,81 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TraceIDFunc 获取当前请求的追踪 ID，返回空字符串表示没有
+type TraceIDFunc func(c *gin.Context) string
+
+// traceIDFunc 全局追踪 ID 获取函数，为 nil 时不输出追踪 ID
+var traceIDFunc atomic.Pointer[TraceIDFunc]
+
+// SetTraceIDFunc 开启追踪 ID 输出，传入 nil 关闭
+// 开启后包装器将追踪 ID 写入响应的 trace_id 字段，并附加到包装器输出的每条日志中，便于跨服务关联错误
+//
+// 示例:
+//
+//	gint.SetTraceIDFunc(gint.DefaultTraceID)
+func SetTraceIDFunc(fn TraceIDFunc) {
+	if fn == nil {
+		traceIDFunc.Store(nil)
+		return
+	}
+	traceIDFunc.Store(&fn)
+}
+
+// DefaultTraceID 默认的追踪 ID 获取函数，依次查找：
+//   - Context 中的 trace_id、request_id（由链路追踪或请求 ID 中间件设置）
+//   - X-Request-ID 请求头
+//   - W3C traceparent 请求头中的 trace-id
+func DefaultTraceID(c *gin.Context) string {
+	for _, key := range []string{"trace_id", "request_id"} {
+		if id := c.GetString(key); id != "" {
+			return id
+		}
+	}
+	if id := c.GetHeader("X-Request-ID"); id != "" {
+		return id
+	}
+	// traceparent 格式: version-traceid-parentid-flags
+	if parts := strings.Split(c.GetHeader("traceparent"), "-"); len(parts) == 4 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	return ""
+}
+
+// traceID 获取当前请求的追踪 ID，未开启时返回空字符串
+func traceID(c *gin.Context) string {
+	fn := traceIDFunc.Load()
+	if fn == nil {
+		return ""
+	}
+	return (*fn)(c)
+}
+
+// withTrace 在日志属性前加上追踪 ID
+func withTrace(c *gin.Context, attrs []any) []any {
+	id := traceID(c)
+	if id == "" {
+		return attrs
+	}
+	return append([]any{slog.String("trace_id", id)}, attrs...)
+}
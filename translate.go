@@ -0,0 +1,302 @@
+// Copyright 2025 Light-ink-yht
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// BindError 翻译后的绑定错误
+type BindError struct {
+	Msg    string            // 整体错误信息，拼接在 "参数错误: " 之后
+	Fields map[string]string // 字段级错误信息，key 为 json/form/uri 标签名，嵌套字段以 . 连接
+}
+
+// BindErrorTranslator 绑定错误翻译函数
+// req 为绑定目标的指针，可用于根据结构体标签确定字段名
+type BindErrorTranslator func(req any, err error) BindError
+
+// bindErrorTranslator 全局绑定错误翻译函数
+var bindErrorTranslator atomic.Pointer[BindErrorTranslator]
+
+// SetBindErrorTranslator 设置全局的绑定错误翻译函数，传入 nil 恢复默认实现
+// 可以在自定义实现中处理特定错误，其余错误交给 TranslateBindError
+func SetBindErrorTranslator(fn BindErrorTranslator) {
+	if fn == nil {
+		bindErrorTranslator.Store(nil)
+		return
+	}
+	bindErrorTranslator.Store(&fn)
+}
+
+// WithBindErrorTranslator 为单个接口设置绑定错误翻译函数，优先于全局设置
+func WithBindErrorTranslator(fn BindErrorTranslator) Option {
+	return func(o *options) {
+		o.bindTranslator = fn
+	}
+}
+
+// translateBindError 使用接口或全局配置的翻译函数翻译绑定错误
+func translateBindError(o *options, req any, err error) BindError {
+	if o.bindTranslator != nil {
+		return o.bindTranslator(req, err)
+	}
+	if fn := bindErrorTranslator.Load(); fn != nil {
+		return (*fn)(req, err)
+	}
+	return TranslateBindError(req, err)
+}
+
+// TranslateBindError 默认的绑定错误翻译实现
+// 将 binding 标签的校验错误和 JSON 类型错误转换为按字段组织的中文提示，无法识别的错误保留原始信息
+func TranslateBindError(req any, err error) BindError {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+		numErr         *strconv.NumError
+		sizeErr        *FileSizeError
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make(map[string]string, len(validationErrs))
+		msgs := make([]string, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			name := fieldPath(req, fe)
+			msg := validationMessage(name, fe)
+			if _, ok := fields[name]; !ok {
+				fields[name] = msg
+				msgs = append(msgs, msg)
+			}
+		}
+		return BindError{Msg: strings.Join(msgs, "；"), Fields: fields}
+	case errors.As(err, &typeErr):
+		name := typeErr.Field
+		if name == "" {
+			return BindError{Msg: "请求体格式错误，应为" + kindName(typeErr.Type.Kind())}
+		}
+		msg := fmt.Sprintf("%s的类型错误，应为%s", name, kindName(typeErr.Type.Kind()))
+		return BindError{Msg: msg, Fields: map[string]string{name: msg}}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return BindError{Msg: "请求体不是合法的 JSON"}
+	case errors.As(err, &numErr):
+		return BindError{Msg: fmt.Sprintf("无法将 %q 解析为数值", numErr.Num)}
+	case errors.As(err, &sizeErr):
+		msg := sizeErr.Error()
+		return BindError{Msg: msg, Fields: map[string]string{sizeErr.Field: msg}}
+	}
+
+	// DisallowUnknownFields 开启时的未知字段错误
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		name = strings.Trim(name, `"`)
+		msg := name + "是不支持的字段"
+		return BindError{Msg: msg, Fields: map[string]string{name: msg}}
+	}
+	return BindError{Msg: err.Error()}
+}
+
+// bindFailures 将绑定错误转换为指标中的校验失败信息
+// 与 BindErrorTranslator 无关，规则名取 binding 标签名，如 required、min
+// 字段名来自请求内容（类型错误、未知字段）时，只有请求参数类型中存在的字段才作为标签，其他记为 OtherLabel
+func bindFailures(req any, err error) []ValidationFailure {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		sizeErr        *FileSizeError
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		failures := make([]ValidationFailure, len(validationErrs))
+		for i, fe := range validationErrs {
+			failures[i] = ValidationFailure{Field: labelField(fieldPath(req, fe)), Rule: fe.Tag()}
+		}
+		return failures
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return []ValidationFailure{{Field: knownField(req, typeErr.Field), Rule: "type"}}
+	case errors.As(err, &sizeErr):
+		return []ValidationFailure{{Field: sizeErr.Field, Rule: "maxsize"}}
+	}
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return []ValidationFailure{{Field: knownField(req, strings.Trim(name, `"`)), Rule: "unknown"}}
+	}
+	return []ValidationFailure{{Rule: "malformed"}}
+}
+
+// knownField 字段路径在请求参数类型中存在时返回标签形式的路径，否则返回 OtherLabel
+// encoding/json 的路径中下标和 map 的键是单独的一段，如 items.0.name，转换为 items[*].name
+func knownField(req any, path string) string {
+	t := reflect.TypeOf(req)
+	var names []string
+	for _, seg := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if k := t.Kind(); (k == reflect.Slice || k == reflect.Array || k == reflect.Map) && len(names) > 0 {
+			// 这一段是下标或键
+			t = t.Elem()
+			names[len(names)-1] += "[*]"
+			continue
+		}
+		if t.Kind() != reflect.Struct {
+			return OtherLabel
+		}
+		name, _, _ := strings.Cut(seg, "[")
+		found := false
+		for _, field := range ruleFields(t) {
+			if fieldName(field) == name {
+				t, found = field.Type, true
+				break
+			}
+		}
+		if !found {
+			return OtherLabel
+		}
+		names = append(names, name)
+	}
+	return labelField(strings.Join(names, "."))
+}
+
+// validationMessage 生成单个校验错误的提示
+func validationMessage(name string, fe validator.FieldError) string {
+	param := fe.Param()
+	length := false
+	switch fe.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		length = true
+	}
+
+	// RegisterRule 注册的规则使用规则自身的错误提示
+	if rule, ok := LookupRule(fe.Tag(), param); ok {
+		if err := rule.Validate(fe.Value()); err != nil {
+			return name + err.Error()
+		}
+	}
+
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return name + "不能为空"
+	case "min", "gte":
+		if length {
+			return fmt.Sprintf("%s长度不能小于%s", name, param)
+		}
+		return fmt.Sprintf("%s不能小于%s", name, param)
+	case "max", "lte":
+		if length {
+			return fmt.Sprintf("%s长度不能大于%s", name, param)
+		}
+		return fmt.Sprintf("%s不能大于%s", name, param)
+	case "gt":
+		if length {
+			return fmt.Sprintf("%s长度必须大于%s", name, param)
+		}
+		return fmt.Sprintf("%s必须大于%s", name, param)
+	case "lt":
+		if length {
+			return fmt.Sprintf("%s长度必须小于%s", name, param)
+		}
+		return fmt.Sprintf("%s必须小于%s", name, param)
+	case "len":
+		if length {
+			return fmt.Sprintf("%s长度必须为%s", name, param)
+		}
+		return fmt.Sprintf("%s必须等于%s", name, param)
+	case "eq":
+		return fmt.Sprintf("%s必须等于%s", name, param)
+	case "ne":
+		return fmt.Sprintf("%s不能等于%s", name, param)
+	case "oneof":
+		return fmt.Sprintf("%s必须是 [%s] 中的一个", name, param)
+	case "email":
+		return name + "不是有效的邮箱地址"
+	case "url", "http_url":
+		return name + "不是有效的 URL"
+	case "numeric", "number":
+		return name + "必须是数字"
+	case "alphanum":
+		return name + "只能包含字母和数字"
+	case "datetime":
+		return fmt.Sprintf("%s的格式必须为 %s", name, param)
+	case "eqfield":
+		return fmt.Sprintf("%s必须与%s一致", name, param)
+	}
+	if param != "" {
+		return fmt.Sprintf("%s不满足 %s=%s 校验规则", name, fe.Tag(), param)
+	}
+	return fmt.Sprintf("%s不满足 %s 校验规则", name, fe.Tag())
+}
+
+// fieldPath 根据结构体标签将校验错误的命名空间（如 CreateOrderReq.Items[0].SkuID）转换为请求中的字段名（如 items[0].sku_id）
+func fieldPath(req any, fe validator.FieldError) string {
+	segments := strings.Split(fe.StructNamespace(), ".")
+	if req == nil || len(segments) < 2 {
+		return fe.Field()
+	}
+
+	t := reflect.TypeOf(req)
+	names := make([]string, 0, len(segments)-1)
+	for _, seg := range segments[1:] {
+		field, index, _ := strings.Cut(seg, "[")
+		if index != "" {
+			index = "[" + index
+		}
+
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return fe.Field()
+		}
+		sf, ok := t.FieldByName(field)
+		if !ok {
+			return fe.Field()
+		}
+		t = sf.Type
+		// 未指定标签的嵌入字段在 JSON 中被展开，不出现在路径里
+		if sf.Anonymous && fieldName(sf) == sf.Name {
+			continue
+		}
+		names = append(names, fieldName(sf)+index)
+	}
+	return strings.Join(names, ".")
+}
+
+// kindName 返回类型的中文描述
+func kindName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "字符串"
+	case reflect.Bool:
+		return "布尔值"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "整数"
+	case reflect.Float32, reflect.Float64:
+		return "数字"
+	case reflect.Slice, reflect.Array:
+		return "数组"
+	case reflect.Map, reflect.Struct:
+		return "对象"
+	}
+	return kind.String()
+}
@@ -32,6 +32,12 @@ type Result struct {
 	Code int    `json:"code"` // 业务状态码，0 表示成功
 	Msg  string `json:"msg"`  // 响应消息
 	Data any    `json:"data"` // 响应数据
+
+	TraceID string `json:"trace_id,omitempty"` // 追踪 ID，通过 SetTraceIDFunc 开启后由包装器填充
+
+	Truncated bool `json:"truncated,omitempty"` // 列表数据因超过响应体大小限制被截断，见 WithResponseSizeLimit
+
+	Errors []FieldError `json:"errors,omitempty"` // 结构化的校验错误（字段、错误信息和错误码），由包装器在校验失败时填充
 }
 
 // PageData 用于返回分页查询的数据
@@ -15,8 +15,23 @@
 package gint
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/dlclark/regexp2"
@@ -27,12 +42,54 @@ type ValidationRule interface {
 	Validate(value any) error
 }
 
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`   // 字段名
+	Message string `json:"message"` // 完整的错误信息（包含字段名）
+	Code    string `json:"code"`    // 机器可读的错误码，如 VAL_MIN_LENGTH，见 WithCode
+	Rule    string `json:"-"`       // 未通过的规则名，如 min_length，用于校验失败指标
+}
+
+// ValidationErrors 结构化的校验错误，实现了 error 接口
+// 处理函数直接返回时，包装器响应 400，data 为按字段分组的错误（见 Map）
+type ValidationErrors []FieldError
+
+// Error 用分号连接所有错误信息
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "；")
+}
+
+// Map 按字段分组错误信息，便于前端在对应的输入框旁展示
+func (e ValidationErrors) Map() map[string][]string {
+	m := make(map[string][]string, len(e))
+	for _, fe := range e {
+		m[fe.Field] = append(m[fe.Field], fe.Message)
+	}
+	return m
+}
+
+// failures 转换为指标中的校验失败信息
+// 元素错误的字段名中的下标和键（如 items[3]、tags[color]）记为 [*]，完整路径只保留在错误信息中
+func (e ValidationErrors) failures() []ValidationFailure {
+	out := make([]ValidationFailure, len(e))
+	for i, fe := range e {
+		out[i] = ValidationFailure{Field: labelField(fe.Field), Rule: fe.Rule}
+	}
+	return out
+}
+
 // FieldValidator 字段校验器（建造者模式）
 type FieldValidator struct {
-	fieldName string
-	value     any
-	rules     []ValidationRule
-	errors    []string
+	fieldName   string
+	value       any
+	rules       []ValidationRule
+	errors      []string
+	fieldErrors []FieldError
+	builder     *ValidatorBuilder // 所属的构建器，用于 AfterField 等规则查找其他字段
 }
 
 // NewFieldValidator 创建字段校验器
@@ -52,19 +109,70 @@ func (fv *FieldValidator) AddRule(rule ValidationRule) *FieldValidator {
 }
 
 // Validate 执行校验
+// 所属的构建器开启了 StopOnFirstError 时，在第一个失败的规则处停止
 func (fv *FieldValidator) Validate() []string {
+	failFast := fv.builder != nil && fv.builder.failFast
 	for _, rule := range fv.rules {
-		if err := rule.Validate(fv.value); err != nil {
-			fv.errors = append(fv.errors, fmt.Sprintf("%s%s", fv.fieldName, err.Error()))
+		if failFast && len(fv.errors) > 0 {
+			break
+		}
+
+		check := resolveRule(rule, fv.lookup)
+		err := check.Validate(fv.value)
+		if err == nil {
+			continue
+		}
+
+		// Each、MapKeys、MapValues 规则按元素拆分错误，字段名带上下标或键，如 items[1]、tags[color]
+		var elemErrs ElementErrors
+		if errors.As(err, &elemErrs) {
+			if failFast {
+				elemErrs = elemErrs[:1]
+			}
+			for _, ee := range elemErrs {
+				msg := fmt.Sprintf("%s%s", fv.fieldName, ee.Error())
+				fv.errors = append(fv.errors, msg)
+				fv.fieldErrors = append(fv.fieldErrors, FieldError{
+					Field:   fmt.Sprintf("%s[%s]", fv.fieldName, ee.path()),
+					Message: msg,
+					Code:    ruleCode(failedRule(ee.Rule, ee.Err)),
+					Rule:    ruleName(failedRule(ee.Rule, ee.Err)),
+				})
+			}
+			continue
 		}
+
+		msg := fmt.Sprintf("%s%s", fv.fieldName, err.Error())
+		fv.errors = append(fv.errors, msg)
+		fv.fieldErrors = append(fv.fieldErrors, FieldError{
+			Field:   fv.fieldName,
+			Message: msg,
+			Code:    ruleCode(failedRule(rule, err)),
+			Rule:    ruleName(failedRule(rule, err)),
+		})
 	}
 	return fv.errors
 }
 
+// lookup 查找同一构建器中的其他字段值
+func (fv *FieldValidator) lookup(name string) (any, bool) {
+	if fv.builder == nil {
+		return nil, false
+	}
+	for _, other := range fv.builder.validators {
+		if other.fieldName == name {
+			return other.value, true
+		}
+	}
+	return nil, false
+}
+
 // ValidatorBuilder 校验器构建器（建造者模式）
 type ValidatorBuilder struct {
-	validators []*FieldValidator
-	errors     []string
+	validators  []*FieldValidator
+	errors      []string
+	fieldErrors []FieldError
+	failFast    bool // 是否在第一个错误处停止
 }
 
 // NewValidatorBuilder 创建校验器构建器
@@ -78,15 +186,48 @@ func NewValidatorBuilder() *ValidatorBuilder {
 // Field 添加字段校验
 func (vb *ValidatorBuilder) Field(fieldName string, value any) *FieldValidator {
 	fv := NewFieldValidator(fieldName, value)
+	fv.builder = vb
 	vb.validators = append(vb.validators, fv)
 	return fv
 }
 
+// When 条件校验，cond 为 true 时才添加 fn 中声明的字段校验
+// 条件可以引用请求参数的其他字段
+//
+// 示例:
+//
+//	vb.When(r.InvoiceType == "company", func(vb *gint.ValidatorBuilder) {
+//	   vb.Field("发票抬头", r.InvoiceTitle).AddRule(gint.Required())
+//	   vb.Field("税号", r.TaxNo).AddRule(gint.Required())
+//	})
+func (vb *ValidatorBuilder) When(cond bool, fn func(vb *ValidatorBuilder)) *ValidatorBuilder {
+	if cond {
+		fn(vb)
+	}
+	return vb
+}
+
+// StopOnFirstError 开启快速失败：Validate 在第一个失败的字段和规则处停止，之后的规则（包括 Custom）不再执行
+// 只返回一条错误，适用于校验规则代价较高或只需要提示第一个错误的场景
+//
+// 示例:
+//
+//	vb := gint.NewValidatorBuilder().StopOnFirstError()
+//	vb.Field("用户名", req.Username).AddRule(gint.Required()).AddRule(usernameAvailable(ctx))
+func (vb *ValidatorBuilder) StopOnFirstError() *ValidatorBuilder {
+	vb.failFast = true
+	return vb
+}
+
 // Validate 执行所有校验
 func (vb *ValidatorBuilder) Validate() *ValidatorBuilder {
 	for _, validator := range vb.validators {
+		if vb.failFast && len(vb.errors) > 0 {
+			break
+		}
 		errors := validator.Validate()
 		vb.errors = append(vb.errors, errors...)
+		vb.fieldErrors = append(vb.fieldErrors, validator.fieldErrors...)
 	}
 	return vb
 }
@@ -114,6 +255,249 @@ func (vb *ValidatorBuilder) GetErrorString() string {
 	return strings.Join(vb.errors, "；")
 }
 
+// GetFieldErrors 获取按字段区分的错误
+func (vb *ValidatorBuilder) GetFieldErrors() ValidationErrors {
+	return vb.fieldErrors
+}
+
+// GetErrorMap 获取按字段分组的错误信息
+func (vb *ValidatorBuilder) GetErrorMap() map[string][]string {
+	return ValidationErrors(vb.fieldErrors).Map()
+}
+
+// Err 校验失败时返回 ValidationErrors，通过时返回 nil
+//
+// 示例:
+//
+//	if err := vb.Validate().Err(); err != nil {
+//	   return gint.Result{}, err // 响应 400，data 为按字段分组的错误
+//	}
+func (vb *ValidatorBuilder) Err() error {
+	if len(vb.fieldErrors) == 0 {
+		return nil
+	}
+	return ValidationErrors(vb.fieldErrors)
+}
+
+// ============ 批量校验 ============
+
+// ValidateAll 批量校验，返回每个不合法元素的下标及其字段错误，全部通过时返回空 map
+// 不会在第一个不合法的元素处停止，适用于批量导入时生成逐行的错误报告；rulesFor 返回 nil 表示该元素不需要校验
+//
+// 示例:
+//
+//	errs := gint.ValidateAll(rows, func(row ImportRow) *gint.ValidatorBuilder {
+//	   vb := gint.NewValidatorBuilder()
+//	   vb.Field("手机号", row.Mobile).AddRule(gint.Required()).AddRule(gint.Mobile())
+//	   vb.Field("姓名", row.Name).AddRule(gint.Required())
+//	   return vb
+//	})
+//	for i, fieldErrs := range errs {
+//	   // 第 i+1 行的错误...
+//	}
+func ValidateAll[T any](items []T, rulesFor func(T) *ValidatorBuilder) map[int][]FieldError {
+	result := make(map[int][]FieldError)
+	for i, item := range items {
+		vb := rulesFor(item)
+		if vb == nil {
+			continue
+		}
+		if errs := vb.Validate().GetFieldErrors(); len(errs) > 0 {
+			result[i] = errs
+		}
+	}
+	return result
+}
+
+// ============ 请求参数自校验 ============
+
+// Validatable 可自校验的请求参数
+// B/BS 绑定参数成功后会自动调用 Validate，返回非空错误列表时响应 400
+type Validatable interface {
+	Validate() []string
+}
+
+// BuilderValidatable 使用 ValidatorBuilder 描述校验逻辑的请求参数
+// B/BS 绑定参数成功后会自动执行返回的构建器，校验失败时响应 400
+//
+// 示例:
+//
+//	func (r RegisterReq) Validation() *gint.ValidatorBuilder {
+//	   vb := gint.NewValidatorBuilder()
+//	   vb.Field("用户名", r.Username).AddRule(gint.Username())
+//	   return vb
+//	}
+type BuilderValidatable interface {
+	Validation() *ValidatorBuilder
+}
+
+// RulesValidatable 以声明方式描述校验规则的请求参数
+// 键为字段对外的名称（依次取 json、form、uri 标签，都没有时为字段名），同时作为错误信息中的字段名；
+// B/BS 绑定参数成功后按字段顺序构建 ValidatorBuilder 并执行，校验失败时响应 400。
+// 注意：B/BS 创建时会以零值调用一次 Rules 检查字段名，键不对应任何字段时 panic
+//
+// 示例:
+//
+//	func (r RegisterReq) Rules() map[string][]gint.ValidationRule {
+//	   return map[string][]gint.ValidationRule{
+//	      "username":         {gint.Username()},
+//	      "password":         {gint.Required(), gint.StrongPassword()},
+//	      "confirm_password": {gint.EqualsField("password")},
+//	   }
+//	}
+type RulesValidatable interface {
+	Rules() map[string][]ValidationRule
+}
+
+// validate 对实现了 Validatable、BuilderValidatable 或 RulesValidatable 的请求参数执行校验
+// req 应为指向请求参数的指针，以便同时识别值接收者和指针接收者的实现
+// 同时返回 ValidatorBuilder 中按字段记录的错误，用于校验失败指标
+func validate(req any) ([]string, ValidationErrors) {
+	var (
+		errs      []string
+		fieldErrs ValidationErrors
+	)
+	if v, ok := req.(Validatable); ok {
+		errs = append(errs, v.Validate()...)
+	}
+	builders := make([]*ValidatorBuilder, 0, 2)
+	if v, ok := req.(BuilderValidatable); ok {
+		builders = append(builders, v.Validation())
+	}
+	if v, ok := req.(RulesValidatable); ok {
+		builders = append(builders, rulesBuilder(req, v.Rules()))
+	}
+	for _, vb := range builders {
+		if vb != nil {
+			errs = append(errs, vb.Validate().GetErrors()...)
+			fieldErrs = append(fieldErrs, vb.GetFieldErrors()...)
+		}
+	}
+	return errs, fieldErrs
+}
+
+// rulesBuilder 按结构体字段顺序为 Rules 声明的规则构建 ValidatorBuilder
+// 所有导出字段都会加入构建器，以便 EqualsField 等规则引用没有声明规则的字段；
+// 非空指针取其指向的值，空指针传入 nil，使 Required 等规则按未填写处理
+func rulesBuilder(req any, rules map[string][]ValidationRule) *ValidatorBuilder {
+	v := reflect.Indirect(reflect.ValueOf(req))
+	vb := NewValidatorBuilder()
+	for _, field := range ruleFields(v.Type()) {
+		var val any
+		value, err := v.FieldByIndexErr(field.Index)
+		switch {
+		case err != nil:
+			// 嵌入的结构体指针为 nil，其中的字段视为未填写
+		case value.Kind() == reflect.Pointer && value.IsNil():
+		case value.Kind() == reflect.Pointer:
+			val = value.Elem().Interface()
+		default:
+			val = value.Interface()
+		}
+		fv := vb.Field(fieldName(field), val)
+		for _, rule := range rules[fieldName(field)] {
+			fv.AddRule(rule)
+		}
+	}
+	return vb
+}
+
+// ruleFields 返回可以在 Rules 中声明规则的字段：导出的字段以及嵌入结构体（如 PageRequest）提升的字段
+func ruleFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for _, field := range reflect.VisibleFields(t) {
+		if field.IsExported() && !field.Anonymous {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// checkRules 检查请求参数类型的 Rules 声明的字段是否存在，并解析上传文件字段的 maxsize 标签，B/BS 创建时调用
+func checkRules[Req any]() {
+	fileLimitsOf(reflect.TypeFor[Req]())
+
+	var req Req
+	v, ok := any(&req).(RulesValidatable)
+	if !ok || reflect.TypeFor[Req]().Kind() != reflect.Struct {
+		return
+	}
+	names := make(map[string]bool)
+	t := reflect.TypeFor[Req]()
+	for _, field := range ruleFields(t) {
+		names[fieldName(field)] = true
+	}
+	for name := range v.Rules() {
+		if !names[name] {
+			panic(fmt.Sprintf("gint: %s.Rules 中的字段 %s 不存在", t.Name(), name))
+		}
+	}
+}
+
+// ruleName 根据规则类型生成规则名，如 *MinLengthRule 为 min_length
+func ruleName(rule ValidationRule) string {
+	switch r := rule.(type) {
+	case *MessageRule:
+		return ruleName(r.rule)
+	case *CodeRule:
+		return ruleName(r.rule)
+	case *RegisteredRule:
+		return r.name
+	case *NotRule:
+		return "not_" + ruleName(r.rule)
+	case *RelativeTimeRule:
+		if r.after {
+			return "after_now"
+		}
+		return "before_now"
+	case *TimeFieldRule:
+		if r.after {
+			return "after_field"
+		}
+		return "before_field"
+	case *FieldCompareRule:
+		return [...]string{
+			cmpEqual:          "equals_field",
+			cmpGreater:        "greater_than_field",
+			cmpGreaterOrEqual: "greater_or_equal_field",
+			cmpLess:           "less_than_field",
+			cmpLessOrEqual:    "less_or_equal_field",
+		}[r.op]
+	case *IPRule:
+		if r.version != 0 {
+			return "ipv" + strconv.Itoa(r.version)
+		}
+	case *CharClassRule:
+		name := [...]string{charAlpha: "alpha", charAlphaNumeric: "alpha_numeric", charNumeric: "numeric"}[r.class]
+		if r.unicode {
+			name += "_unicode"
+		}
+		return name
+	}
+	t := reflect.TypeOf(rule)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name, _, _ := strings.Cut(t.Name(), "[") // 去掉泛型参数，如 RangeOfRule[float64]
+	name = strings.TrimSuffix(name, "Rule")
+	if name == "" {
+		return "custom"
+	}
+	// 连续的大写字母视为一个缩写，如 URL、UUID、CIDR
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
 // ============ 具体的校验规则实现（策略模式） ============
 
 // RequiredRule 必填规则
@@ -260,22 +644,65 @@ func (r *MobileRule) Validate(value any) error {
 // Mobile 手机号规则构造函数
 func Mobile() ValidationRule {
 	return &MobileRule{
-		regex: regexp2.MustCompile(`^1[3-9]\d{9}$`, 0),
+		regex: regexp2.MustCompile(`^1[3-9][0-9]{9}$`, 0),
 	}
 }
 
-// URLRule URL 规则
-type URLRule struct {
-	regex *regexp2.Regexp
+// phoneRegion 地区的电话号码规则
+type phoneRegion struct {
+	code    string // 国家/地区代码，如 86
+	trunk   string // 国内拨号时的长途前缀，如 0，没有时为空
+	pattern string // 去掉国家代码和长途前缀后的号码格式
 }
 
-func (r *URLRule) Validate(value any) error {
+// phoneRegions 支持的地区，键为 ISO 3166-1 两位字母代码
+// 号码格式只校验长度和开头的数字，不区分手机和固定电话
+var phoneRegions = map[string]phoneRegion{
+	"CN": {code: "86", trunk: "0", pattern: `1[3-9][0-9]{9}|(?:10|2[0-9]|[3-9][0-9]{2})[0-9]{7,8}`},
+	"HK": {code: "852", pattern: `[2-9][0-9]{7}`},
+	"MO": {code: "853", pattern: `[268][0-9]{7}`},
+	"TW": {code: "886", trunk: "0", pattern: `9[0-9]{8}|[2-8][0-9]{7,8}`},
+	"US": {code: "1", trunk: "1", pattern: `[2-9][0-9]{2}[2-9][0-9]{6}`},
+	"CA": {code: "1", trunk: "1", pattern: `[2-9][0-9]{2}[2-9][0-9]{6}`},
+	"GB": {code: "44", trunk: "0", pattern: `[1-9][0-9]{8,9}`},
+	"DE": {code: "49", trunk: "0", pattern: `[1-9][0-9]{5,13}`},
+	"FR": {code: "33", trunk: "0", pattern: `[1-9][0-9]{8}`},
+	"JP": {code: "81", trunk: "0", pattern: `[1-9][0-9]{8,9}`},
+	"KR": {code: "82", trunk: "0", pattern: `[1-9][0-9]{7,9}`},
+	"SG": {code: "65", pattern: `[3689][0-9]{7}`},
+	"MY": {code: "60", trunk: "0", pattern: `1[0-9]{8,9}|[3-9][0-9]{7,8}`},
+	"TH": {code: "66", trunk: "0", pattern: `[2-9][0-9]{7,8}`},
+	"VN": {code: "84", trunk: "0", pattern: `[1-9][0-9]{8,9}`},
+	"IN": {code: "91", trunk: "0", pattern: `[1-9][0-9]{9}`},
+	"AU": {code: "61", trunk: "0", pattern: `[2-478][0-9]{8}`},
+}
+
+// phoneSeparators 号码中允许的分隔符
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+// PhoneRule 电话号码规则
+type PhoneRule struct {
+	region phoneRegion
+	regex  *regexp2.Regexp
+}
+
+func (r *PhoneRule) Validate(value any) error {
 	str, ok := value.(string)
 	if !ok || str == "" {
 		return nil
 	}
 
-	matched, _ := r.regex.MatchString(str)
+	number := phoneSeparators.Replace(str)
+	if intl, ok := strings.CutPrefix(number, "+"); ok {
+		// 国际格式必须是该地区的国家代码
+		if number, ok = strings.CutPrefix(intl, r.region.code); !ok {
+			return fmt.Errorf("格式不正确")
+		}
+	} else if r.region.trunk != "" {
+		number = strings.TrimPrefix(number, r.region.trunk)
+	}
+
+	matched, _ := r.regex.MatchString(number)
 	if !matched {
 		return fmt.Errorf("格式不正确")
 	}
@@ -283,20 +710,27 @@ func (r *URLRule) Validate(value any) error {
 	return nil
 }
 
-// URL URL 规则构造函数
-func URL() ValidationRule {
-	return &URLRule{
-		regex: regexp2.MustCompile(`^https?://[^\s]+$`, 0),
+// Phone 电话号码规则构造函数，region 为 ISO 3166-1 两位字母代码，如 "US"、"GB"、"HK"
+// 同时接受国内格式（如 020 7946 0958）和带国家代码的国际格式（如 +44 20 7946 0958），允许空格、连字符、括号和点作为分隔符
+// 只校验长度和开头的数字，不区分手机和固定电话；需要限定中国大陆手机号时使用 Mobile
+// 不支持的地区 panic，不限地区时使用 E164
+func Phone(region string) ValidationRule {
+	r, ok := phoneRegions[strings.ToUpper(region)]
+	if !ok {
+		panic(fmt.Sprintf("gint: Phone 不支持地区 %s", region))
+	}
+	return &PhoneRule{
+		region: r,
+		regex:  regexp2.MustCompile(`^(?:`+r.pattern+`)$`, 0),
 	}
 }
 
-// PatternRule 正则规则
-type PatternRule struct {
-	regex  *regexp2.Regexp
-	errMsg string
+// E164Rule E.164 国际电话号码规则
+type E164Rule struct {
+	regex *regexp2.Regexp
 }
 
-func (r *PatternRule) Validate(value any) error {
+func (r *E164Rule) Validate(value any) error {
 	str, ok := value.(string)
 	if !ok || str == "" {
 		return nil
@@ -304,156 +738,1610 @@ func (r *PatternRule) Validate(value any) error {
 
 	matched, _ := r.regex.MatchString(str)
 	if !matched {
-		if r.errMsg != "" {
-			return fmt.Errorf("%s", r.errMsg)
-		}
 		return fmt.Errorf("格式不正确")
 	}
 
 	return nil
 }
 
-// Pattern 正则规则构造函数
-func Pattern(pattern string, errMsg ...string) ValidationRule {
-	msg := ""
-	if len(errMsg) > 0 {
-		msg = errMsg[0]
-	}
-	return &PatternRule{
-		regex:  regexp2.MustCompile(pattern, 0),
-		errMsg: msg,
+// E164 国际电话号码规则构造函数，格式为 + 加国家代码和号码，最多 15 位数字，不允许分隔符，如 +8613800138000
+func E164() ValidationRule {
+	return &E164Rule{
+		regex: regexp2.MustCompile(`^\+[1-9][0-9]{1,14}$`, 0),
 	}
 }
 
-// InRule 枚举规则
-type InRule struct {
-	options []string
+// URLRule URL 规则
+type URLRule struct {
+	regex *regexp2.Regexp
 }
 
-func (r *InRule) Validate(value any) error {
+func (r *URLRule) Validate(value any) error {
 	str, ok := value.(string)
 	if !ok || str == "" {
 		return nil
 	}
 
-	for _, option := range r.options {
-		if str == option {
-			return nil
-		}
+	matched, _ := r.regex.MatchString(str)
+	if !matched {
+		return fmt.Errorf("格式不正确")
 	}
 
-	return fmt.Errorf("的值不在允许的范围内")
+	return nil
 }
 
-// In 枚举规则构造函数
-func In(options ...string) ValidationRule {
-	return &InRule{options: options}
+// URL URL 规则构造函数
+func URL() ValidationRule {
+	return &URLRule{
+		regex: regexp2.MustCompile(`^https?://[^\s]+$`, 0),
+	}
 }
 
-// RangeRule 数值范围规则
-type RangeRule struct {
-	min int
-	max int
+// UUIDRule UUID 规则
+type UUIDRule struct {
+	versions []int // 允许的版本，为空表示不限制
 }
 
-func (r *RangeRule) Validate(value any) error {
-	var num int
-
-	switch v := value.(type) {
-	case int:
-		num = v
-	case int8:
-		num = int(v)
-	case int16:
-		num = int(v)
-	case int32:
-		num = int(v)
-	case int64:
-		num = int(v)
-	default:
+func (r *UUIDRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
 		return nil
 	}
 
-	if num < r.min || num > r.max {
-		return fmt.Errorf("必须在%d到%d之间", r.min, r.max)
+	if !isUUID(str) {
+		return fmt.Errorf("不是有效的UUID")
+	}
+	if len(r.versions) > 0 {
+		// 指定版本时还要求是 RFC 9562 变体（第 20 位为 8、9、a、b）
+		version, _ := strconv.ParseInt(str[14:15], 16, 0)
+		if !slices.Contains(r.versions, int(version)) || !strings.ContainsRune("89abAB", rune(str[19])) {
+			return fmt.Errorf("不是有效的UUID v%s", joinInts(r.versions, "/v"))
+		}
 	}
 
 	return nil
 }
 
-// Range 数值范围规则构造函数
-func Range(min, max int) ValidationRule {
-	return &RangeRule{min: min, max: max}
+// isUUID 判断是否为 8-4-4-4-12 格式的 UUID（不区分大小写）
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
 }
 
-// EqualsRule 相等规则
-type EqualsRule struct {
-	compareValue any
+// joinInts 用 sep 连接整数
+func joinInts(nums []int, sep string) string {
+	strs := make([]string, len(nums))
+	for i, n := range nums {
+		strs[i] = strconv.Itoa(n)
+	}
+	return strings.Join(strs, sep)
 }
 
-func (r *EqualsRule) Validate(value any) error {
-	if value != r.compareValue {
-		return fmt.Errorf("不一致")
+// UUID UUID 规则构造函数，校验 8-4-4-4-12 格式（不区分大小写）
+// 传入 versions 时只允许指定的版本，如 UUID(4) 只允许随机生成的 v4，UUID(4, 7) 允许 v4 和 v7
+func UUID(versions ...int) ValidationRule {
+	return &UUIDRule{versions: versions}
+}
+
+// IPRule IP 地址规则
+type IPRule struct {
+	version int // 4 或 6，0 表示不限制
+}
+
+func (r *IPRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	addr, err := netip.ParseAddr(str)
+	// 带区域（如 fe80::1%eth0）的地址只在本机有意义，不能用于白名单
+	if err != nil || addr.Zone() != "" {
+		return fmt.Errorf("不是有效的%s地址", ipName(r.version))
+	}
+	if (r.version == 4 && !addr.Is4()) || (r.version == 6 && !addr.Is6()) {
+		return fmt.Errorf("不是有效的%s地址", ipName(r.version))
 	}
+
 	return nil
 }
 
-// Equals 相等规则构造函数
-func Equals(compareValue any) ValidationRule {
-	return &EqualsRule{compareValue: compareValue}
+// ipName 返回错误信息中的地址类型名称
+func ipName(version int) string {
+	switch version {
+	case 4:
+		return "IPv4"
+	case 6:
+		return "IPv6"
+	}
+	return "IP"
 }
 
-// CustomRule 自定义规则
-type CustomRule struct {
-	validateFunc func(value any) error
+// IP IP 地址规则构造函数，允许 IPv4 和 IPv6
+func IP() ValidationRule {
+	return &IPRule{}
 }
 
-func (r *CustomRule) Validate(value any) error {
-	return r.validateFunc(value)
+// IPv4 IPv4 地址规则构造函数，如 192.168.1.1
+func IPv4() ValidationRule {
+	return &IPRule{version: 4}
 }
 
-// Custom 自定义规则构造函数
-func Custom(validateFunc func(value any) error) ValidationRule {
-	return &CustomRule{validateFunc: validateFunc}
+// IPv6 IPv6 地址规则构造函数，如 2001:db8::1（IPv4 映射地址 ::ffff:192.168.1.1 也属于 IPv6）
+func IPv6() ValidationRule {
+	return &IPRule{version: 6}
 }
 
-// CustomCondition 自定义条件规则构造函数
-func CustomCondition(condition bool, errMsg string) ValidationRule {
-	return &CustomRule{
-		validateFunc: func(value any) error {
-			if !condition {
-				return fmt.Errorf("%s", errMsg)
-			}
-			return nil
-		},
+// CIDRRule 网段规则
+type CIDRRule struct{}
+
+func (r *CIDRRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
 	}
-}
 
-// ============ 组合规则（组合模式） ============
+	prefix, err := netip.ParsePrefix(str)
+	if err != nil {
+		return fmt.Errorf("不是有效的网段")
+	}
+	// 主机位不为 0 时多半是输入错误，提示规范的写法
+	if masked := prefix.Masked(); masked != prefix {
+		return fmt.Errorf("不是有效的网段，应为%s", masked)
+	}
 
-// CompositeRule 组合规则
-type CompositeRule struct {
-	rules []ValidationRule
+	return nil
 }
 
-func (r *CompositeRule) Validate(value any) error {
-	for _, rule := range r.rules {
-		if err := rule.Validate(value); err != nil {
-			return err
+// CIDR 网段规则构造函数，如 10.0.0.0/8、2001:db8::/32，主机位必须为 0
+func CIDR() ValidationRule {
+	return &CIDRRule{}
+}
+
+// PortRule 端口号规则
+type PortRule struct{}
+
+func (r *PortRule) Validate(value any) error {
+	var port uint64
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return fmt.Errorf("必须是1到65535之间的端口号")
+		}
+		port = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		port = v.Uint()
+	case reflect.String:
+		if v.String() == "" {
+			return nil
 		}
+		n, err := strconv.ParseUint(v.String(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("必须是1到65535之间的端口号")
+		}
+		port = n
+	default:
+		return nil
+	}
+
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("必须是1到65535之间的端口号")
 	}
+
 	return nil
 }
 
-// And 组合多个规则（所有规则都必须通过）
-func And(rules ...ValidationRule) ValidationRule {
-	return &CompositeRule{rules: rules}
+// Port 端口号规则构造函数，字段可以是整数或数字字符串（如 "8080"），范围为 1-65535
+func Port() ValidationRule {
+	return &PortRule{}
 }
 
-// ============ 密码校验辅助函数 ============
+// cardSeparators 卡号中允许的分隔符
+var cardSeparators = strings.NewReplacer(" ", "", "-", "")
 
-// IsPassword 检查是否为有效密码（包含字母和数字）
-func IsPassword(password string) bool {
+// BankCardRule 银行卡号规则
+type BankCardRule struct {
+	lengths []int
+	bins    []string
+}
+
+// BankCardOption 银行卡号规则选项
+type BankCardOption func(*BankCardRule)
+
+// BankCardLength 限定卡号位数，如 BankCardLength(16, 19)，不设置时接受 12-19 位
+func BankCardLength(lengths ...int) BankCardOption {
+	return func(r *BankCardRule) {
+		r.lengths = lengths
+	}
+}
+
+// BankCardBIN 限定卡号开头（发卡行识别码），如 BankCardBIN("62") 只接受银联卡
+func BankCardBIN(prefixes ...string) BankCardOption {
+	return func(r *BankCardRule) {
+		r.bins = prefixes
+	}
+}
+
+func (r *BankCardRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	number := cardSeparators.Replace(str)
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("格式不正确")
+		}
+	}
+
+	if len(r.lengths) > 0 {
+		if !slices.Contains(r.lengths, len(number)) {
+			return fmt.Errorf("必须是%s位", joinInts(r.lengths, "、"))
+		}
+	} else if len(number) < 12 || len(number) > 19 {
+		return fmt.Errorf("必须是12到19位")
+	}
+
+	if len(r.bins) > 0 && !slices.ContainsFunc(r.bins, func(bin string) bool {
+		return strings.HasPrefix(number, bin)
+	}) {
+		return fmt.Errorf("不是支持的卡种")
+	}
+
+	if !luhnValid(number) {
+		return fmt.Errorf("格式不正确")
+	}
+
+	return nil
+}
+
+// luhnValid 校验 Luhn 校验位，number 只包含数字
+func luhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// BankCard 银行卡号规则构造函数，按 Luhn 算法校验最后一位校验位，允许空格和连字符作为分隔符
+//
+// 示例:
+//
+//	vb.Field("银行卡号", req.CardNo).
+//	   AddRule(gint.BankCard(gint.BankCardLength(16, 19), gint.BankCardBIN("62")))
+func BankCard(opts ...BankCardOption) ValidationRule {
+	r := &BankCardRule{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// 信用卡品牌
+const (
+	CardVisa       = "visa"
+	CardMastercard = "mastercard"
+	CardUnionPay   = "unionpay"
+	CardAmex       = "amex"
+	CardJCB        = "jcb"
+)
+
+// cardBrand 信用卡品牌的号段和位数
+type cardBrand struct {
+	name    string
+	label   string   // 错误提示中的名称
+	ranges  [][2]int // 号段，按前缀长度比较，如 {2221, 2720}
+	lengths []int    // 允许的位数
+	luhn    bool     // 是否使用 Luhn 校验位
+}
+
+// cardBrands 支持的品牌，按顺序匹配
+var cardBrands = []cardBrand{
+	{name: CardVisa, label: "Visa", ranges: [][2]int{{4, 4}}, lengths: []int{13, 16, 19}, luhn: true},
+	{name: CardMastercard, label: "Mastercard", ranges: [][2]int{{51, 55}, {2221, 2720}}, lengths: []int{16}, luhn: true},
+	{name: CardAmex, label: "American Express", ranges: [][2]int{{34, 34}, {37, 37}}, lengths: []int{15}, luhn: true},
+	{name: CardJCB, label: "JCB", ranges: [][2]int{{3528, 3589}}, lengths: []int{16, 17, 18, 19}, luhn: true},
+	// 部分银联卡不满足 Luhn 校验，只校验号段和位数
+	{name: CardUnionPay, label: "银联", ranges: [][2]int{{62, 62}}, lengths: []int{16, 17, 18, 19}},
+}
+
+// matchCardBrand 按号段查找品牌，number 只包含数字
+func matchCardBrand(number string) (cardBrand, bool) {
+	for _, b := range cardBrands {
+		for _, rg := range b.ranges {
+			width := len(strconv.Itoa(rg[0]))
+			if len(number) < width {
+				continue
+			}
+			prefix, _ := strconv.Atoi(number[:width])
+			if prefix >= rg[0] && prefix <= rg[1] {
+				return b, true
+			}
+		}
+	}
+	return cardBrand{}, false
+}
+
+// DetectCardBrand 按号段识别信用卡品牌，如 CardVisa，允许空格和连字符
+// 只根据号段判断，不校验位数和校验位；无法识别时返回空字符串
+func DetectCardBrand(number string) string {
+	number = cardSeparators.Replace(number)
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	b, _ := matchCardBrand(number)
+	return b.name
+}
+
+// CardBrandError 卡号格式正确但品牌不在允许范围内
+type CardBrandError struct {
+	Brand string // 识别出的品牌，如 CardAmex
+	label string
+}
+
+func (e *CardBrandError) Error() string {
+	return fmt.Sprintf("不支持%s卡", e.label)
+}
+
+// CreditCardRule 信用卡号规则
+type CreditCardRule struct {
+	brands []string
+	into   *string
+}
+
+func (r *CreditCardRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	number := cardSeparators.Replace(str)
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("格式不正确")
+		}
+	}
+
+	b, ok := matchCardBrand(number)
+	if !ok || !slices.Contains(b.lengths, len(number)) || (b.luhn && !luhnValid(number)) {
+		return fmt.Errorf("格式不正确")
+	}
+	if len(r.brands) > 0 && !slices.Contains(r.brands, b.name) {
+		return &CardBrandError{Brand: b.name, label: b.label}
+	}
+
+	if r.into != nil {
+		*r.into = b.name
+	}
+	return nil
+}
+
+// newCreditCardRule 创建信用卡号规则，brands 包含未知品牌时 panic
+func newCreditCardRule(into *string, brands []string) *CreditCardRule {
+	for _, name := range brands {
+		if !slices.ContainsFunc(cardBrands, func(b cardBrand) bool { return b.name == name }) {
+			panic(fmt.Sprintf("gint: CreditCard 不支持品牌 %s", name))
+		}
+	}
+	return &CreditCardRule{brands: brands, into: into}
+}
+
+// CreditCard 信用卡号规则构造函数，按品牌校验号段、位数和 Luhn 校验位，允许空格和连字符作为分隔符
+// 传入 brands 时只接受指定的品牌，如 CreditCard(gint.CardVisa, gint.CardMastercard)；
+// 品牌不在范围内时返回 *CardBrandError，错误提示如 "卡号不支持American Express卡"
+// 支持 Visa、Mastercard、银联、American Express 和 JCB，传入其他品牌时 panic
+func CreditCard(brands ...string) ValidationRule {
+	return newCreditCardRule(nil, brands)
+}
+
+// CreditCardInto 与 CreditCard 相同，校验通过时将识别出的品牌写入 brand
+//
+// 示例:
+//
+//	var brand string
+//	vb.Field("卡号", req.CardNo).AddRule(gint.CreditCardInto(&brand, gint.CardVisa, gint.CardUnionPay))
+//	if err := vb.Validate().Err(); err != nil {
+//	   return gint.Result{}, err
+//	}
+//	// brand 为 "visa" 或 "unionpay"
+func CreditCardInto(brand *string, brands ...string) ValidationRule {
+	return newCreditCardRule(brand, brands)
+}
+
+// usccChars 统一社会信用代码使用的字符（不含 I、O、S、V、Z），下标即字符的代码值
+const usccChars = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+
+// usccWeights 统一社会信用代码前 17 位的加权因子
+var usccWeights = [17]int{1, 3, 9, 27, 19, 26, 16, 17, 20, 29, 25, 13, 8, 24, 10, 30, 28}
+
+// USCCRule 统一社会信用代码规则
+type USCCRule struct{}
+
+func (r *USCCRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	if len(str) != 18 {
+		return fmt.Errorf("必须是18位")
+	}
+
+	sum := 0
+	for i := 0; i < 18; i++ {
+		v := strings.IndexByte(usccChars, str[i])
+		if v < 0 {
+			return fmt.Errorf("格式不正确")
+		}
+		if i < 17 {
+			sum += v * usccWeights[i]
+		} else if v != (31-sum%31)%31 {
+			return fmt.Errorf("校验位不正确")
+		}
+	}
+
+	return nil
+}
+
+// USCC 统一社会信用代码规则构造函数（GB 32100-2015）
+// 校验 18 位长度、字符集（数字和除 I、O、S、V、Z 以外的大写字母）和最后一位校验位，不校验登记管理部门和行政区划是否存在
+func USCC() ValidationRule {
+	return &USCCRule{}
+}
+
+// JSONKind JSON 的顶层类型
+type JSONKind int
+
+const (
+	// JSONAny 任意类型
+	JSONAny JSONKind = iota
+	// JSONObject 对象，如 {"a": 1}
+	JSONObject
+	// JSONArray 数组，如 [1, 2]
+	JSONArray
+)
+
+// JSONStringRule JSON 字符串规则
+type JSONStringRule struct {
+	kind JSONKind
+}
+
+func (r *JSONStringRule) Validate(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		return nil
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if !json.Valid(data) {
+		return fmt.Errorf("不是有效的JSON")
+	}
+	first := bytes.TrimLeft(data, " \t\r\n")[0]
+	if r.kind == JSONObject && first != '{' {
+		return fmt.Errorf("必须是JSON对象")
+	}
+	if r.kind == JSONArray && first != '[' {
+		return fmt.Errorf("必须是JSON数组")
+	}
+
+	return nil
+}
+
+// JSONString JSON 字符串规则构造函数，用于以字符串形式接收的 JSON 配置等
+// 字段可以是 string、[]byte 或 json.RawMessage；kind 可以限制顶层类型，如 JSONString(gint.JSONObject)
+func JSONString(kind ...JSONKind) ValidationRule {
+	r := &JSONStringRule{}
+	if len(kind) > 0 {
+		r.kind = kind[0]
+	}
+	return r
+}
+
+// Base64Rule Base64 规则
+type Base64Rule struct {
+	encodings []*base64.Encoding
+}
+
+func (r *Base64Rule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	for _, enc := range r.encodings {
+		if _, err := enc.DecodeString(str); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("不是有效的Base64编码")
+}
+
+// Base64 Base64 规则构造函数，默认为带填充的标准编码（base64.StdEncoding）
+// 可以传入允许的编码，如 Base64(base64.RawURLEncoding)，满足任意一种即通过；应与业务逻辑解码时使用的编码一致
+func Base64(encodings ...*base64.Encoding) ValidationRule {
+	if len(encodings) == 0 {
+		encodings = []*base64.Encoding{base64.StdEncoding}
+	}
+	return &Base64Rule{encodings: encodings}
+}
+
+// HexRule 十六进制规则
+type HexRule struct{}
+
+func (r *HexRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	if _, err := hex.DecodeString(str); err != nil {
+		return fmt.Errorf("不是有效的十六进制编码")
+	}
+
+	return nil
+}
+
+// Hex 十六进制规则构造函数，不区分大小写，长度必须为偶数，不接受 0x 前缀
+func Hex() ValidationRule {
+	return &HexRule{}
+}
+
+// 字符类别
+const (
+	charAlpha        = iota // 字母
+	charAlphaNumeric        // 字母和数字
+	charNumeric             // 数字
+)
+
+// CharClassRule 字符类别规则
+type CharClassRule struct {
+	class   int
+	unicode bool // 是否允许 ASCII 以外的字母和数字，如中文、全角数字
+}
+
+func (r *CharClassRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	for _, c := range str {
+		if !r.allow(c) {
+			return fmt.Errorf("%s", r.message())
+		}
+	}
+
+	return nil
+}
+
+// allow 判断字符是否属于允许的类别
+func (r *CharClassRule) allow(c rune) bool {
+	var letter, digit bool
+	if r.unicode {
+		letter, digit = unicode.IsLetter(c), unicode.IsDigit(c)
+	} else {
+		letter = 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+		digit = '0' <= c && c <= '9'
+	}
+	switch r.class {
+	case charAlpha:
+		return letter
+	case charAlphaNumeric:
+		return letter || digit
+	}
+	return digit
+}
+
+// message 返回错误信息
+func (r *CharClassRule) message() string {
+	switch r.class {
+	case charAlpha:
+		return "只能包含字母"
+	case charAlphaNumeric:
+		return "只能包含字母和数字"
+	}
+	return "只能包含数字"
+}
+
+// Alpha 字母规则构造函数，只允许 ASCII 字母 a-z、A-Z
+func Alpha() ValidationRule {
+	return &CharClassRule{class: charAlpha}
+}
+
+// AlphaNumeric 字母数字规则构造函数，只允许 ASCII 字母和数字 0-9
+func AlphaNumeric() ValidationRule {
+	return &CharClassRule{class: charAlphaNumeric}
+}
+
+// Numeric 数字规则构造函数，只允许数字 0-9（不允许符号和小数点），适合验证码、编号等以字符串接收的数字
+func Numeric() ValidationRule {
+	return &CharClassRule{class: charNumeric}
+}
+
+// AlphaUnicode 字母规则构造函数，允许任意语言的字母，如中文姓名
+func AlphaUnicode() ValidationRule {
+	return &CharClassRule{class: charAlpha, unicode: true}
+}
+
+// AlphaNumericUnicode 字母数字规则构造函数，允许任意语言的字母和数字
+func AlphaNumericUnicode() ValidationRule {
+	return &CharClassRule{class: charAlphaNumeric, unicode: true}
+}
+
+// NumericUnicode 数字规则构造函数，允许任意语言的十进制数字，如全角数字
+func NumericUnicode() ValidationRule {
+	return &CharClassRule{class: charNumeric, unicode: true}
+}
+
+// PatternRule 正则规则
+type PatternRule struct {
+	regex  *regexp2.Regexp
+	errMsg string
+}
+
+func (r *PatternRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	matched, _ := r.regex.MatchString(str)
+	if !matched {
+		if r.errMsg != "" {
+			return fmt.Errorf("%s", r.errMsg)
+		}
+		return fmt.Errorf("格式不正确")
+	}
+
+	return nil
+}
+
+// Pattern 正则规则构造函数
+func Pattern(pattern string, errMsg ...string) ValidationRule {
+	msg := ""
+	if len(errMsg) > 0 {
+		msg = errMsg[0]
+	}
+	return &PatternRule{
+		regex:  regexp2.MustCompile(pattern, 0),
+		errMsg: msg,
+	}
+}
+
+// InRule 枚举规则
+type InRule struct {
+	options []string
+}
+
+func (r *InRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	for _, option := range r.options {
+		if str == option {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("的值不在允许的范围内")
+}
+
+// In 枚举规则构造函数
+func In(options ...string) ValidationRule {
+	return &InRule{options: options}
+}
+
+// RangeRule 数值范围规则
+type RangeRule struct {
+	min int
+	max int
+}
+
+func (r *RangeRule) Validate(value any) error {
+	var inRange bool
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num := v.Int()
+		inRange = num >= int64(r.min) && num <= int64(r.max)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		num := v.Uint()
+		inRange = r.max >= 0 && num <= uint64(r.max) && (r.min <= 0 || num >= uint64(r.min))
+	case reflect.Float32, reflect.Float64:
+		num := v.Float()
+		inRange = num >= float64(r.min) && num <= float64(r.max)
+	default:
+		return nil
+	}
+
+	if !inRange {
+		return fmt.Errorf("必须在%d到%d之间", r.min, r.max)
+	}
+
+	return nil
+}
+
+// Range 数值范围规则构造函数，支持有符号整数、无符号整数和浮点数
+// 边界为小数时（如价格）使用 RangeOf
+func Range(min, max int) ValidationRule {
+	return &RangeRule{min: min, max: max}
+}
+
+// RangeOfRule 泛型范围规则
+type RangeOfRule[T cmp.Ordered] struct {
+	min T
+	max T
+}
+
+func (r *RangeOfRule[T]) Validate(value any) error {
+	value = deref(value)
+	if value == nil {
+		return nil
+	}
+
+	// 数字之间按数值精确比较，字段类型与 T 不同时（如 int64 字段使用 RangeOf[int]）同样校验
+	lo, okLo := compareValues(value, r.min)
+	hi, okHi := compareValues(value, r.max)
+	if !okLo || !okHi {
+		if f := reflect.ValueOf(value); f.CanFloat() && math.IsNaN(f.Float()) {
+			return fmt.Errorf("必须在%v到%v之间", r.min, r.max)
+		}
+		return fmt.Errorf("类型%T不能按%v到%v的范围校验", value, r.min, r.max)
+	}
+
+	if lo < 0 || hi > 0 {
+		return fmt.Errorf("必须在%v到%v之间", r.min, r.max)
+	}
+
+	return nil
+}
+
+// RangeOf 泛型范围规则构造函数
+// 数字之间按数值比较，字段类型不必与 T 相同（如 int64、uint32 字段使用 RangeOf(1, 999)）；NaN 不通过，
+// 无法与 T 比较的类型（如字符串字段使用数字范围）校验失败
+//
+// 示例:
+//
+//	vb.Field("价格", req.Price).AddRule(gint.RangeOf(0.01, 99999.99))
+//	vb.Field("数量", req.Quantity).AddRule(gint.RangeOf[uint32](1, 999))
+func RangeOf[T cmp.Ordered](min, max T) ValidationRule {
+	return &RangeOfRule[T]{min: min, max: max}
+}
+
+// DateRule 日期格式规则
+type DateRule struct {
+	layout string
+}
+
+func (r *DateRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	if _, err := time.Parse(r.layout, str); err != nil {
+		return fmt.Errorf("不是有效的日期，格式应为%s", r.layout)
+	}
+
+	return nil
+}
+
+// Date 日期格式规则构造函数，layout 为 time 包的格式，如 time.DateOnly
+func Date(layout string) ValidationRule {
+	return &DateRule{layout: layout}
+}
+
+// dateLayouts 日期范围规则解析字符串时依次尝试的格式
+var dateLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+// toTime 将字段值转换为时间，支持 time.Time、*time.Time 和 dateLayouts 中格式的字符串
+func toTime(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case *time.Time:
+		if v == nil || v.IsZero() {
+			return time.Time{}, false
+		}
+		return *v, true
+	case string:
+		for _, layout := range dateLayouts {
+			if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// DateBetweenRule 日期范围规则
+type DateBetweenRule struct {
+	min time.Time
+	max time.Time
+}
+
+func (r *DateBetweenRule) Validate(value any) error {
+	t, ok := toTime(value)
+	if !ok {
+		return nil
+	}
+
+	if (!r.min.IsZero() && t.Before(r.min)) || (!r.max.IsZero() && t.After(r.max)) {
+		return fmt.Errorf("必须在%s到%s之间", formatDate(r.min), formatDate(r.max))
+	}
+
+	return nil
+}
+
+// DateBetween 日期范围规则构造函数（包含边界），min 或 max 为零值表示不限制
+// 字段可以是 time.Time、*time.Time，或 RFC3339、"2006-01-02 15:04:05"、"2006-01-02" 格式的字符串（按本地时区解析）；
+// 格式不正确的字符串不校验，需要时配合 Date 使用
+//
+// 示例:
+//
+//	vb.Field("出生日期", req.Birthday).
+//	   AddRule(gint.Date(time.DateOnly)).
+//	   AddRule(gint.DateBetween(time.Date(1900, 1, 1, 0, 0, 0, 0, time.Local), time.Now()))
+func DateBetween(min, max time.Time) ValidationRule {
+	return &DateBetweenRule{min: min, max: max}
+}
+
+// formatDate 格式化错误信息中的日期，零值表示不限制
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "不限"
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.DateTime)
+}
+
+// RelativeTimeRule 相对当前时间的规则
+type RelativeTimeRule struct {
+	after bool // true 表示必须晚于当前时间
+}
+
+func (r *RelativeTimeRule) Validate(value any) error {
+	t, ok := toTime(value)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	if r.after && !t.After(now) {
+		return fmt.Errorf("必须晚于当前时间")
+	}
+	if !r.after && !t.Before(now) {
+		return fmt.Errorf("必须早于当前时间")
+	}
+
+	return nil
+}
+
+// BeforeNow 早于当前时间的规则构造函数，如出生日期
+func BeforeNow() ValidationRule {
+	return &RelativeTimeRule{}
+}
+
+// AfterNow 晚于当前时间的规则构造函数，如预约时间、有效期
+func AfterNow() ValidationRule {
+	return &RelativeTimeRule{after: true}
+}
+
+// fieldRefRule 引用其他字段的规则
+// FieldValidator 校验前调用 resolve，传入查找其他字段值的函数，得到实际执行校验的规则
+// 包装其他规则的组合规则都实现了该接口，把 resolve 传给内部规则
+type fieldRefRule interface {
+	resolve(lookup func(name string) (any, bool)) ValidationRule
+}
+
+// resolveRule 解析单个规则引用的字段，不引用其他字段的规则原样返回
+func resolveRule(rule ValidationRule, lookup func(name string) (any, bool)) ValidationRule {
+	if r, ok := rule.(fieldRefRule); ok {
+		return r.resolve(lookup)
+	}
+	return rule
+}
+
+// resolveRules 解析一组规则引用的字段，返回新的切片，不修改构造时传入的规则
+func resolveRules(rules []ValidationRule, lookup func(name string) (any, bool)) []ValidationRule {
+	resolved := make([]ValidationRule, len(rules))
+	for i, rule := range rules {
+		resolved[i] = resolveRule(rule, lookup)
+	}
+	return resolved
+}
+
+// TimeFieldRule 与其他字段比较时间的规则
+type TimeFieldRule struct {
+	field    string // 比较的字段名
+	after    bool   // true 表示必须晚于比较的字段
+	orEqual  bool   // 是否允许与比较的字段相同（GreaterOrEqualField、LessOrEqualField 比较时间时使用）
+	other    any    // 比较的字段值
+	resolved bool   // 是否找到了比较的字段
+}
+
+func (r *TimeFieldRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	other, ok := lookup(r.field)
+	return &TimeFieldRule{field: r.field, after: r.after, orEqual: r.orEqual, other: other, resolved: ok}
+}
+
+func (r *TimeFieldRule) Validate(value any) error {
+	if !r.resolved {
+		return fmt.Errorf("引用的字段%s不存在", r.field)
+	}
+
+	t, ok := toTime(value)
+	if !ok {
+		return nil
+	}
+	other, ok := toTime(r.other)
+	if !ok {
+		return nil
+	}
+
+	switch c := t.Compare(other); {
+	case r.after && r.orEqual && c < 0:
+		return fmt.Errorf("不能早于%s", r.field)
+	case r.after && !r.orEqual && c <= 0:
+		return fmt.Errorf("必须晚于%s", r.field)
+	case !r.after && r.orEqual && c > 0:
+		return fmt.Errorf("不能晚于%s", r.field)
+	case !r.after && !r.orEqual && c >= 0:
+		return fmt.Errorf("必须早于%s", r.field)
+	}
+
+	return nil
+}
+
+// AfterField 晚于其他字段的规则构造函数，field 为同一个 ValidatorBuilder 中 Field 的字段名
+// 任意一方为空或不是有效的时间时不校验，需要时配合 Required、Date 使用
+//
+// 示例:
+//
+//	vb.Field("开始时间", req.StartTime).AddRule(gint.Required())
+//	vb.Field("结束时间", req.EndTime).AddRule(gint.Required()).AddRule(gint.AfterField("开始时间"))
+func AfterField(field string) ValidationRule {
+	return &TimeFieldRule{field: field, after: true}
+}
+
+// BeforeField 早于其他字段的规则构造函数，用法与 AfterField 相同
+func BeforeField(field string) ValidationRule {
+	return &TimeFieldRule{field: field}
+}
+
+// fieldCompareOp 字段比较方式
+type fieldCompareOp int
+
+const (
+	cmpEqual fieldCompareOp = iota
+	cmpGreater
+	cmpGreaterOrEqual
+	cmpLess
+	cmpLessOrEqual
+)
+
+// FieldCompareRule 与同一构建器中其他字段比较的规则
+type FieldCompareRule struct {
+	field    string         // 比较的字段名
+	op       fieldCompareOp // 比较方式
+	other    any            // 比较的字段值
+	resolved bool           // 是否找到了比较的字段
+}
+
+func (r *FieldCompareRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	other, ok := lookup(r.field)
+	return &FieldCompareRule{field: r.field, op: r.op, other: other, resolved: ok}
+}
+
+func (r *FieldCompareRule) Validate(value any) error {
+	if !r.resolved {
+		return fmt.Errorf("引用的字段%s不存在", r.field)
+	}
+
+	// 时间的先后比较与 AfterField、BeforeField 相同
+	if r.op != cmpEqual && isTime(value) {
+		return (&TimeFieldRule{
+			field:    r.field,
+			after:    r.op == cmpGreater || r.op == cmpGreaterOrEqual,
+			orEqual:  r.op == cmpGreaterOrEqual || r.op == cmpLessOrEqual,
+			other:    deref(r.other),
+			resolved: true,
+		}).Validate(deref(value))
+	}
+
+	c, ok := compareValues(value, r.other)
+	if r.op == cmpEqual {
+		if ok && c == 0 || !ok && reflect.DeepEqual(deref(value), deref(r.other)) {
+			return nil
+		}
+		return fmt.Errorf("必须与%s一致", r.field)
+	}
+	// 任意一方为空或无法比较时不校验
+	if !ok || isBlank(deref(value)) || isBlank(deref(r.other)) {
+		return nil
+	}
+
+	switch {
+	case r.op == cmpGreater && c <= 0:
+		return fmt.Errorf("必须大于%s", r.field)
+	case r.op == cmpGreaterOrEqual && c < 0:
+		return fmt.Errorf("不能小于%s", r.field)
+	case r.op == cmpLess && c >= 0:
+		return fmt.Errorf("必须小于%s", r.field)
+	case r.op == cmpLessOrEqual && c > 0:
+		return fmt.Errorf("不能大于%s", r.field)
+	}
+
+	return nil
+}
+
+// deref 解引用指针，nil 指针返回 nil
+func deref(value any) any {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// isTime 判断值是否为 time.Time 或指向 time.Time 的指针
+func isTime(value any) bool {
+	_, ok := deref(value).(time.Time)
+	return ok
+}
+
+// compareValues 比较两个值的大小，支持数字（不同类型之间也可以比较）、字符串和 time.Time
+// 无法比较时 ok 为 false
+func compareValues(a, b any) (c int, ok bool) {
+	a, b = deref(a), deref(b)
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb), true
+		}
+		return 0, false
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		return 0, false
+	}
+	if va.Kind() == reflect.String && vb.Kind() == reflect.String {
+		return cmp.Compare(va.String(), vb.String()), true
+	}
+
+	signed := func(k reflect.Kind) bool { return k >= reflect.Int && k <= reflect.Int64 }
+	unsigned := func(k reflect.Kind) bool { return k >= reflect.Uint && k <= reflect.Uintptr }
+	// 整数与浮点数之间转为 big.Float 精确比较，NaN 无法比较
+	number := func(v reflect.Value) (*big.Float, bool) {
+		switch k := v.Kind(); {
+		case signed(k):
+			return new(big.Float).SetInt64(v.Int()), true
+		case unsigned(k):
+			return new(big.Float).SetUint64(v.Uint()), true
+		case (k == reflect.Float32 || k == reflect.Float64) && !math.IsNaN(v.Float()):
+			return new(big.Float).SetFloat64(v.Float()), true
+		}
+		return nil, false
+	}
+
+	// 整数之间直接比较，避免大整数转为浮点数后丢失精度
+	switch ka, kb := va.Kind(), vb.Kind(); {
+	case signed(ka) && signed(kb):
+		return cmp.Compare(va.Int(), vb.Int()), true
+	case unsigned(ka) && unsigned(kb):
+		return cmp.Compare(va.Uint(), vb.Uint()), true
+	case signed(ka) && unsigned(kb):
+		return compareIntUint(va.Int(), vb.Uint()), true
+	case unsigned(ka) && signed(kb):
+		return -compareIntUint(vb.Int(), va.Uint()), true
+	}
+	na, okA := number(va)
+	nb, okB := number(vb)
+	if !okA || !okB {
+		return 0, false
+	}
+	return na.Cmp(nb), true
+}
+
+// compareIntUint 比较有符号整数与无符号整数
+func compareIntUint(a int64, b uint64) int {
+	if a < 0 {
+		return -1
+	}
+	return cmp.Compare(uint64(a), b)
+}
+
+// EqualsField 与其他字段相等的规则构造函数，field 为同一个 ValidatorBuilder 中 Field 的字段名
+// 数字之间按数值比较（如 int 与 int64），其他类型按 reflect.DeepEqual 比较
+//
+// 示例:
+//
+//	vb.Field("密码", req.Password).AddRule(gint.Password())
+//	vb.Field("确认密码", req.ConfirmPassword).AddRule(gint.EqualsField("密码"))
+func EqualsField(field string) ValidationRule {
+	return &FieldCompareRule{field: field, op: cmpEqual}
+}
+
+// GreaterThanField 大于其他字段的规则构造函数，支持数字、字符串和 time.Time
+// 任意一方为空或类型无法比较时不校验，需要时配合 Required 使用
+//
+// 示例:
+//
+//	vb.Field("最低价", req.MinPrice)
+//	vb.Field("最高价", req.MaxPrice).AddRule(gint.GreaterThanField("最低价"))
+func GreaterThanField(field string) ValidationRule {
+	return &FieldCompareRule{field: field, op: cmpGreater}
+}
+
+// GreaterOrEqualField 大于或等于其他字段的规则构造函数，用法与 GreaterThanField 相同
+func GreaterOrEqualField(field string) ValidationRule {
+	return &FieldCompareRule{field: field, op: cmpGreaterOrEqual}
+}
+
+// LessThanField 小于其他字段的规则构造函数，用法与 GreaterThanField 相同
+func LessThanField(field string) ValidationRule {
+	return &FieldCompareRule{field: field, op: cmpLess}
+}
+
+// LessOrEqualField 小于或等于其他字段的规则构造函数，用法与 GreaterThanField 相同
+func LessOrEqualField(field string) ValidationRule {
+	return &FieldCompareRule{field: field, op: cmpLessOrEqual}
+}
+
+// EqualsRule 相等规则
+type EqualsRule struct {
+	compareValue any
+}
+
+func (r *EqualsRule) Validate(value any) error {
+	if value != r.compareValue {
+		return fmt.Errorf("不一致")
+	}
+	return nil
+}
+
+// Equals 相等规则构造函数
+func Equals(compareValue any) ValidationRule {
+	return &EqualsRule{compareValue: compareValue}
+}
+
+// CustomRule 自定义规则
+type CustomRule struct {
+	validateFunc func(value any) error
+}
+
+func (r *CustomRule) Validate(value any) error {
+	return r.validateFunc(value)
+}
+
+// Custom 自定义规则构造函数
+func Custom(validateFunc func(value any) error) ValidationRule {
+	return &CustomRule{validateFunc: validateFunc}
+}
+
+// CustomCondition 自定义条件规则构造函数
+func CustomCondition(condition bool, errMsg string) ValidationRule {
+	return &CustomRule{
+		validateFunc: func(value any) error {
+			if !condition {
+				return fmt.Errorf("%s", errMsg)
+			}
+			return nil
+		},
+	}
+}
+
+// ============ 组合规则（组合模式） ============
+
+// CompositeRule 组合规则
+type CompositeRule struct {
+	rules []ValidationRule
+}
+
+func (r *CompositeRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	return &CompositeRule{rules: resolveRules(r.rules, lookup)}
+}
+
+func (r *CompositeRule) Validate(value any) error {
+	for _, rule := range r.rules {
+		if err := rule.Validate(value); err != nil {
+			return &subRuleError{rule: rule, err: err}
+		}
+	}
+	return nil
+}
+
+// And 组合多个规则（所有规则都必须通过）
+func And(rules ...ValidationRule) ValidationRule {
+	return &CompositeRule{rules: rules}
+}
+
+// WhenRule 条件规则
+type WhenRule struct {
+	cond  func(value any) bool
+	rules []ValidationRule
+}
+
+func (r *WhenRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	return &WhenRule{cond: r.cond, rules: resolveRules(r.rules, lookup)}
+}
+
+func (r *WhenRule) Validate(value any) error {
+	if !r.cond(value) {
+		return nil
+	}
+	for _, rule := range r.rules {
+		if err := rule.Validate(value); err != nil {
+			return &subRuleError{rule: rule, err: err}
+		}
+	}
+	return nil
+}
+
+// When 条件规则，cond 返回 true 时依次执行 rules，否则跳过
+// 条件需要引用其他字段时，使用 ValidatorBuilder.When
+//
+// 示例:
+//
+//	// 以 http 开头时才校验完整的网址格式
+//	vb.Field("回调地址", req.Callback).AddRule(gint.When(func(v any) bool {
+//	   return strings.HasPrefix(v.(string), "http")
+//	}, gint.URL()))
+func When(cond func(value any) bool, rules ...ValidationRule) ValidationRule {
+	return &WhenRule{cond: cond, rules: rules}
+}
+
+// EachRule 切片元素规则
+type EachRule struct {
+	rules []ValidationRule
+}
+
+// ElementError 切片或 map 中单个元素的校验错误
+type ElementError struct {
+	Index int            // 切片元素的下标（从 0 开始），map 元素为 -1
+	Key   string         // map 元素的键
+	Rule  ValidationRule // 未通过的规则
+	Err   error          // 规则返回的错误
+	isKey bool           // 错误来自 map 的键（MapKeys）
+}
+
+// Error 返回如“第2项长度不能少于2个字符”、“的键"x"长度不能少于2个字符”的错误信息（序号从 1 开始）
+func (e ElementError) Error() string {
+	switch {
+	case e.Index >= 0:
+		return fmt.Sprintf("第%d项%s", e.Index+1, e.Err.Error())
+	case e.isKey:
+		return fmt.Sprintf("的键%q%s", e.Key, e.Err.Error())
+	default:
+		return fmt.Sprintf("中%q的值%s", e.Key, e.Err.Error())
+	}
+}
+
+// path 元素在字段中的位置，切片为下标，map 为键
+func (e ElementError) path() string {
+	if e.Index >= 0 {
+		return strconv.Itoa(e.Index)
+	}
+	return e.Key
+}
+
+// ElementErrors Each 规则返回的错误，包含所有未通过校验的元素
+type ElementErrors []ElementError
+
+// Error 用分号连接所有元素的错误信息
+func (e ElementErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ee := range e {
+		msgs[i] = ee.Error()
+	}
+	return strings.Join(msgs, "；")
+}
+
+func (r *EachRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	return &EachRule{rules: resolveRules(r.rules, lookup)}
+}
+
+func (r *EachRule) Validate(value any) error {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil
+	}
+
+	var errs ElementErrors
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i).Interface()
+		for _, rule := range r.rules {
+			if err := rule.Validate(elem); err != nil {
+				errs = append(errs, ElementError{Index: i, Rule: rule, Err: err})
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Each 对切片的每个元素依次执行 rules，每个元素只报告第一个未通过的规则
+// 在 FieldValidator 中使用时，每个未通过的元素生成一条字段错误，字段名带上下标（如 标签[1]）
+//
+// 示例:
+//
+//	vb.Field("标签", req.Tags).AddRule(gint.Each(gint.Required(), gint.MaxLength(10)))
+//	// 标签第2项长度不能超过10个字符
+func Each(rules ...ValidationRule) ValidationRule {
+	return &EachRule{rules: rules}
+}
+
+// MapRule map 键或值规则
+type MapRule struct {
+	rules []ValidationRule
+	keys  bool // 校验键（MapKeys）还是值（MapValues）
+}
+
+func (r *MapRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	return &MapRule{rules: resolveRules(r.rules, lookup), keys: r.keys}
+}
+
+func (r *MapRule) Validate(value any) error {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map {
+		return nil
+	}
+
+	// 按键排序，保证错误信息的顺序稳定
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+
+	var errs ElementErrors
+	for _, key := range keys {
+		target := v.MapIndex(key)
+		if r.keys {
+			target = key
+		}
+		for _, rule := range r.rules {
+			if err := rule.Validate(target.Interface()); err != nil {
+				errs = append(errs, ElementError{
+					Index: -1,
+					Key:   fmt.Sprint(key.Interface()),
+					Rule:  rule,
+					Err:   err,
+					isKey: r.keys,
+				})
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// MapKeys 对 map 的每个键依次执行 rules，每个键只报告第一个未通过的规则
+//
+// 示例:
+//
+//	vb.Field("标签", req.Labels).
+//	   AddRule(gint.MapKeys(gint.Pattern(`^[a-z_]+$`), gint.MaxLength(20))).
+//	   AddRule(gint.MapValues(gint.MaxLength(50)))
+//	// 标签的键"Color"格式不正确
+func MapKeys(rules ...ValidationRule) ValidationRule {
+	return &MapRule{rules: rules, keys: true}
+}
+
+// MapValues 对 map 的每个值依次执行 rules，每个值只报告第一个未通过的规则
+// 错误信息如：标签中"color"的值长度不能超过50个字符
+func MapValues(rules ...ValidationRule) ValidationRule {
+	return &MapRule{rules: rules}
+}
+
+// NotRule 取反规则
+type NotRule struct {
+	rule ValidationRule
+	msg  string
+}
+
+func (r *NotRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	return &NotRule{rule: resolveRule(r.rule, lookup), msg: r.msg}
+}
+
+func (r *NotRule) Validate(value any) error {
+	// 与其他规则一致，空值不校验，是否必填由 Required 决定
+	if isBlank(value) {
+		return nil
+	}
+	if r.rule.Validate(value) == nil {
+		return fmt.Errorf("%s", r.msg)
+	}
+	return nil
+}
+
+// Not 对规则取反，被包装的规则通过时校验失败，错误提示为 msg
+//
+// 示例:
+//
+//	vb.Field("用户名", req.Username).
+//	   AddRule(gint.Not(gint.In("admin", "root", "system"), "不能使用系统保留名称"))
+func Not(rule ValidationRule, msg string) ValidationRule {
+	return &NotRule{rule: rule, msg: msg}
+}
+
+// isBlank 判断是否为空值（nil 或空白字符串）
+func isBlank(value any) bool {
+	if value == nil {
+		return true
+	}
+	str, ok := value.(string)
+	return ok && strings.TrimSpace(str) == ""
+}
+
+// ============ 自定义错误提示（装饰器模式） ============
+
+// MessageRule 覆盖错误提示的规则
+type MessageRule struct {
+	rule ValidationRule
+	msg  string
+}
+
+func (r *MessageRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	return &MessageRule{rule: resolveRule(r.rule, lookup), msg: r.msg}
+}
+
+func (r *MessageRule) Validate(value any) error {
+	if err := r.rule.Validate(value); err != nil {
+		return &messageError{msg: r.msg, err: err}
+	}
+	return nil
+}
+
+// messageError WithMessage 覆盖后的错误，保留原来的错误用于确定未通过的子规则
+type messageError struct {
+	msg string
+	err error
+}
+
+func (e *messageError) Error() string { return e.msg }
+
+func (e *messageError) Unwrap() error { return e.err }
+
+// WithMessage 覆盖规则的默认错误提示，与默认提示一样会在前面拼接字段名
+//
+// 示例:
+//
+//	vb.Field("昵称", req.Nickname).
+//	   AddRule(gint.WithMessage(gint.MinLength(2), "太短了，至少输入2个字"))
+func WithMessage(rule ValidationRule, msg string) ValidationRule {
+	return &MessageRule{rule: rule, msg: msg}
+}
+
+// ============ 错误码（装饰器模式） ============
+
+// CodeRule 指定错误码的规则
+type CodeRule struct {
+	rule ValidationRule
+	code string
+}
+
+func (r *CodeRule) resolve(lookup func(name string) (any, bool)) ValidationRule {
+	return &CodeRule{rule: resolveRule(r.rule, lookup), code: r.code}
+}
+
+func (r *CodeRule) Validate(value any) error {
+	return r.rule.Validate(value)
+}
+
+// WithCode 指定规则未通过时的错误码，客户端可以按错误码处理而不需要匹配错误信息
+// 未指定时错误码为 VAL_ 加上大写的规则名，如 MinLength 为 VAL_MIN_LENGTH、Required 为 VAL_REQUIRED
+//
+// 示例:
+//
+//	vb.Field("用户名", req.Username).
+//	   AddRule(gint.WithCode(usernameAvailable(ctx), "USERNAME_TAKEN"))
+func WithCode(rule ValidationRule, code string) ValidationRule {
+	return &CodeRule{rule: rule, code: code}
+}
+
+// ruleCode 返回规则的错误码，未通过 WithCode 指定时根据规则名生成
+func ruleCode(rule ValidationRule) string {
+	if code := explicitCode(rule); code != "" {
+		return code
+	}
+	return "VAL_" + strings.ToUpper(ruleName(rule))
+}
+
+// subRuleError And、When 等组合规则中未通过的子规则及其错误
+type subRuleError struct {
+	rule ValidationRule
+	err  error
+}
+
+func (e *subRuleError) Error() string { return e.err.Error() }
+
+func (e *subRuleError) Unwrap() error { return e.err }
+
+// failedRule 返回实际未通过的规则，用于生成错误码和规则名
+// 通过 WithCode 指定了错误码的规则以自身为准，组合规则取未通过的子规则，如 IDCard 为空时为 Required
+func failedRule(rule ValidationRule, err error) ValidationRule {
+	if explicitCode(rule) != "" {
+		return rule
+	}
+	var sub *subRuleError
+	if errors.As(err, &sub) {
+		return failedRule(sub.rule, sub.err)
+	}
+	return rule
+}
+
+// explicitCode 返回通过 WithCode 指定的错误码，没有时返回空字符串
+func explicitCode(rule ValidationRule) string {
+	switch r := rule.(type) {
+	case *CodeRule:
+		return r.code
+	case *MessageRule:
+		return explicitCode(r.rule)
+	case *RegisteredRule:
+		return explicitCode(r.rule)
+	}
+	return ""
+}
+
+// ============ 密码校验辅助函数 ============
+
+// IsPassword 检查是否为有效密码（包含字母和数字）
+func IsPassword(password string) bool {
 	hasLetter := false
 	hasDigit := false
 
@@ -500,7 +2388,7 @@ func Username() ValidationRule {
 	return And(
 		Required(),
 		LengthRange(4, 20),
-		Pattern(`^[a-zA-Z0-9_]+$`, "只能包含字母、数字和下划线"),
+		WithCode(Pattern(`^[a-zA-Z0-9_]+$`, "只能包含字母、数字和下划线"), "VAL_USERNAME"),
 	)
 }
 
@@ -509,7 +2397,7 @@ func Password() ValidationRule {
 	return And(
 		Required(),
 		LengthRange(6, 20),
-		Custom(func(value any) error {
+		WithCode(Custom(func(value any) error {
 			str, ok := value.(string)
 			if !ok {
 				return nil
@@ -518,7 +2406,7 @@ func Password() ValidationRule {
 				return fmt.Errorf("必须包含字母和数字")
 			}
 			return nil
-		}),
+		}), "VAL_PASSWORD"),
 	)
 }
 
@@ -527,7 +2415,7 @@ func StrongPassword() ValidationRule {
 	return And(
 		Required(),
 		LengthRange(8, 20),
-		Custom(func(value any) error {
+		WithCode(Custom(func(value any) error {
 			str, ok := value.(string)
 			if !ok {
 				return nil
@@ -536,7 +2424,7 @@ func StrongPassword() ValidationRule {
 				return fmt.Errorf("必须包含大小写字母、数字和特殊字符")
 			}
 			return nil
-		}),
+		}), "VAL_STRONG_PASSWORD"),
 	)
 }
 
@@ -544,15 +2432,79 @@ func StrongPassword() ValidationRule {
 func ChineseName() ValidationRule {
 	return And(
 		Required(),
-		Pattern(`^[\p{Han}]{2,4}$`, "必须为2-4个汉字"),
+		WithCode(Pattern(`^[\p{Han}]{2,4}$`, "必须为2-4个汉字"), "VAL_CHINESE_NAME"),
 	)
 }
 
-// IDCard 身份证号规则
-func IDCard() ValidationRule {
+// IDCardMode 身份证号的校验程度
+type IDCardMode int
+
+const (
+	// IDCardChecksum 校验格式和 GB 11643 校验位（默认）
+	IDCardChecksum IDCardMode = iota
+	// IDCardStrict 在 IDCardChecksum 的基础上校验出生日期真实存在且不晚于当天
+	IDCardStrict
+	// IDCardLenient 只校验格式，不校验校验位（旧版行为）
+	IDCardLenient
+)
+
+// idCardWeights 身份证号前 17 位的加权因子
+var idCardWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// idCardCheckDigits 加权和除以 11 的余数对应的校验位
+const idCardCheckDigits = "10X98765432"
+
+// IDCardRule 18 位身份证号规则
+type IDCardRule struct {
+	mode  IDCardMode
+	regex *regexp2.Regexp
+}
+
+func (r *IDCardRule) Validate(value any) error {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	matched, _ := r.regex.MatchString(str)
+	if !matched {
+		return fmt.Errorf("格式不正确")
+	}
+	if r.mode == IDCardLenient {
+		return nil
+	}
+
+	sum := 0
+	for i := 0; i < 17; i++ {
+		sum += int(str[i]-'0') * idCardWeights[i]
+	}
+	if idCardCheckDigits[sum%11] != str[17] && !(str[17] == 'x' && idCardCheckDigits[sum%11] == 'X') {
+		return fmt.Errorf("校验位不正确")
+	}
+
+	if r.mode == IDCardStrict {
+		birth, err := time.ParseInLocation("20060102", str[6:14], time.Local)
+		if err != nil || birth.After(time.Now()) {
+			return fmt.Errorf("出生日期不正确")
+		}
+	}
+
+	return nil
+}
+
+// IDCard 18 位身份证号规则（包含 Required）
+// 默认校验格式和 GB 11643 校验位；IDCard(gint.IDCardStrict) 额外校验出生日期，IDCard(gint.IDCardLenient) 只校验格式
+func IDCard(mode ...IDCardMode) ValidationRule {
+	m := IDCardChecksum
+	if len(mode) > 0 {
+		m = mode[0]
+	}
 	return And(
 		Required(),
-		Pattern(`^[1-9]\d{5}(18|19|20)\d{2}(0[1-9]|1[0-2])(0[1-9]|[12]\d|3[01])\d{3}[\dXx]$`, "格式不正确"),
+		WithCode(&IDCardRule{
+			mode:  m,
+			regex: regexp2.MustCompile(`^[1-9][0-9]{5}(18|19|20)[0-9]{2}(0[1-9]|1[0-2])(0[1-9]|[12][0-9]|3[01])[0-9]{3}[0-9Xx]$`, 0),
+		}, "VAL_ID_CARD"),
 	)
 }
 
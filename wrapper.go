@@ -19,12 +19,16 @@
 package gint
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ink-code/gint/errpage"
 	"github.com/ink-code/gint/gctx"
+	"github.com/ink-code/gint/middlewares/tx"
 	"github.com/ink-code/gint/session"
 )
 
@@ -36,40 +40,15 @@ import (
 //	router.GET("/ping", gint.W(func(ctx *gint.Context) (gint.Result, error) {
 //	   return gint.Result{Code: 0, Msg: "pong"}, nil
 //	}))
-func W(fn func(ctx *gctx.Context) (Result, error)) gin.HandlerFunc {
+func W(fn func(ctx *gctx.Context) (Result, error), opts ...Option) gin.HandlerFunc {
+	o := newOptions(fn, opts)
 	return func(c *gin.Context) {
-		ctx := &gctx.Context{Context: c}
+		ctx := newContext(c, o)
 
-		// 执行业务逻辑
-		res, err := fn(ctx)
-
-		// 处理特殊错误
-		if errors.Is(err, ErrNoResponse) {
-			slog.Debug("不需要响应", slog.Any("err", err))
-			return
-		}
-
-		if errors.Is(err, ErrUnauthorized) {
-			slog.Debug("未授权", slog.Any("err", err))
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-
-		// 处理一般错误
-		if err != nil {
-			slog.Error("执行业务逻辑失败",
-				slog.String("path", c.Request.URL.Path),
-				slog.Any("err", err))
-			c.JSON(http.StatusOK, Result{
-				Code: res.Code,
-				Msg:  err.Error(),
-				Data: nil,
-			})
-			return
-		}
-
-		// 返回成功响应
-		c.JSON(http.StatusOK, res)
+		// 执行业务逻辑并响应
+		handle(c, o, func() (Result, error) {
+			return fn(ctx)
+		})
 	}
 }
 
@@ -84,57 +63,23 @@ func W(fn func(ctx *gctx.Context) (Result, error)) gin.HandlerFunc {
 //	}
 //
 //	router.POST("/login", gint.B(func(ctx *gint.Context, req LoginReq) (gint.Result, error) {
-//	   // req 已经自动绑定
+//	   // req 已经自动绑定，若实现了 Validatable 也已自动校验
 //	   return gint.Result{Code: 0, Data: "登录成功"}, nil
 //	}))
-func B[Req any](fn func(ctx *gctx.Context, req Req) (Result, error)) gin.HandlerFunc {
+func B[Req any](fn func(ctx *gctx.Context, req Req) (Result, error), opts ...Option) gin.HandlerFunc {
+	checkRules[Req]()
+	o := newOptions(fn, opts)
 	return func(c *gin.Context) {
-		ctx := &gctx.Context{Context: c}
-
-		// 绑定请求参数
-		var req Req
-		if err := c.ShouldBind(&req); err != nil {
-			slog.Debug("绑定参数失败",
-				slog.String("path", c.Request.URL.Path),
-				slog.Any("err", err))
-			c.JSON(http.StatusBadRequest, Result{
-				Code: 400,
-				Msg:  "参数错误: " + err.Error(),
-				Data: nil,
-			})
-			return
-		}
-
-		// 执行业务逻辑
-		res, err := fn(ctx, req)
+		ctx := newContext(c, o)
 
-		// 处理特殊错误
-		if errors.Is(err, ErrNoResponse) {
-			slog.Debug("不需要响应", slog.Any("err", err))
-			return
-		}
-
-		if errors.Is(err, ErrUnauthorized) {
-			slog.Debug("未授权", slog.Any("err", err))
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-
-		// 处理一般错误
-		if err != nil {
-			slog.Error("执行业务逻辑失败",
-				slog.String("path", c.Request.URL.Path),
-				slog.Any("err", err))
-			c.JSON(http.StatusOK, Result{
-				Code: res.Code,
-				Msg:  err.Error(),
-				Data: nil,
-			})
-			return
-		}
-
-		// 返回成功响应
-		c.JSON(http.StatusOK, res)
+		// 绑定参数、执行业务逻辑并响应
+		handle(c, o, func() (Result, error) {
+			req, ok := bind[Req](c, o)
+			if !ok {
+				return Result{}, errBindFailed
+			}
+			return fn(ctx, req)
+		})
 	}
 }
 
@@ -147,51 +92,23 @@ func B[Req any](fn func(ctx *gctx.Context, req Req) (Result, error)) gin.Handler
 //	   userId := sess.Claims().UserId
 //	   return gint.Result{Code: 0, Data: userId}, nil
 //	}))
-func S(fn func(ctx *gctx.Context, sess session.Session) (Result, error)) gin.HandlerFunc {
+func S(fn func(ctx *gctx.Context, sess session.Session) (Result, error), opts ...Option) gin.HandlerFunc {
+	o := newOptions(fn, opts)
 	return func(c *gin.Context) {
-		ctx := &gctx.Context{Context: c}
+		start := time.Now()
+		ctx := newContext(c, o)
 
 		// 获取 Session
 		sess, err := session.Get(ctx)
 		if err != nil {
-			slog.Debug("获取 Session 失败",
-				slog.String("path", c.Request.URL.Path),
-				slog.Any("err", err))
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-
-		// 执行业务逻辑
-		res, err := fn(ctx, sess)
-
-		// 处理特殊错误
-		if errors.Is(err, ErrNoResponse) {
-			slog.Debug("不需要响应", slog.Any("err", err))
+			rejectSession(c, o, start, "获取 Session 失败", err)
 			return
 		}
 
-		if errors.Is(err, ErrUnauthorized) {
-			slog.Debug("未授权", slog.Any("err", err))
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-
-		// 处理一般错误
-		if err != nil {
-			slog.Error("执行业务逻辑失败",
-				slog.String("path", c.Request.URL.Path),
-				slog.String("user_id", sess.Claims().UserId),
-				slog.Any("err", err))
-			c.JSON(http.StatusOK, Result{
-				Code: res.Code,
-				Msg:  err.Error(),
-				Data: nil,
-			})
-			return
-		}
-
-		// 返回成功响应
-		c.JSON(http.StatusOK, res)
+		// 执行业务逻辑并响应
+		handle(c, o, func() (Result, error) {
+			return fn(ctx, sess)
+		}, slog.String("user_id", sess.Claims().UserId))
 	}
 }
 
@@ -210,65 +127,314 @@ func S(fn func(ctx *gctx.Context, sess session.Session) (Result, error)) gin.Han
 //	   // 更新用户信息...
 //	   return gint.Result{Code: 0, Msg: "更新成功"}, nil
 //	}))
-func BS[Req any](fn func(ctx *gctx.Context, req Req, sess session.Session) (Result, error)) gin.HandlerFunc {
+func BS[Req any](fn func(ctx *gctx.Context, req Req, sess session.Session) (Result, error), opts ...Option) gin.HandlerFunc {
+	checkRules[Req]()
+	o := newOptions(fn, opts)
 	return func(c *gin.Context) {
-		ctx := &gctx.Context{Context: c}
+		start := time.Now()
+		ctx := newContext(c, o)
 
 		// 获取 Session
 		sess, err := session.Get(ctx)
 		if err != nil {
-			slog.Debug("获取 Session 失败",
-				slog.String("path", c.Request.URL.Path),
-				slog.Any("err", err))
-			c.AbortWithStatus(http.StatusUnauthorized)
+			rejectSession(c, o, start, "获取 Session 失败", err)
 			return
 		}
 
-		// 绑定请求参数
-		var req Req
-		if err := c.ShouldBind(&req); err != nil {
-			slog.Debug("绑定参数失败",
-				slog.String("path", c.Request.URL.Path),
-				slog.String("user_id", sess.Claims().UserId),
-				slog.Any("err", err))
-			c.JSON(http.StatusBadRequest, Result{
-				Code: 400,
-				Msg:  "参数错误: " + err.Error(),
-				Data: nil,
-			})
+		// 绑定参数、执行业务逻辑并响应
+		userAttr := slog.String("user_id", sess.Claims().UserId)
+		handle(c, o, func() (Result, error) {
+			req, ok := bind[Req](c, o, userAttr)
+			if !ok {
+				return Result{}, errBindFailed
+			}
+			return fn(ctx, req, sess)
+		}, userAttr)
+	}
+}
+
+// C (Claims) 只校验 Token 的轻量包装器
+// 仅验证 JWT 并传入 Claims，不访问会话存储，适用于 Redis 查询没有必要的高频只读接口
+// 注意：会话被主动销毁后，Access Token 在过期前仍可通过校验，对实时性要求高的接口请使用 S
+//
+// 示例:
+//
+//	router.GET("/feeds", gint.C(func(ctx *gctx.Context, claims *session.Claims) (gint.Result, error) {
+//	   return gint.Success("", listFeeds(claims.UserId)), nil
+//	}))
+func C(fn func(ctx *gctx.Context, claims *session.Claims) (Result, error), opts ...Option) gin.HandlerFunc {
+	o := newOptions(fn, opts)
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := newContext(c, o)
+
+		// 校验 Token
+		claims, err := session.GetClaims(ctx)
+		if err != nil {
+			rejectSession(c, o, start, "校验 Token 失败", err)
 			return
 		}
 
-		// 执行业务逻辑
-		res, err := fn(ctx, req, sess)
+		// 执行业务逻辑并响应
+		handle(c, o, func() (Result, error) {
+			return fn(ctx, claims)
+		}, slog.String("user_id", claims.UserId))
+	}
+}
 
-		// 处理特殊错误
-		if errors.Is(err, ErrNoResponse) {
-			slog.Debug("不需要响应", slog.Any("err", err))
-			return
+// writeError 输出错误响应
+// 开启了 HTML 错误页面且客户端优先接受 HTML 时渲染错误页面（见 errpage），否则输出 JSON
+func writeError(c *gin.Context, status int, res Result) {
+	if errpage.Render(c, errpage.Page{Status: status, Code: res.Code, Msg: res.Msg, TraceID: res.TraceID}) {
+		return
+	}
+	c.JSON(status, res)
+}
+
+// abortError 输出错误响应并终止请求
+func abortError(c *gin.Context, status int, res Result) {
+	c.Abort()
+	writeError(c, status, res)
+}
+
+// abortUnauthorized 以 401 终止请求
+// JSON 客户端不输出响应体，浏览器在开启了 HTML 错误页面时看到提示页面
+func abortUnauthorized(c *gin.Context) {
+	if errpage.Render(c, errpage.Page{Status: http.StatusUnauthorized, Code: http.StatusUnauthorized, Msg: "未登录或登录已过期", TraceID: traceID(c)}) {
+		c.Abort()
+		return
+	}
+	c.AbortWithStatus(http.StatusUnauthorized)
+}
+
+// recordError 将请求错误写入 gin.Errors，附带业务码和 HTTP 状态码（gctx.ErrorMeta）
+// public 表示错误信息已作为 msg 返回给客户端；访问日志、指标等中间件通过 gctx.HandlerError 读取
+func recordError(c *gin.Context, err error, code, status int, public bool) {
+	typ := gin.ErrorTypePrivate
+	if public {
+		typ = gin.ErrorTypePublic
+	}
+	_ = c.Error(err).SetType(typ).SetMeta(gctx.ErrorMeta{Code: code, Status: status})
+}
+
+// rejectSession 获取会话或校验 Token 失败时输出响应
+// 账号被禁用（session.ErrAccountDisabled）时响应 403，其他情况响应 401
+func rejectSession(c *gin.Context, o *options, start time.Time, msg string, err error) {
+	slog.Debug(msg, withTrace(c, []any{
+		slog.String("path", c.Request.URL.Path),
+		slog.Any("err", err)})...)
+	if errors.Is(err, session.ErrAccountDisabled) {
+		recordError(c, err, http.StatusForbidden, http.StatusForbidden, true)
+		abortError(c, http.StatusForbidden, Result{
+			Code:    http.StatusForbidden,
+			Msg:     err.Error(),
+			TraceID: traceID(c),
+		})
+	} else {
+		recordError(c, err, http.StatusUnauthorized, http.StatusUnauthorized, false)
+		abortUnauthorized(c)
+	}
+	observe(c, o, start, 0, err)
+}
+
+// handle 包装器的公共执行流程：执行业务逻辑并输出响应
+// call 中绑定参数失败时已直接输出 400 响应，返回 errBindFailed
+func handle(c *gin.Context, o *options, call func() (Result, error), attrs ...any) {
+	attrs = withTrace(c, attrs)
+
+	// 记录客户端在处理完成前断开的情况
+	call = watchCancel(c, o, call, attrs)
+
+	// 处理失败时记录脱敏后的请求体
+	if o.errorBody != nil {
+		call = logBodyOnError(c, o.errorBody, call, attrs)
+	}
+
+	// 开启了请求范围的事务时，在输出响应之前根据处理结果提交或回滚
+	if _, ok := c.Get(gctx.CtxTxKey); ok {
+		call = finishTx(c, call)
+	}
+
+	// 配置了指标回调时记录业务码与错误，响应输出后上报
+	// 缓存命中或幂等重放时不执行业务逻辑，通过 replayed 记录返回结果的业务码
+	replayed := func(Result) {}
+	if metricsFunc.Load() != nil {
+		start := time.Now()
+		var code int
+		var callErr error
+		inner := call
+		call = func() (Result, error) {
+			res, err := inner()
+			code, callErr = res.Code, err
+			return res, err
+		}
+		replayed = func(res Result) {
+			code = res.Code
 		}
+		defer func() {
+			observe(c, o, start, code, callErr)
+		}()
+	}
 
-		if errors.Is(err, ErrUnauthorized) {
-			slog.Debug("未授权", slog.Any("err", err))
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
+	if cacheable(c, o) {
+		handleCached(c, o, call, replayed, attrs...)
+		return
+	}
+
+	if o.idempotency != nil {
+		handleIdempotent(c, o, call, replayed, attrs...)
+		return
+	}
+
+	res, err := call()
+	render(c, o, res, err, attrs...)
+}
+
+// finishTx 在业务逻辑执行完成后结束请求范围的事务
+// 成功结果提交；返回错误或非成功业务码时回滚；ErrNoResponse（已自行输出响应）按响应状态码判断
+func finishTx(c *gin.Context, call func() (Result, error)) func() (Result, error) {
+	return func() (Result, error) {
+		res, err := call()
+
+		var commit bool
+		switch {
+		case errors.Is(err, errBindFailed):
+			commit = false
+		case errors.Is(err, ErrNoResponse):
+			commit = c.Writer.Status() < http.StatusBadRequest
+		default:
+			commit = err == nil && res.Code == CodeSuccess
 		}
 
-		// 处理一般错误
-		if err != nil {
-			slog.Error("执行业务逻辑失败",
-				slog.String("path", c.Request.URL.Path),
-				slog.String("user_id", sess.Claims().UserId),
-				slog.Any("err", err))
-			c.JSON(http.StatusOK, Result{
-				Code: res.Code,
-				Msg:  err.Error(),
-				Data: nil,
-			})
+		if txErr := tx.Finish(c, commit); txErr != nil && err == nil {
+			return Result{Code: CodeError}, txErr
+		}
+		return res, err
+	}
+}
+
+// render 统一处理业务逻辑的返回结果并输出响应
+func render(c *gin.Context, o *options, res Result, err error, attrs ...any) {
+	// 处理特殊错误
+	if errors.Is(err, ErrNoResponse) {
+		slog.Debug("不需要响应", append([]any{slog.Any("err", err)}, attrs...)...)
+		return
+	}
+
+	// 客户端已经断开，响应不会送达
+	if errors.Is(err, context.Canceled) && clientGone(c) {
+		slog.Debug("客户端已断开，不输出响应", append([]any{slog.Any("err", err)}, attrs...)...)
+		recordError(c, err, statusClientClosed, statusClientClosed, false)
+		c.Status(statusClientClosed)
+		return
+	}
+
+	if errors.Is(err, ErrUnauthorized) {
+		slog.Debug("未授权", append([]any{slog.Any("err", err)}, attrs...)...)
+		recordError(c, err, http.StatusUnauthorized, http.StatusUnauthorized, false)
+		abortUnauthorized(c)
+		return
+	}
+
+	// 处理结构化的校验错误
+	var ve ValidationErrors
+	if errors.As(err, &ve) {
+		slog.Debug("参数校验失败", append([]any{
+			slog.String("path", c.Request.URL.Path),
+			slog.Any("err", err)}, attrs...)...)
+		recordError(c, err, http.StatusBadRequest, http.StatusBadRequest, true)
+		writeError(c, http.StatusBadRequest, Result{
+			Code:    400,
+			Msg:     "参数错误: " + ve.Error(),
+			Data:    ve.Map(),
+			TraceID: traceID(c),
+			Errors:  ve,
+		})
+		return
+	}
+
+	// 处理一般错误
+	if err != nil {
+		slog.Error("执行业务逻辑失败", append([]any{
+			slog.String("path", c.Request.URL.Path),
+			slog.Any("err", err)}, attrs...)...)
+		status := httpStatusForError(res.Code)
+		recordError(c, err, res.Code, status, true)
+		writeError(c, status, Result{
+			Code:    res.Code,
+			Msg:     err.Error(),
+			Data:    nil,
+			TraceID: traceID(c),
+		})
+		return
+	}
+
+	// 成功响应的状态码
+	status := http.StatusOK
+	if o.successStatus != 0 {
+		status = o.successStatus
+	}
+
+	// 处理特殊的响应类型，nil 指针按没有数据处理
+	switch data := res.Data.(type) {
+	case Raw:
+		renderRaw(c, data, status)
+		return
+	case *Raw:
+		if data != nil {
+			renderRaw(c, *data, status)
 			return
 		}
+		res.Data = nil
+	case Redirect:
+		renderRedirect(c, data)
+		return
+	case *Redirect:
+		if data != nil {
+			renderRedirect(c, *data)
+			return
+		}
+		res.Data = nil
+	case FileResult:
+		renderFile(c, data)
+		return
+	case *FileResult:
+		if data != nil {
+			renderFile(c, *data)
+			return
+		}
+		res.Data = nil
+	}
+
+	// 输出分页响应头
+	if o.pageHeaders {
+		if p, ok := res.Data.(pager); ok {
+			writePageHeaders(c, p)
+		}
+	}
+
+	// 业务码配置了 HTTP 状态码时使用配置的状态码
+	if res.Code != CodeSuccess {
+		if mapped, ok := httpStatusForCode(res.Code); ok {
+			status = mapped
+		}
+	}
+
+	// 失败的业务码同样记录为请求错误，警告不算失败
+	if res.Code != CodeSuccess && res.Code != CodeWarning {
+		msg := res.Msg
+		if msg == "" {
+			msg = GetCodeMessage(res.Code)
+		}
+		recordError(c, errors.New(msg), res.Code, status, true)
+	}
 
-		// 返回成功响应
-		c.JSON(http.StatusOK, res)
+	// 返回成功响应
+	if status == http.StatusNoContent {
+		c.Status(status)
+		c.Writer.WriteHeaderNow()
+		return
 	}
+	res.TraceID = traceID(c)
+	renderJSON(c, o, status, res)
 }